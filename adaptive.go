@@ -0,0 +1,96 @@
+package radix
+
+import "math/bits"
+
+// Fanouts at which nodes of a tree created with WithAdaptiveNodes
+// change their edge lookup. The thresholds for dropping a form are
+// below those for adopting it, so a node hovering around one isn't
+// rebuilt on every change.
+const (
+	// Nodes with this many edges locate them through a bitmap
+	bitmapFanout   = linearSearchThreshold
+	unbitmapFanout = linearSearchThreshold / 2
+
+	// Nodes with this many edges also get a dense dispatch table
+	denseFanout   = 48
+	undenseFanout = 32
+)
+
+// edgeBitmap marks the labels of a node's edges by their position in
+// the key order, so the index of an edge is the number of bits set
+// before its own.
+type edgeBitmap [4]uint64
+
+func (b *edgeBitmap) set(pos byte) {
+	b[pos>>6] |= 1 << (pos & 63)
+}
+
+func (b *edgeBitmap) clear(pos byte) {
+	b[pos>>6] &^= 1 << (pos & 63)
+}
+
+func (b *edgeBitmap) has(pos byte) bool {
+	return b[pos>>6]&(1<<(pos&63)) != 0
+}
+
+// rank returns the number of bits set before pos
+func (b *edgeBitmap) rank(pos byte) int {
+	r := 0
+	for i := 0; i < int(pos>>6); i++ {
+		r += bits.OnesCount64(b[i])
+	}
+	return r + bits.OnesCount64(b[pos>>6]&(1<<(pos&63)-1))
+}
+
+// count returns the number of bits set
+func (b *edgeBitmap) count() int {
+	return b.rank(255) + bits.OnesCount64(b[3]>>63)
+}
+
+// pos returns the position of label in the key order
+func (n *node) pos(label byte) byte {
+	if n.ext == nil || n.ext.collation == nil {
+		return label
+	}
+	return n.ext.collation[label]
+}
+
+// tune switches an adaptive node's edge lookup to suit its fanout
+func (n *node) tune() {
+	x := n.ext
+	num := len(n.edges)
+	switch {
+	case x.bitmap == nil && num >= bitmapFanout:
+		x.bitmap = new(edgeBitmap)
+		for _, e := range n.edges {
+			x.bitmap.set(n.pos(e.label))
+		}
+	case x.bitmap != nil && num < unbitmapFanout:
+		x.bitmap = nil
+	}
+	switch {
+	case x.dense == nil && num >= denseFanout:
+		x.dense = new([256]*node)
+		for _, e := range n.edges {
+			x.dense[e.label] = e.node
+		}
+	case x.dense != nil && num < undenseFanout:
+		x.dense = nil
+	}
+}
+
+// clearEdges removes every edge of n
+func (n *node) clearEdges() {
+	n.edges = nil
+	x := n.ext
+	if x == nil {
+		return
+	}
+	x.bitmap = nil
+	if x.dense != nil {
+		*x.dense = [256]*node{}
+	}
+	if x.adaptive {
+		n.tune()
+	}
+}
@@ -0,0 +1,75 @@
+package radix
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAdaptiveNodes(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithCollation(CaseInsensitiveCollation())}} {
+		r := New(append(opts, WithAdaptiveNodes())...)
+		if r.root.dense() != nil || r.root.ext.bitmap != nil {
+			t.Fatalf("empty root should use the edges alone")
+		}
+
+		// Grow the root's fanout through every form
+		var keys []string
+		for i := 0; i < 64; i++ {
+			k := string([]byte{byte('0' + i)})
+			keys = append(keys, k)
+			r.Insert(k, i)
+			n := len(r.root.edges)
+			if (r.root.ext.bitmap != nil) != (n >= bitmapFanout) || (r.root.dense() != nil) != (n >= denseFanout) {
+				t.Fatalf("bad form at fanout %d", n)
+			}
+			validateTree(t, r.root)
+		}
+		for i, k := range keys {
+			if v, ok := r.Get(k); !ok || v != i {
+				t.Fatalf("bad: %q %v %v", k, v, ok)
+			}
+		}
+		if _, ok := r.Get("~~"); ok {
+			t.Fatalf("bad")
+		}
+
+		// Shrinking drops the forms at lower thresholds
+		for i, k := range keys {
+			r.Delete(k)
+			n := len(r.root.edges)
+			if n == denseFanout-1 && r.root.dense() == nil {
+				t.Fatalf("dense table dropped too early")
+			}
+			if (n < undenseFanout && r.root.dense() != nil) || (n < unbitmapFanout && r.root.ext.bitmap != nil) {
+				t.Fatalf("bad form at fanout %d", n)
+			}
+			validateTree(t, r.root)
+			if _, ok := r.Get(k); ok || r.Len() != len(keys)-i-1 {
+				t.Fatalf("bad")
+			}
+		}
+	}
+}
+
+func TestAdaptiveNodes_Structure(t *testing.T) {
+	r := New(WithAdaptiveNodes())
+	plain := New()
+	for i := 0; i < 2000; i++ {
+		k := fmt.Sprintf("%x", i*7919)
+		r.Insert(k, i)
+		plain.Insert(k, i)
+	}
+	left, right := r.Split("8")
+	pleft, pright := plain.Split("8")
+	validateTree(t, left.root)
+	validateTree(t, right.root)
+	if fmt.Sprint(left.ToMap()) != fmt.Sprint(pleft.ToMap()) || fmt.Sprint(right.ToMap()) != fmt.Sprint(pright.ToMap()) {
+		t.Fatalf("split differs")
+	}
+	right.DeletePrefix("a")
+	pright.DeletePrefix("a")
+	validateTree(t, right.root)
+	if fmt.Sprint(right.ToMap()) != fmt.Sprint(pright.ToMap()) {
+		t.Fatalf("delete prefix differs")
+	}
+}
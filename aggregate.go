@@ -0,0 +1,164 @@
+package radix
+
+import "strings"
+
+// Aggregate summarises the numbers derived from a set of entries
+// by the function given to WithAggregate. Min and Max are only
+// meaningful when Count is non-zero.
+type Aggregate struct {
+	Count int
+	Sum   float64
+	Min   float64
+	Max   float64
+}
+
+// add folds a single number into the aggregate
+func (a *Aggregate) add(x float64) {
+	a.merge(Aggregate{Count: 1, Sum: x, Min: x, Max: x})
+}
+
+// merge folds another aggregate into this one
+func (a *Aggregate) merge(o Aggregate) {
+	if o.Count == 0 {
+		return
+	}
+	if a.Count == 0 {
+		*a = o
+		return
+	}
+	a.Count += o.Count
+	a.Sum += o.Sum
+	if o.Min < a.Min {
+		a.Min = o.Min
+	}
+	if o.Max > a.Max {
+		a.Max = o.Max
+	}
+}
+
+// Aggregate returns the summary of every entry whose key starts
+// with prefix. The tree must have been created with WithAggregate.
+func (t *Tree) Aggregate(prefix string) Aggregate {
+	prefix = t.norm(prefix)
+	if t.aggFn == nil {
+		panic("radix: Aggregate requires WithAggregate")
+	}
+	t.promote()
+	n := t.root
+	search := prefix
+	for {
+		// Check for key exhaustion
+		if len(search) == 0 {
+			return *n.ext.agg
+		}
+
+		// Look for an edge
+		n = n.getEdge(search[0])
+		if n == nil {
+			return Aggregate{}
+		}
+
+		// Consume the search prefix
+		if strings.HasPrefix(search, n.prefix) {
+			search = search[len(n.prefix):]
+			continue
+		}
+		if strings.HasPrefix(n.prefix, search) {
+			return *n.ext.agg
+		}
+		return Aggregate{}
+	}
+}
+
+// refreshPath recomputes the aggregates of the nodes along the
+// path to key, bottom up. Only those nodes can have changed after
+// a mutation of key: merged nodes inherit their child's aggregate
+// and everything off the path is untouched.
+func (t *Tree) refreshPath(key string) {
+	if !t.counted() || t.smallMode {
+		return
+	}
+	var buf [32]*node
+	path := append(buf[:0], t.root)
+	n := t.root
+	search := key
+	for len(search) > 0 {
+		n = n.getEdge(search[0])
+		if n == nil || !strings.HasPrefix(search, n.prefix) {
+			break
+		}
+		path = append(path, n)
+		search = search[len(n.prefix):]
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		t.recompute(path[i])
+	}
+}
+
+// counted reports whether nodes maintain an aggregate, which holds
+// just the count of leaves under them without WithAggregate
+func (t *Tree) counted() bool {
+	return t.aggFn != nil || t.leafCounts
+}
+
+// recompute rebuilds a node's aggregate from its leaf and the
+// aggregates of its children
+func (t *Tree) recompute(n *node) {
+	var a Aggregate
+	switch {
+	case n.leaf == nil:
+	case t.aggFn != nil:
+		a.add(t.aggFn(n.leaf.key, n.leaf.val))
+	default:
+		a.Count = 1
+	}
+	for _, e := range n.edges {
+		a.merge(*e.node.ext.agg)
+	}
+	*n.ext.agg = a
+}
+
+// AggregateRange returns the summary of every entry whose key lies
+// in [start, end). Subtrees that fall entirely inside the range
+// contribute their maintained aggregate without being visited, so
+// only the nodes along the two boundaries are inspected. The tree
+// must have been created with WithAggregate.
+func (t *Tree) AggregateRange(start, end string) Aggregate {
+	start, end = t.norm(start), t.norm(end)
+	if t.aggFn == nil {
+		panic("radix: AggregateRange requires WithAggregate")
+	}
+	t.promote()
+	if t.collation.Compare(start, end) >= 0 {
+		return Aggregate{}
+	}
+	return t.rangeAggregate(t.root, 0, start, end)
+}
+
+// rangeAggregate computes the part of n's aggregate that falls in
+// [start, end). pathLen is the length of the key path through n.
+func (t *Tree) rangeAggregate(n *node, pathLen int, start, end string) Aggregate {
+	l := minLeaf(n)
+	if l == nil {
+		return Aggregate{}
+	}
+
+	// Every key under n starts with path
+	path := l.key[:pathLen]
+	cmp := t.collation.Compare
+	switch {
+	case cmp(start, path) <= 0 && cmp(path, end) < 0 && !strings.HasPrefix(end, path):
+		return *n.ext.agg
+	case cmp(path, end) >= 0 || (cmp(path, start) < 0 && !strings.HasPrefix(start, path)):
+		return Aggregate{}
+	}
+
+	var a Aggregate
+	if n.leaf != nil && cmp(start, n.leaf.key) <= 0 && cmp(n.leaf.key, end) < 0 {
+		a.add(t.aggFn(n.leaf.key, n.leaf.val))
+	}
+	for _, e := range n.edges {
+		a.merge(t.rangeAggregate(e.node, pathLen+len(e.node.prefix), start, end))
+	}
+	return a
+}
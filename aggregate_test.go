@@ -0,0 +1,119 @@
+package radix
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestAggregate(t *testing.T) {
+	fn := func(k string, v interface{}) float64 {
+		return float64(v.(int))
+	}
+	r := New(WithAggregate(fn))
+	inp := make(map[string]int)
+
+	// check compares the maintained aggregates against a brute
+	// force computation over the reference map
+	check := func(r *Tree, inp map[string]int) {
+		t.Helper()
+		for _, prefix := range []string{"", "a", "ab", "abc", "b", "ba", "c", "zz"} {
+			var want Aggregate
+			for k, v := range inp {
+				if strings.HasPrefix(k, prefix) {
+					want.add(float64(v))
+				}
+			}
+			if got := r.Aggregate(prefix); got != want {
+				t.Fatalf("prefix %q: %+v %+v", prefix, got, want)
+			}
+		}
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	letters := "abc"
+	randKey := func() string {
+		b := make([]byte, rng.Intn(5))
+		for i := range b {
+			b[i] = letters[rng.Intn(len(letters))]
+		}
+		return string(b)
+	}
+	for i := 0; i < 2000; i++ {
+		k := randKey()
+		switch rng.Intn(10) {
+		case 0, 1, 2:
+			r.Delete(k)
+			delete(inp, k)
+		case 3:
+			r.DeletePrefix(k)
+			for ik := range inp {
+				if strings.HasPrefix(ik, k) {
+					delete(inp, ik)
+				}
+			}
+		default:
+			v := rng.Intn(1000) - 500
+			r.Insert(k, v)
+			inp[k] = v
+		}
+		if i%50 == 0 {
+			check(r, inp)
+		}
+	}
+	check(r, inp)
+
+	// Split and Partition keep the aggregates consistent
+	for k := range inp {
+		r.Insert(k, 1)
+		inp[k] = 1
+	}
+	left, right := r.Split("b")
+	leftInp, rightInp := make(map[string]int), make(map[string]int)
+	for k, v := range inp {
+		if k < "b" {
+			leftInp[k] = v
+		} else {
+			rightInp[k] = v
+		}
+	}
+	check(left, leftInp)
+	check(right, rightInp)
+
+	parts := left.Partition([]string{"ab"})
+	abInp := make(map[string]int)
+	for k, v := range leftInp {
+		if strings.HasPrefix(k, "ab") {
+			abInp[k] = v
+			delete(leftInp, k)
+		}
+	}
+	check(parts["ab"], abInp)
+	check(left, leftInp)
+}
+
+func TestAggregateRange(t *testing.T) {
+	fn := func(k string, v interface{}) float64 {
+		return float64(v.(int))
+	}
+	r := New(WithAggregate(fn))
+	keys := []string{"", "a", "ab", "abc", "abd", "b", "ba", "bab", "c", "foo/bar", "foo/baz", "foobar"}
+	for i, k := range keys {
+		r.Insert(k, i)
+	}
+
+	bounds := []string{"", "0", "a", "aa", "ab", "abc", "abcd", "abz", "b", "bb", "c", "foo", "foo/", "foo/baz", "fooz", "zzz"}
+	for _, start := range bounds {
+		for _, end := range bounds {
+			var want Aggregate
+			for i, k := range keys {
+				if start <= k && k < end {
+					want.add(float64(i))
+				}
+			}
+			if got := r.AggregateRange(start, end); got != want {
+				t.Fatalf("[%q, %q): %+v %+v", start, end, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package radix
+
+import "sort"
+
+// AhoCorasick finds every occurrence of a set of keys in a text in a
+// single pass, taking time linear in the length of the text plus the
+// number of matches. It is built from the keys of a tree by
+// ToAhoCorasick, and is immutable and safe for concurrent use.
+type AhoCorasick struct {
+	states []acState
+	keys   []string
+	vals   []interface{}
+}
+
+// acState is a state of the automaton: the keys sharing a prefix,
+// which is the path to the state
+type acState struct {
+	// labels and next are the transitions, sorted by label
+	labels []byte
+	next   []int32
+
+	// fail is the state for the longest proper suffix of the path
+	// that is also a path
+	fail int32
+
+	// key is the index of the key equal to the path, or -1
+	key int32
+
+	// dict is the nearest state along the fail links that ends a
+	// key, or -1
+	dict int32
+}
+
+// Match is an occurrence of a key in a text
+type Match struct {
+	Key   string
+	Value interface{}
+
+	// Start and End are the byte offsets of the occurrence in the
+	// text, so it is text[Start:End]
+	Start, End int
+}
+
+// ToAhoCorasick returns a matcher for the keys currently in the tree,
+// reporting their values as they are now. The empty key, which would
+// match everywhere, is left out.
+func (t *Tree) ToAhoCorasick() *AhoCorasick {
+	a := &AhoCorasick{states: []acState{{key: -1, dict: -1}}}
+	for l := t.leaves.head; l != nil; l = l.next {
+		if l.key == "" {
+			continue
+		}
+		s := int32(0)
+		for i := 0; i < len(l.key); i++ {
+			s = a.child(s, l.key[i])
+		}
+		a.states[s].key = int32(len(a.keys))
+		a.keys = append(a.keys, l.key)
+		a.vals = append(a.vals, l.val)
+	}
+
+	// Link the states breadth first, so the fail state of every state
+	// has been linked before its own
+	queue := []int32{0}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		for i, label := range a.states[s].labels {
+			c := a.states[s].next[i]
+			queue = append(queue, c)
+			if s == 0 {
+				continue
+			}
+			f := a.step(a.states[s].fail, label)
+			a.states[c].fail = f
+			if a.states[f].key >= 0 {
+				a.states[c].dict = f
+			} else {
+				a.states[c].dict = a.states[f].dict
+			}
+		}
+	}
+	return a
+}
+
+// child returns the transition from s on label, adding it if missing.
+// It is only used while building.
+func (a *AhoCorasick) child(s int32, label byte) int32 {
+	st := &a.states[s]
+	i := sort.Search(len(st.labels), func(i int) bool {
+		return st.labels[i] >= label
+	})
+	if i < len(st.labels) && st.labels[i] == label {
+		return st.next[i]
+	}
+	c := int32(len(a.states))
+	st.labels = append(st.labels, 0)
+	copy(st.labels[i+1:], st.labels[i:])
+	st.labels[i] = label
+	st.next = append(st.next, 0)
+	copy(st.next[i+1:], st.next[i:])
+	st.next[i] = c
+	a.states = append(a.states, acState{key: -1, dict: -1})
+	return c
+}
+
+// transition returns the transition from s on label, or -1
+func (a *AhoCorasick) transition(s int32, label byte) int32 {
+	st := &a.states[s]
+	lo, hi := 0, len(st.labels)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if st.labels[mid] < label {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	if lo < len(st.labels) && st.labels[lo] == label {
+		return st.next[lo]
+	}
+	return -1
+}
+
+// step returns the state after reading label in state s
+func (a *AhoCorasick) step(s int32, label byte) int32 {
+	for {
+		if c := a.transition(s, label); c >= 0 {
+			return c
+		}
+		if s == 0 {
+			return 0
+		}
+		s = a.states[s].fail
+	}
+}
+
+// Len returns the number of keys matched
+func (a *AhoCorasick) Len() int {
+	return len(a.keys)
+}
+
+// Scan calls fn for every occurrence of a key in text, ordered by
+// where the occurrence ends and, for those ending at the same byte,
+// longest first. Returning true from fn stops the scan.
+func (a *AhoCorasick) Scan(text string, fn func(m Match) bool) {
+	a.scan(text, func(k int32, start int) bool {
+		key := a.keys[k]
+		return fn(Match{Key: key, Value: a.vals[k], Start: start, End: start + len(key)})
+	})
+}
+
+// scan is Scan reporting the index of the key matched
+func (a *AhoCorasick) scan(text string, fn func(k int32, start int) bool) {
+	s := int32(0)
+	for i := 0; i < len(text); i++ {
+		s = a.step(s, text[i])
+		m := s
+		if a.states[m].key < 0 {
+			m = a.states[m].dict
+		}
+		for ; m >= 0; m = a.states[m].dict {
+			k := a.states[m].key
+			if fn(k, i+1-len(a.keys[k])) {
+				return
+			}
+		}
+	}
+}
+
+// FindAll returns every occurrence of a key in text, in the order
+// Scan reports them
+func (a *AhoCorasick) FindAll(text string) []Match {
+	var out []Match
+	a.Scan(text, func(m Match) bool {
+		out = append(out, m)
+		return false
+	})
+	return out
+}
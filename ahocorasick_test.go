@@ -0,0 +1,73 @@
+package radix
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAhoCorasick(t *testing.T) {
+	r := New()
+	for _, k := range []string{"he", "she", "his", "hers", ""} {
+		r.Insert(k, strings.ToUpper(k))
+	}
+	a := r.ToAhoCorasick()
+	if a.Len() != 4 {
+		t.Fatalf("bad: %d", a.Len())
+	}
+	got := a.FindAll("ushers")
+	want := []Match{
+		{Key: "she", Value: "SHE", Start: 1, End: 4},
+		{Key: "he", Value: "HE", Start: 2, End: 4},
+		{Key: "hers", Value: "HERS", Start: 2, End: 6},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("bad: %v", got)
+	}
+
+	var n int
+	a.Scan("ushers", func(m Match) bool {
+		n++
+		return true
+	})
+	if n != 1 {
+		t.Fatalf("scan should stop: %d", n)
+	}
+
+	// The matcher doesn't change with the tree
+	r.Delete("he")
+	if len(a.FindAll("he")) != 1 || len(r.ToAhoCorasick().FindAll("he")) != 0 {
+		t.Fatalf("bad")
+	}
+}
+
+func TestAhoCorasick_Random(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	randString := func(n int) string {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = "ab"[rnd.Intn(2)]
+		}
+		return string(b)
+	}
+	r := New()
+	for i := 0; i < 30; i++ {
+		r.Insert(randString(rnd.Intn(5)+1), i)
+	}
+	a := r.ToAhoCorasick()
+	text := randString(500)
+
+	// Compare against a brute force search, in the same order
+	var want []Match
+	for end := 1; end <= len(text); end++ {
+		for start := 0; start < end; start++ {
+			if v, ok := r.Get(text[start:end]); ok {
+				want = append(want, Match{Key: text[start:end], Value: v, Start: start, End: end})
+			}
+		}
+	}
+	if got := a.FindAll(text); !reflect.DeepEqual(got, want) {
+		t.Fatalf("bad: %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,35 @@
+package radix
+
+// AppendKeys appends the keys starting with prefix to dst, in key
+// order, and returns the extended slice. Reusing dst across calls
+// lets tight loops collect keys without allocating, since the keys
+// themselves are shared with the tree. Unlike WalkPrefix, it ignores
+// WithNaturalOrder.
+func (t *Tree) AppendKeys(dst []string, prefix string) []string {
+	first, end := t.prefixLeaves(t.norm(prefix))
+	for l := first; l != end; l = l.next {
+		dst = append(dst, l.key)
+	}
+	return dst
+}
+
+// AppendValues appends the values of the keys starting with prefix to
+// dst, in key order, and returns the extended slice, like AppendKeys
+func (t *Tree) AppendValues(dst []interface{}, prefix string) []interface{} {
+	first, end := t.prefixLeaves(t.norm(prefix))
+	for l := first; l != end; l = l.next {
+		dst = append(dst, l.val)
+	}
+	return dst
+}
+
+// prefixLeaves returns the first leaf starting with prefix and the
+// leaf after the last one, which are equal if there are none
+func (t *Tree) prefixLeaves(prefix string) (first, end *leafNode) {
+	end = t.prefixEnd(prefix)
+	first, _, succ := t.seek(prefix)
+	if first == nil {
+		first = succ
+	}
+	return first, end
+}
@@ -0,0 +1,48 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAppendKeys(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithSmallTree(10)}} {
+		r := New(opts...)
+		for i, k := range []string{"a", "ab", "abc", "b", "ba"} {
+			r.Insert(k, i)
+		}
+		dst := []string{"x"}
+		if got := r.AppendKeys(dst, "ab"); !reflect.DeepEqual(got, []string{"x", "ab", "abc"}) {
+			t.Fatalf("bad: %v", got)
+		}
+		if got := r.AppendKeys(dst[:0], ""); len(got) != 5 {
+			t.Fatalf("bad: %v", got)
+		}
+		if got := r.AppendKeys(nil, "c"); got != nil {
+			t.Fatalf("bad: %v", got)
+		}
+		if got := r.AppendKeys(nil, "aa"); got != nil {
+			t.Fatalf("bad: %v", got)
+		}
+		if got := r.AppendValues(nil, "b"); !reflect.DeepEqual(got, []interface{}{3, 4}) {
+			t.Fatalf("bad: %v", got)
+		}
+		if got := r.AppendChildren([]string{"x"}, "", 'b'); !reflect.DeepEqual(got, []string{"x", "a", "ab", "b"}) {
+			t.Fatalf("bad: %v", got)
+		}
+	}
+}
+
+func TestAppendKeysAllocs(t *testing.T) {
+	r := New()
+	for _, k := range []string{"a", "ab", "abc", "b", "ba"} {
+		r.Insert(k, nil)
+	}
+	dst := make([]string, 0, 8)
+	allocs := testing.AllocsPerRun(100, func() {
+		dst = r.AppendKeys(dst[:0], "a")
+	})
+	if allocs != 0 {
+		t.Fatalf("bad: %v allocs", allocs)
+	}
+}
@@ -0,0 +1,268 @@
+package radix
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Backend holds the persistent state of a tree: a snapshot, and an
+// append-only log of the mutations made since it was taken. It only
+// stores opaque bytes, so the layout on disk, or elsewhere, is up to
+// the implementation. Backends must be safe for concurrent use.
+type Backend interface {
+	// ReadSnapshot calls fn with the current snapshot, returning
+	// ErrNoSnapshot if none has been written.
+	ReadSnapshot(fn func(r io.Reader) error) error
+
+	// WriteSnapshot atomically replaces the snapshot with the
+	// bytes fn writes. If fn fails, the old snapshot is kept.
+	WriteSnapshot(fn func(w io.Writer) error) error
+
+	// Append adds a record to the end of the log
+	Append(rec []byte) error
+
+	// Sync makes every appended record durable
+	Sync() error
+
+	// ReadLog calls fn with every record in the log, in order
+	ReadLog(fn func(rec []byte) error) error
+
+	// TruncateLog empties the log
+	TruncateLog() error
+}
+
+var (
+	// ErrNoSnapshot is returned by Backend.ReadSnapshot when no
+	// snapshot has been written yet.
+	ErrNoSnapshot = errors.New("radix: no snapshot")
+
+	// ErrCorruptLog is returned when a log record fails its checksum
+	ErrCorruptLog = errors.New("radix: corrupt log record")
+)
+
+// MemBackend is a Backend that keeps everything in memory, for tests
+// and for trees that only need to survive being rebuilt in-process.
+type MemBackend struct {
+	mu       sync.Mutex
+	snapshot []byte
+	log      [][]byte
+}
+
+// NewMemBackend returns an empty in-memory backend
+func NewMemBackend() *MemBackend {
+	return &MemBackend{}
+}
+
+func (m *MemBackend) ReadSnapshot(fn func(r io.Reader) error) error {
+	m.mu.Lock()
+	snap := m.snapshot
+	m.mu.Unlock()
+	if snap == nil {
+		return ErrNoSnapshot
+	}
+	return fn(bytes.NewReader(snap))
+}
+
+func (m *MemBackend) WriteSnapshot(fn func(w io.Writer) error) error {
+	var buf bytes.Buffer
+	if err := fn(&buf); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.snapshot = buf.Bytes()
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *MemBackend) Append(rec []byte) error {
+	m.mu.Lock()
+	m.log = append(m.log, append([]byte(nil), rec...))
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *MemBackend) Sync() error {
+	return nil
+}
+
+func (m *MemBackend) ReadLog(fn func(rec []byte) error) error {
+	m.mu.Lock()
+	log := m.log[:len(m.log):len(m.log)]
+	m.mu.Unlock()
+	for _, rec := range log {
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MemBackend) TruncateLog() error {
+	m.mu.Lock()
+	m.log = nil
+	m.mu.Unlock()
+	return nil
+}
+
+// FileBackend is a Backend keeping the snapshot and the log as two
+// files in a directory. Snapshots are replaced by renaming a fully
+// written temporary file. Log records carry a length and checksum,
+// and a record torn by a crash is cut off when the backend is opened.
+type FileBackend struct {
+	mu  sync.Mutex
+	dir string
+	log *os.File
+}
+
+const (
+	snapshotFile = "snapshot"
+	logFile      = "log"
+)
+
+// NewFileBackend opens, or creates, a file backend in dir. It must be
+// closed with Close.
+func NewFileBackend(dir string) (*FileBackend, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, logFile), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cut off a record torn by a crash, so appends follow the last
+	// intact one
+	valid, err := scanLog(f, func([]byte) error { return nil })
+	if err == nil {
+		err = f.Truncate(valid)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &FileBackend{dir: dir, log: f}, nil
+}
+
+func (b *FileBackend) ReadSnapshot(fn func(r io.Reader) error) error {
+	f, err := os.Open(filepath.Join(b.dir, snapshotFile))
+	if os.IsNotExist(err) {
+		return ErrNoSnapshot
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+	return fn(bufio.NewReader(f))
+}
+
+func (b *FileBackend) WriteSnapshot(fn func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(b.dir, snapshotFile+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := fn(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(b.dir, snapshotFile)); err != nil {
+		return err
+	}
+	return syncDir(b.dir)
+}
+
+// syncDir makes a rename in dir durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+func (b *FileBackend) Append(rec []byte) error {
+	buf := make([]byte, binary.MaxVarintLen64+4+len(rec))
+	n := binary.PutUvarint(buf, uint64(len(rec)))
+	binary.LittleEndian.PutUint32(buf[n:], crc32.ChecksumIEEE(rec))
+	n += 4
+	n += copy(buf[n:], rec)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, err := b.log.Write(buf[:n])
+	return err
+}
+
+func (b *FileBackend) Sync() error {
+	return b.log.Sync()
+}
+
+func (b *FileBackend) ReadLog(fn func(rec []byte) error) error {
+	f, err := os.Open(filepath.Join(b.dir, logFile))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = scanLog(f, fn)
+	return err
+}
+
+// scanLog calls fn with every record in a log file, returning the
+// length of the intact part. A torn record at the end is ignored.
+func scanLog(f io.Reader, fn func(rec []byte) error) (int64, error) {
+	r := bufio.NewReader(f)
+	var valid int64
+	var sum [4]byte
+	for {
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return valid, nil
+		}
+		if _, err := io.ReadFull(r, sum[:]); err != nil {
+			return valid, nil
+		}
+		if n > maxSectionLen {
+			return valid, ErrCorruptLog
+		}
+		rec := make([]byte, n)
+		if _, err := io.ReadFull(r, rec); err != nil {
+			return valid, nil
+		}
+		if crc32.ChecksumIEEE(rec) != binary.LittleEndian.Uint32(sum[:]) {
+			return valid, ErrCorruptLog
+		}
+		if err := fn(rec); err != nil {
+			return valid, err
+		}
+		var tmp [binary.MaxVarintLen64]byte
+		valid += int64(binary.PutUvarint(tmp[:], n)) + 4 + int64(n)
+	}
+}
+
+func (b *FileBackend) TruncateLog() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.log.Truncate(0); err != nil {
+		return err
+	}
+	return b.log.Sync()
+}
+
+// Close closes the log file
+func (b *FileBackend) Close() error {
+	return b.log.Close()
+}
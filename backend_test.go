@@ -0,0 +1,155 @@
+package radix
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testBackend checks the contract every Backend must meet
+func testBackend(t *testing.T, b Backend) {
+	if err := b.ReadSnapshot(func(io.Reader) error { return nil }); err != ErrNoSnapshot {
+		t.Fatalf("err: %v", err)
+	}
+	for _, data := range []string{"first", "second"} {
+		err := b.WriteSnapshot(func(w io.Writer) error {
+			_, err := io.WriteString(w, data)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+	boom := errors.New("boom")
+	err := b.WriteSnapshot(func(w io.Writer) error {
+		io.WriteString(w, "partial")
+		return boom
+	})
+	if err != boom {
+		t.Fatalf("err: %v", err)
+	}
+	var snap []byte
+	err = b.ReadSnapshot(func(r io.Reader) error {
+		var err error
+		snap, err = io.ReadAll(r)
+		return err
+	})
+	if err != nil || string(snap) != "second" {
+		t.Fatalf("bad: %q %v", snap, err)
+	}
+
+	readLog := func() []string {
+		var recs []string
+		if err := b.ReadLog(func(rec []byte) error {
+			recs = append(recs, string(rec))
+			return nil
+		}); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		return recs
+	}
+	for _, rec := range []string{"a", "", "ccc"} {
+		if err := b.Append([]byte(rec)); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+	if err := b.Sync(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if recs := readLog(); len(recs) != 3 || recs[0] != "a" || recs[1] != "" || recs[2] != "ccc" {
+		t.Fatalf("bad: %q", recs)
+	}
+	if err := b.TruncateLog(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	b.Append([]byte("d"))
+	if recs := readLog(); len(recs) != 1 || recs[0] != "d" {
+		t.Fatalf("bad: %q", recs)
+	}
+}
+
+func TestMemBackend(t *testing.T) {
+	testBackend(t, NewMemBackend())
+}
+
+func TestFileBackend(t *testing.T) {
+	dir := t.TempDir()
+	b, err := NewFileBackend(dir)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	testBackend(t, b)
+	if err := b.Close(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// A torn record is cut off on open
+	path := filepath.Join(dir, logFile)
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, append(data, 5, 1, 2), 0644)
+	b, err = NewFileBackend(dir)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer b.Close()
+	b.Append([]byte("e"))
+	var recs []string
+	b.ReadLog(func(rec []byte) error {
+		recs = append(recs, string(rec))
+		return nil
+	})
+	if len(recs) != 2 || recs[0] != "d" || recs[1] != "e" {
+		t.Fatalf("bad: %q", recs)
+	}
+
+	// A bad checksum is reported
+	data, _ = os.ReadFile(path)
+	data[len(data)-1] ^= 0xff
+	os.WriteFile(path, data, 0644)
+	if err := b.ReadLog(func([]byte) error { return nil }); err != ErrCorruptLog {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestBackend_Recover(t *testing.T) {
+	b := NewMemBackend()
+	fail := func(err error) {
+		t.Fatalf("err: %v", err)
+	}
+	r := New(WithBackend(b, stringCodec{}, fail))
+	r.Insert("foo", "1")
+	r.Insert("foobar", "2")
+	if err := r.Checkpoint(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	r.Insert("zip", "3")
+	r.Insert("foo", "4")
+	r.Delete("foobar")
+
+	out := New(WithBackend(b, stringCodec{}, fail))
+	if err := out.Recover(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	checkKeys(t, out, []string{"foo", "zip"})
+	if v, _ := out.Get("foo"); v != "4" {
+		t.Fatalf("bad: %v", v)
+	}
+
+	// Recovery doesn't log again, and unknown optional records are
+	// skipped
+	var n int
+	b.ReadLog(func([]byte) error { n++; return nil })
+	if n != 3 {
+		t.Fatalf("bad: %d", n)
+	}
+	b.Append([]byte{99, 0, 'x'})
+	if err := New(WithBackend(b, stringCodec{}, fail)).Recover(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	b.Append([]byte{99, sectionRequired})
+	if err := New(WithBackend(b, stringCodec{}, fail)).Recover(); err != ErrSnapshotVersion {
+		t.Fatalf("err: %v", err)
+	}
+}
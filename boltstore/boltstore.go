@@ -0,0 +1,78 @@
+// Package boltstore persists the entries of a radix tree in a bbolt
+// bucket, making the tree a fast in-memory index over durable
+// storage. A Store is attached to a tree with radix.WithStore, or
+// radix.WithAsyncStore to persist lazily in the background, and Load
+// warms the tree up from the bucket on startup. It is a module of its
+// own, so that the radix module doesn't depend on bbolt.
+package boltstore
+
+import (
+	"bytes"
+
+	radix "github.com/armon/go-radix"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Store is a radix.Store that keeps entries in a bbolt bucket
+type Store struct {
+	db     *bolt.DB
+	bucket []byte
+	codec  radix.ValueCodec
+}
+
+// New returns a Store keeping entries in the named bucket of db,
+// creating the bucket if needed. Values are encoded with codec.
+func New(db *bolt.DB, bucket string, codec radix.ValueCodec) (*Store, error) {
+	s := &Store{db: db, bucket: []byte(bucket), codec: codec}
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(s.bucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Put stores the entry. Entries the bucket already holds unchanged,
+// such as those just put in the tree by Load, cost only a read.
+func (s *Store) Put(key string, v interface{}) error {
+	b, err := s.codec.Encode(v)
+	if err != nil {
+		return err
+	}
+	var same bool
+	s.db.View(func(tx *bolt.Tx) error {
+		old := tx.Bucket(s.bucket).Get([]byte(key))
+		same = old != nil && bytes.Equal(old, b)
+		return nil
+	})
+	if same {
+		return nil
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(s.bucket).Put([]byte(key), b)
+	})
+}
+
+// Delete removes the entry for key, if any
+func (s *Store) Delete(key string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(s.bucket).Delete([]byte(key))
+	})
+}
+
+// Load inserts every entry in the bucket into t. It is meant for
+// warming up a tree on startup, which may already have s attached.
+func (s *Store) Load(t *radix.Tree) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(s.bucket).ForEach(func(k, b []byte) error {
+			v, err := s.codec.Decode(b)
+			if err != nil {
+				return err
+			}
+			_, _, err = t.TryInsert(string(k), v)
+			return err
+		})
+	})
+}
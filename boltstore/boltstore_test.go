@@ -0,0 +1,77 @@
+package boltstore
+
+import (
+	"path/filepath"
+	"testing"
+
+	radix "github.com/armon/go-radix"
+	bolt "go.etcd.io/bbolt"
+)
+
+// stringCodec persists string values
+type stringCodec struct{}
+
+func (stringCodec) Encode(v interface{}) ([]byte, error) {
+	return []byte(v.(string)), nil
+}
+
+func (stringCodec) Decode(b []byte) (interface{}, error) {
+	return string(b), nil
+}
+
+func openStore(t *testing.T, path string) (*bolt.DB, *Store) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	s, err := New(db, "entries", stringCodec{})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return db, s
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.db")
+	db, s := openStore(t, path)
+	fail := func(err error) {
+		t.Fatalf("store error: %v", err)
+	}
+	r := radix.New(radix.WithAsyncStore(s, fail, 16))
+	r.Insert("foo", "1")
+	r.Insert("foo/bar", "2")
+	r.Insert("baz", "3")
+	r.Insert("foo", "4")
+	r.Delete("baz")
+	if err := r.Close(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Warm load a new tree after a restart
+	db, s = openStore(t, path)
+	defer db.Close()
+	r = radix.New(radix.WithStore(s, fail))
+	before := db.Stats()
+	if err := s.Load(r); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	want := map[string]interface{}{"foo": "4", "foo/bar": "2"}
+	got := r.ToMap()
+	if len(got) != len(want) {
+		t.Fatalf("bad: %v", got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("bad: %v", got)
+		}
+	}
+
+	// Loading didn't rewrite the unchanged entries
+	after := db.Stats()
+	if diff := after.Sub(&before); diff.TxStats.Write != 0 {
+		t.Fatalf("load should not write: %d", diff.TxStats.Write)
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/armon/go-radix/boltstore
+
+go 1.18
+
+require (
+	github.com/armon/go-radix v0.0.0
+	go.etcd.io/bbolt v1.3.5
+)
+
+require golang.org/x/sys v0.13.0 // indirect
+
+replace github.com/armon/go-radix => ../
@@ -0,0 +1,61 @@
+package radix
+
+// Builder fills a new tree with entries given in sorted order, in a
+// single bottom-up pass that never splits a node, which is much
+// faster than inserting them one at a time.
+type Builder struct {
+	t *Tree
+	a *appender
+}
+
+// NewBuilder returns a builder for a tree with the given options
+func NewBuilder(opts ...Option) *Builder {
+	t := New(opts...)
+	return &Builder{t: t, a: newAppender(t)}
+}
+
+// Append adds an entry, whose key must sort after every key appended
+// so far in the order of the tree's collation, after any
+// normalization. Otherwise it returns ErrUnsorted, or a *KeyError for
+// a key that breaks the tree's key policy, and the entry is not
+// added. Entries are stored as if by Insert, ignoring any memory
+// budget or capacity.
+func (b *Builder) Append(key string, v interface{}) error {
+	if b.a == nil {
+		panic("radix: Builder used after Tree")
+	}
+	key = b.t.norm(key)
+	if b.t.keyPolicy != nil {
+		if err := b.t.keyPolicy.check(key); err != nil {
+			return err
+		}
+	}
+	return b.a.add(key, v)
+}
+
+// Tree completes and returns the tree. The builder can't be used
+// afterwards.
+func (b *Builder) Tree() *Tree {
+	if b.a != nil {
+		b.a.finish()
+		b.a = nil
+	}
+	return b.t
+}
+
+// NewFromSortedSlice returns a tree holding keys[i] mapped to vals[i]
+// for every i, built as by a Builder. keys must be sorted and free of
+// repeats, otherwise the tree built so far is returned with the error
+// from Append. It panics if the slices differ in length.
+func NewFromSortedSlice(keys []string, vals []interface{}, opts ...Option) (*Tree, error) {
+	if len(keys) != len(vals) {
+		panic("radix: keys and values differ in length")
+	}
+	b := NewBuilder(opts...)
+	for i, k := range keys {
+		if err := b.Append(k, vals[i]); err != nil {
+			return b.Tree(), err
+		}
+	}
+	return b.Tree(), nil
+}
@@ -0,0 +1,67 @@
+package radix
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	var keys []string
+	var vals []interface{}
+	for i := 0; i < 1000; i++ {
+		keys = append(keys, fmt.Sprintf("k/%03d/%d", i/10, i%10))
+		vals = append(vals, i)
+	}
+	for _, opts := range [][]Option{nil, {WithSmallTree(100)}, {WithSmallTree(5000)}} {
+		r, err := NewFromSortedSlice(keys, vals, opts...)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if r.Len() != len(keys) {
+			t.Fatalf("bad: %d", r.Len())
+		}
+		if !r.smallMode {
+			validateTree(t, r.root)
+		}
+		for i, k := range keys {
+			if v, ok := r.Get(k); !ok || v != i {
+				t.Fatalf("bad %q: %v %v", k, v, ok)
+			}
+		}
+
+		// The tree is usable afterwards
+		r.Insert("k/0", nil)
+		r.Delete(keys[0])
+		if r.Len() != len(keys) {
+			t.Fatalf("bad: %d", r.Len())
+		}
+	}
+
+	b := NewBuilder()
+	if err := b.Append("b", 1); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := b.Append("a", 2); !errors.Is(err, ErrUnsorted) {
+		t.Fatalf("bad: %v", err)
+	}
+	if err := b.Append("b", 3); !errors.Is(err, ErrUnsorted) {
+		t.Fatalf("bad: %v", err)
+	}
+	if got := b.Tree().ToMap(); !reflect.DeepEqual(got, map[string]interface{}{"b": 1}) {
+		t.Fatalf("bad: %v", got)
+	}
+}
+
+func BenchmarkNewFromSortedSlice(b *testing.B) {
+	keys := make([]string, 100000)
+	vals := make([]interface{}, len(keys))
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key/%08d", i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewFromSortedSlice(keys, vals)
+	}
+}
@@ -0,0 +1,288 @@
+package radix
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// LoadParallel fills the empty tree t with the entries of m. The
+// entries are partitioned by their first byte, and the subtree for
+// each partition is built on one of up to workers goroutines before
+// being grafted under the root, which cuts the time of a large
+// initial load on multicore machines. A workers of zero or less uses
+// GOMAXPROCS. Entries are stored as if by Insert, and the tree's
+// store and log, if any, see them in key order. The function given to
+// WithAggregate, if any, is called concurrently.
+//
+// Trees with a memory budget, a capacity, a key normalizer or a key
+// policy, and small trees that m would not outgrow, are loaded one
+// entry at a time. LoadParallel panics if the tree is not empty.
+func (t *Tree) LoadParallel(m map[string]interface{}, workers int) {
+	if t.size != 0 {
+		panic("radix: LoadParallel requires an empty tree")
+	}
+	if t.budget > 0 || t.lfuCap > 0 || t.normalize != nil || t.keyPolicy != nil || (t.smallMode && len(m) <= t.smallMax) {
+		for k, v := range m {
+			t.Insert(k, v)
+		}
+		return
+	}
+	t.promote()
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	// Partition by first byte. The empty key belongs to the root.
+	var root *leafNode
+	var parts [256][]*leafNode
+	for k, v := range m {
+		l := t.newLeaf(k, v)
+		if k == "" {
+			root = l
+			continue
+		}
+		parts[k[0]] = append(parts[k[0]], l)
+	}
+
+	// Build the subtrees concurrently. Building only reads the
+	// tree's configuration, once the node slab is out of the way.
+	slab := t.nodeSlab
+	t.nodeSlab = nil
+	var children [256]*node
+	labels := make(chan byte, len(parts))
+	for label, leaves := range parts {
+		if len(leaves) > 0 {
+			labels <- byte(label)
+		}
+	}
+	close(labels)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for label := range labels {
+				leaves := parts[label]
+				sort.Slice(leaves, func(i, j int) bool {
+					return t.collation.Compare(leaves[i].key, leaves[j].key) < 0
+				})
+				first, last := leaves[0].key, leaves[len(leaves)-1].key
+				common := longestPrefix(first, last)
+				children[label] = t.buildNode(first[:common], common, leaves)
+			}
+		}()
+	}
+	wg.Wait()
+	t.nodeSlab = slab
+
+	// Graft the subtrees and account for the entries in key order
+	if root != nil {
+		t.root.leaf = root
+		t.added(root)
+	}
+	for _, child := range children {
+		if child != nil {
+			t.root.addEdge(edge{label: child.prefix[0], node: child})
+		}
+	}
+	for _, e := range t.root.edges {
+		for _, l := range parts[e.label] {
+			t.added(l)
+		}
+	}
+	t.refreshPath("")
+}
+
+// added accounts for l, a new leaf placed in the structure after every
+// other leaf in key order
+func (t *Tree) added(l *leafNode) {
+	t.leaves.insert(l, t.leaves.tail, nil)
+	t.size++
+	t.leafAdded(l)
+	if t.defaultTTL > 0 {
+		t.setExpiry(l, t.defaultTTL)
+	}
+}
+
+// ErrUnsorted is returned when keys that must be given in sorted
+// order are not, or are repeated
+var ErrUnsorted = errors.New("radix: keys are not in sorted order")
+
+// maxRecordLen guards against allocating for corrupt record lengths
+const maxRecordLen = 1 << 30
+
+// BuildFromSortedReader returns a tree holding the records read from
+// r, which must be sorted by key in the order of the tree's collation
+// and have no repeated keys, after any normalization. Each record is the uvarint length of the
+// key, the key, the uvarint length of the value and the value, which
+// is decoded with c. The tree is built bottom up in a single pass
+// without holding the input in memory, so no node is ever split and
+// the input may be larger than an intermediate map could be. Entries
+// are stored as if by Insert, ignoring any memory budget or capacity,
+// but a key that breaks the tree's key policy stops the read.
+//
+// Reading stops at the first malformed or out of order record, in
+// which case the tree built so far is returned with the error.
+func BuildFromSortedReader(r io.Reader, c ValueCodec, opts ...Option) (*Tree, error) {
+	b := NewBuilder(opts...)
+	t := b.t
+	defer b.Tree()
+	br := bufio.NewReader(r)
+	for i := 0; ; i++ {
+		key, err := readRecordField(br)
+		if err == io.EOF {
+			return t, nil
+		}
+		var val []byte
+		if err == nil {
+			val, err = readRecordField(br)
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+		}
+		if err != nil {
+			return t, fmt.Errorf("radix: reading record %d: %w", i, err)
+		}
+		v, err := c.Decode(val)
+		if err != nil {
+			return t, fmt.Errorf("radix: decoding value of %q: %w", key, err)
+		}
+		if err := b.Append(string(key), v); err != nil {
+			return t, err
+		}
+	}
+}
+
+// readRecordField reads a length prefixed field. It returns io.EOF
+// only if the input ended before the field.
+func readRecordField(br *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(br)
+	switch {
+	case err == io.EOF:
+		return nil, io.EOF
+	case err != nil:
+		return nil, io.ErrUnexpectedEOF
+	case n > maxRecordLen:
+		return nil, ErrBadSnapshot
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(br, b); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return b, nil
+}
+
+// appender fills an empty tree with entries added in sorted order.
+// It builds the nodes bottom up, keeping open the path to the last
+// key, and completes each node once no later key can fall under it.
+// Until finish is called, the tree must not be used.
+type appender struct {
+	t     *Tree
+	stack []appendFrame
+	prev  string
+}
+
+// appendFrame is an open node, whose path is key[:depth]. Its prefix
+// is only known once the node is completed.
+type appendFrame struct {
+	n     *node
+	depth int
+	key   string
+}
+
+func newAppender(t *Tree) *appender {
+	if t.size != 0 {
+		panic("radix: appending requires an empty tree")
+	}
+	return &appender{t: t}
+}
+
+// add appends an entry, which must sort after every entry added so far
+func (a *appender) add(key string, v interface{}) error {
+	t := a.t
+	if t.size > 0 && t.collation.Compare(a.prev, key) >= 0 {
+		return ErrUnsorted
+	}
+	l := t.newLeaf(key, v)
+	a.addLeaf(l)
+	t.added(l)
+	return nil
+}
+
+// addLeaf puts l, which must sort after every leaf added so far, into
+// the structure, leaving the accounting to the caller
+func (a *appender) addLeaf(l *leafNode) {
+	t := a.t
+	switch {
+	case t.smallMode && len(t.small) < t.smallMax:
+		t.small = append(t.small, l)
+	case t.smallMode:
+		// Outgrown, so move the entries so far into nodes
+		t.smallMode = false
+		a.prev = ""
+		for _, l := range t.small {
+			a.place(l)
+		}
+		t.small = nil
+		a.place(l)
+	default:
+		a.place(l)
+	}
+	a.prev = l.key
+}
+
+// place puts l, which sorts after every leaf placed so far, into the
+// structure
+func (a *appender) place(l *leafNode) {
+	if a.stack == nil {
+		a.stack = []appendFrame{{n: a.t.root}}
+	}
+	a.closeAbove(longestPrefix(a.prev, l.key))
+	a.prev = l.key
+	if l.key == "" {
+		a.t.root.leaf = l
+		return
+	}
+	n := a.t.newNode("", len(l.key))
+	n.leaf = l
+	a.stack = append(a.stack, appendFrame{n: n, depth: len(l.key), key: l.key})
+}
+
+// closeAbove completes the open nodes whose paths are longer than
+// depth, hanging each off its parent. Where no open node ends at
+// depth, one is opened so the completed nodes have a common parent.
+func (a *appender) closeAbove(depth int) {
+	for {
+		f := a.stack[len(a.stack)-1]
+		if f.depth <= depth {
+			return
+		}
+		a.stack = a.stack[:len(a.stack)-1]
+		parent := a.stack[len(a.stack)-1]
+		if parent.depth < depth {
+			parent = appendFrame{n: a.t.newNode("", depth), depth: depth, key: f.key}
+			a.stack = append(a.stack, parent)
+		}
+		f.n.prefix = f.key[parent.depth:f.depth]
+		if a.t.counted() {
+			a.t.recompute(f.n)
+		}
+		parent.n.addEdge(edge{label: f.n.prefix[0], node: f.n})
+	}
+}
+
+// finish completes every open node, leaving the tree ready for use
+func (a *appender) finish() {
+	if a.stack == nil {
+		return
+	}
+	a.closeAbove(0)
+	a.t.refreshPath("")
+	a.stack = nil
+}
@@ -0,0 +1,169 @@
+package radix
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestLoadParallel(t *testing.T) {
+	inp := make(map[string]interface{})
+	for i := 0; i < 5000; i++ {
+		inp[generateUUID()[:i%12+1]] = i
+	}
+	inp[""] = -1
+	sum := func(k string, v interface{}) float64 {
+		return float64(v.(int))
+	}
+
+	for _, opts := range [][]Option{
+		nil,
+		{WithCollation(CaseInsensitiveCollation()), WithDenseDispatch(2)},
+		{WithSmallTree(4), WithAdaptiveNodes()},
+	} {
+		s := newMapStore()
+		r := New(append(opts, WithStore(s, nil), WithAggregate(sum))...)
+		r.LoadParallel(inp, 4)
+		want := New(append(opts, WithAggregate(sum))...)
+		for k, v := range inp {
+			want.Insert(k, v)
+		}
+
+		validateTree(t, r.root)
+		if r.Len() != len(inp) || !reflect.DeepEqual(r.ToMap(), inp) || !reflect.DeepEqual(s.m, inp) {
+			t.Fatalf("bad contents")
+		}
+		var got, order []string
+		r.Walk(func(k string, v interface{}) bool {
+			got = append(got, k)
+			return false
+		})
+		for l := r.leaves.head; l != nil; l = l.next {
+			order = append(order, l.key)
+		}
+		want.Walk(func(k string, v interface{}) bool {
+			if len(got) == 0 || got[0] != k || order[0] != k {
+				t.Fatalf("bad order at %q", k)
+			}
+			got, order = got[1:], order[1:]
+			return false
+		})
+		if r.Aggregate("") != want.Aggregate("") || r.Aggregate("a") != want.Aggregate("a") {
+			t.Fatalf("bad aggregates: %+v %+v", r.Aggregate("a"), want.Aggregate("a"))
+		}
+
+		// The loaded tree behaves like any other
+		r.DeletePrefix("a")
+		want.DeletePrefix("a")
+		if !reflect.DeepEqual(r.ToMap(), want.ToMap()) {
+			t.Fatalf("bad contents after delete")
+		}
+	}
+}
+
+func TestLoadParallel_Small(t *testing.T) {
+	r := New(WithSmallTree(8))
+	r.LoadParallel(map[string]interface{}{"a": 1, "b": 2}, 0)
+	if !r.smallMode {
+		t.Fatalf("tree should stay small")
+	}
+	checkKeys(t, r, []string{"a", "b"})
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("loading a non-empty tree should panic")
+		}
+	}()
+	r.LoadParallel(map[string]interface{}{"c": 3}, 0)
+}
+
+// sortedRecords encodes keys, with themselves as values, in the
+// format read by BuildFromSortedReader
+func sortedRecords(keys []string) *bytes.Buffer {
+	var buf bytes.Buffer
+	var tmp [binary.MaxVarintLen64]byte
+	for _, k := range keys {
+		for i := 0; i < 2; i++ {
+			buf.Write(tmp[:binary.PutUvarint(tmp[:], uint64(len(k)))])
+			buf.WriteString(k)
+		}
+	}
+	return &buf
+}
+
+func TestBuildFromSortedReader(t *testing.T) {
+	count := func(k string, v interface{}) float64 {
+		return 1
+	}
+	for _, opts := range [][]Option{
+		nil,
+		{WithCollation(CaseInsensitiveCollation()), WithDenseDispatch(2)},
+		{WithSmallTree(16), WithAdaptiveNodes(), WithAggregate(count)},
+	} {
+		want := New(opts...)
+		want.Insert("", "")
+		for i := 0; i < 3000; i++ {
+			k := generateUUID()[:i%10+1]
+			want.Insert(k, k)
+		}
+		var keys []string
+		want.Walk(func(k string, v interface{}) bool {
+			keys = append(keys, k)
+			return false
+		})
+
+		r, err := BuildFromSortedReader(sortedRecords(keys), stringCodec{}, opts...)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		validateTree(t, r.root)
+		if r.Len() != len(keys) || !reflect.DeepEqual(r.ToMap(), want.ToMap()) {
+			t.Fatalf("bad contents")
+		}
+		i := 0
+		for l := r.leaves.head; l != nil; l, i = l.next, i+1 {
+			if l.key != keys[i] {
+				t.Fatalf("bad order at %d: %q", i, l.key)
+			}
+		}
+		if r.aggFn != nil && r.Aggregate("a") != want.Aggregate("a") {
+			t.Fatalf("bad aggregate: %+v", r.Aggregate("a"))
+		}
+		k, _, _ := want.LongestPrefix(keys[7] + "zz")
+		if got, _, _ := r.LongestPrefix(keys[7] + "zz"); got != k {
+			t.Fatalf("bad: %q %q", got, k)
+		}
+	}
+}
+
+func TestBuildFromSortedReader_Small(t *testing.T) {
+	r, err := BuildFromSortedReader(sortedRecords([]string{"a", "b", "c"}), stringCodec{}, WithSmallTree(4))
+	if err != nil || !r.smallMode {
+		t.Fatalf("bad: %v %v", err, r.smallMode)
+	}
+	checkKeys(t, r, []string{"a", "b", "c"})
+}
+
+func TestBuildFromSortedReader_Errors(t *testing.T) {
+	// Out of order and repeated keys stop the build, leaving the
+	// entries before them
+	for _, keys := range [][]string{{"a", "c", "b"}, {"a", "c", "c"}} {
+		r, err := BuildFromSortedReader(sortedRecords(keys), stringCodec{})
+		if err != ErrUnsorted {
+			t.Fatalf("bad: %v", err)
+		}
+		validateTree(t, r.root)
+		checkKeys(t, r, []string{"a", "c"})
+	}
+
+	buf := sortedRecords([]string{"a", "b"})
+	buf.Truncate(buf.Len() - 1)
+	r, err := BuildFromSortedReader(buf, stringCodec{})
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("bad: %v", err)
+	}
+	checkKeys(t, r, []string{"a"})
+}
@@ -0,0 +1,46 @@
+package radix
+
+import "unsafe"
+
+// bytesView returns b as a string without copying it. The string
+// must not outlive the call it is passed to, since b may change.
+func bytesView(b []byte) string {
+	return *(*string)(unsafe.Pointer(&b))
+}
+
+// InsertBytes is like Insert, for a key held in a byte slice. The key
+// is copied, so b may be reused afterwards, unless the tree was
+// created with WithBorrowedKeys, in which case b must not change.
+func (t *Tree) InsertBytes(b []byte, v interface{}) (interface{}, bool) {
+	if t.borrowKeys {
+		return t.Insert(bytesView(b), v)
+	}
+	return t.Insert(string(b), v)
+}
+
+// GetBytes is like Get, for a key held in a byte slice. Unless the
+// tree has a loader or a prefix profiler, which keep the key, it
+// looks b up in place, without allocating.
+func (t *Tree) GetBytes(b []byte) (interface{}, bool) {
+	if t.loader != nil || t.profile != nil {
+		return t.Get(string(b))
+	}
+	return t.Get(bytesView(b))
+}
+
+// DeleteBytes is like Delete, for a key held in a byte slice. It
+// looks b up in place, without allocating.
+func (t *Tree) DeleteBytes(b []byte) (interface{}, bool) {
+	return t.Delete(bytesView(b))
+}
+
+// LongestPrefixBytes is like LongestPrefix, for a key held in a byte
+// slice. The key returned is the stored one, not part of b. Unless
+// the tree has a prefix profiler, it looks b up in place, without
+// allocating.
+func (t *Tree) LongestPrefixBytes(b []byte) (string, interface{}, bool) {
+	if t.profile != nil {
+		return t.LongestPrefix(string(b))
+	}
+	return t.LongestPrefix(bytesView(b))
+}
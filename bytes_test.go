@@ -0,0 +1,70 @@
+package radix
+
+import "testing"
+
+func TestBytesKeys(t *testing.T) {
+	r := New()
+	buf := []byte("foobar")
+	r.InsertBytes(buf[:3], 1)
+	r.InsertBytes(buf, 2)
+
+	// The stored keys must not alias buf
+	copy(buf, "xxxxxx")
+	checkKeys(t, r, []string{"foo", "foobar"})
+
+	copy(buf, "foobaz")
+	if v, ok := r.GetBytes(buf[:3]); !ok || v != 1 {
+		t.Fatalf("bad: %v %v", v, ok)
+	}
+	if _, ok := r.GetBytes(buf); ok {
+		t.Fatalf("unexpected foobaz")
+	}
+	k, v, ok := r.LongestPrefixBytes(buf)
+	if !ok || k != "foo" || v != 1 {
+		t.Fatalf("bad: %q %v %v", k, v, ok)
+	}
+	copy(buf, "xxx")
+	if k != "foo" {
+		t.Fatalf("returned key aliases the input: %q", k)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		r.GetBytes(buf)
+		r.LongestPrefixBytes(buf)
+	})
+	if allocs != 0 {
+		t.Fatalf("lookups allocated %v times", allocs)
+	}
+
+	if v, ok := r.DeleteBytes([]byte("foobar")); !ok || v != 2 {
+		t.Fatalf("bad: %v %v", v, ok)
+	}
+	checkKeys(t, r, []string{"foo"})
+}
+
+func TestBytesKeys_Borrowed(t *testing.T) {
+	r := New(WithBorrowedKeys())
+	keys := [][]byte{[]byte("foo"), []byte("foobar"), []byte("zip")}
+	for i, k := range keys {
+		r.InsertBytes(k, i)
+	}
+	checkKeys(t, r, []string{"foo", "foobar", "zip"})
+
+	// Updates take the key without copying it
+	allocs := testing.AllocsPerRun(100, func() {
+		r.InsertBytes(keys[1], 1)
+	})
+	if allocs != 0 {
+		t.Fatalf("update allocated %v times", allocs)
+	}
+
+	// Without the option, the key is copied every time
+	r = New()
+	r.InsertBytes(keys[1], 1)
+	allocs = testing.AllocsPerRun(100, func() {
+		r.InsertBytes(keys[1], 1)
+	})
+	if allocs != 1 {
+		t.Fatalf("update allocated %v times", allocs)
+	}
+}
@@ -0,0 +1,48 @@
+package radix
+
+// reserve preallocates the structure for n entries, see WithCapacity
+func (t *Tree) reserve(n int) {
+	if n <= 0 {
+		return
+	}
+	t.leafSlab = make([]leafNode, n)
+	if t.smallMode {
+		small := n
+		if small > t.smallMax {
+			small = t.smallMax
+		}
+		t.small = make([]*leafNode, 0, small)
+		if n <= t.smallMax {
+			return
+		}
+	}
+	t.nodeSlab = make([]node, n)
+	fanout := n
+	if fanout > 256 {
+		fanout = 256
+	}
+	t.root.edges = make(edges, 0, fanout)
+}
+
+// newLeaf returns a new leaf, taken from the preallocated slab while
+// it lasts
+func (t *Tree) newLeaf(key string, v interface{}) *leafNode {
+	if len(t.leafSlab) == 0 {
+		return &leafNode{key: key, val: v}
+	}
+	l := &t.leafSlab[0]
+	t.leafSlab = t.leafSlab[1:]
+	l.key, l.val = key, v
+	return l
+}
+
+// allocNode returns a zero node, taken from the preallocated slab
+// while it lasts
+func (t *Tree) allocNode() *node {
+	if len(t.nodeSlab) == 0 {
+		return &node{}
+	}
+	n := &t.nodeSlab[0]
+	t.nodeSlab = t.nodeSlab[1:]
+	return n
+}
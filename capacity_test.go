@@ -0,0 +1,51 @@
+package radix
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestWithCapacity(t *testing.T) {
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%04d", i)
+	}
+	load := func(opts ...Option) *Tree {
+		r := New(opts...)
+		for _, k := range keys {
+			r.Insert(k, nil)
+		}
+		return r
+	}
+	plain := testing.AllocsPerRun(5, func() { load() })
+	sized := testing.AllocsPerRun(5, func() { load(WithCapacity(len(keys))) })
+	if sized >= plain/2 {
+		t.Fatalf("expected fewer allocations: %v vs %v", sized, plain)
+	}
+
+	for _, opts := range [][]Option{
+		{WithCapacity(10)},
+		{WithCapacity(10), WithSmallTree(4)},
+		{WithCapacity(10), WithSmallTree(2000)},
+	} {
+		r := load(opts...)
+		checkKeys(t, r, keys)
+		validateTree(t, r.root)
+		for _, k := range keys[:500] {
+			r.Delete(k)
+		}
+		checkKeys(t, r, keys[500:])
+	}
+
+	// Entries added after a delete don't come from the blocks
+	r := New(WithCapacity(10))
+	r.Insert("a", 1)
+	r.Delete("a")
+	if len(r.leafSlab) != 0 || len(r.nodeSlab) != 0 {
+		t.Fatalf("expected the blocks to be released")
+	}
+
+	r = New(WithCapacity(100))
+	r.LoadParallel(map[string]interface{}{"a": 1, "b": 2, "ab": 3}, 2)
+	checkKeys(t, r, []string{"a", "ab", "b"})
+}
@@ -0,0 +1,41 @@
+package radix
+
+import "errors"
+
+// ErrModified is returned by the checked walks when entries were
+// added to or removed from the tree while walking it
+var ErrModified = errors.New("radix: tree modified during walk")
+
+// WalkChecked is like Walk, but stops with ErrModified as soon as fn
+// returns having added or removed an entry, rather than carrying on
+// over the changed tree. Replacing the value of an existing entry is
+// allowed. It returns nil otherwise, including when fn stops the walk.
+func (t *Tree) WalkChecked(fn WalkFn) error {
+	var err error
+	t.Walk(t.checked(fn, &err))
+	return err
+}
+
+// WalkPrefixChecked is like WalkPrefix, but stops with ErrModified
+// when the tree is modified, see WalkChecked.
+func (t *Tree) WalkPrefixChecked(prefix string, fn WalkFn) error {
+	var err error
+	t.WalkPrefix(prefix, t.checked(fn, &err))
+	return err
+}
+
+// checked wraps fn to stop the walk and set err if the entries of the
+// tree change
+func (t *Tree) checked(fn WalkFn, err *error) WalkFn {
+	mods := t.mods
+	return func(k string, v interface{}) bool {
+		if fn(k, v) {
+			return true
+		}
+		if t.mods != mods {
+			*err = ErrModified
+			return true
+		}
+		return false
+	}
+}
@@ -0,0 +1,54 @@
+package radix
+
+import "testing"
+
+func TestWalkChecked(t *testing.T) {
+	r := New()
+	for _, k := range []string{"a", "b", "c", "d"} {
+		r.Insert(k, k)
+	}
+
+	// Updating values is fine
+	err := r.WalkChecked(func(k string, v interface{}) bool {
+		r.Insert(k, k+k)
+		return false
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if v, _ := r.Get("d"); v != "dd" {
+		t.Fatalf("bad: %v", v)
+	}
+
+	var visited []string
+	err = r.WalkChecked(func(k string, v interface{}) bool {
+		visited = append(visited, k)
+		if k == "b" {
+			r.Delete("c")
+		}
+		return false
+	})
+	if err != ErrModified || len(visited) != 2 {
+		t.Fatalf("bad: %v %v", err, visited)
+	}
+
+	err = r.WalkPrefixChecked("", func(k string, v interface{}) bool {
+		r.Insert("z", nil)
+		return false
+	})
+	if err != ErrModified {
+		t.Fatalf("bad: %v", err)
+	}
+	if err := r.WalkPrefixChecked("a", func(k string, v interface{}) bool { return true }); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Moving the entries out counts too
+	err = r.WalkChecked(func(k string, v interface{}) bool {
+		r.Split("b")
+		return false
+	})
+	if err != ErrModified {
+		t.Fatalf("bad: %v", err)
+	}
+}
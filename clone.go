@@ -0,0 +1,63 @@
+package radix
+
+import "container/heap"
+
+// Clone returns a copy of the tree, built in one pass over its entries
+// rather than by inserting them one at a time. Entries keep their
+// expiry, hit counts and insertion order, and values are shared, not
+// copied. The clone has the configuration of t, except that it
+// doesn't write to t's store or log, runs no janitor, and samples
+// into a prefix profile of its own, starting empty. Subsequent
+// changes to either tree don't affect the other, and each may be
+// read concurrently with the other.
+func (t *Tree) Clone() *Tree {
+	c := t.derive()
+	c.store, c.wal = nil, nil
+	if t.profile != nil {
+		c.profile = newPrefixProfile(t.profile.every, t.profile.depth)
+	}
+	c.smallMode = t.smallMode
+	c.mods, c.generation = t.mods, t.generation
+	c.leafSlab = make([]leafNode, t.size)
+	if !t.smallMode {
+		c.nodeSlab = make([]node, t.size)
+	}
+
+	var copies map[*leafNode]*leafNode
+	if t.insertionOrder {
+		copies = make(map[*leafNode]*leafNode, t.size)
+	}
+	leaves := make([]*leafNode, 0, t.size)
+	for l := t.leaves.head; l != nil; l = l.next {
+		cl := c.newLeaf(l.key, l.val)
+		if l.ext != nil {
+			cl.ext = &leafExt{expires: l.ext.expires, ttl: l.ext.ttl, hits: l.ext.hits}
+		}
+		c.leaves.insert(cl, c.leaves.tail, nil)
+		c.size++
+		c.bytes += c.entryBytes(cl.key, cl.val)
+		if cl.expires() != 0 {
+			heap.Push(&c.expiry, cl)
+		}
+		if c.lfuCap > 0 {
+			heap.Push(&c.lfu, cl)
+		}
+		if copies != nil {
+			copies[l] = cl
+		}
+		leaves = append(leaves, cl)
+	}
+	for l := t.order.oldest; l != nil; l = l.ext.newer {
+		c.order.push(copies[l])
+	}
+
+	if c.smallMode {
+		c.small = leaves
+	} else {
+		c.root = c.buildNode("", 0, leaves)
+	}
+	if t.history != nil {
+		c.history = t.history.Clone()
+	}
+	return c
+}
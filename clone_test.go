@@ -0,0 +1,110 @@
+package radix
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClone(t *testing.T) {
+	agg := WithAggregate(func(k string, v interface{}) float64 { return float64(v.(int)) })
+	for _, opts := range [][]Option{
+		nil,
+		{WithSmallTree(100)},
+		{agg, WithAdaptiveNodes()},
+		{WithInsertionOrder(), WithLFU(0)},
+	} {
+		r := New(opts...)
+		var keys []string
+		for i := 0; i < 50; i++ {
+			k := generateUUID()
+			keys = append(keys, k)
+			r.Insert(k, i)
+		}
+		c := r.Clone()
+		if !reflect.DeepEqual(c.ToMap(), r.ToMap()) {
+			t.Fatalf("clone differs")
+		}
+		if !c.smallMode {
+			validateTree(t, c.root)
+		}
+
+		// The trees are independent
+		c.Delete(keys[0])
+		r.Insert("extra", 0)
+		if _, ok := r.Get(keys[0]); !ok {
+			t.Fatalf("delete reached the original")
+		}
+		if _, ok := c.Get("extra"); ok {
+			t.Fatalf("insert reached the clone")
+		}
+		if c.Len() != 49 || r.Len() != 51 {
+			t.Fatalf("bad: %d %d", c.Len(), r.Len())
+		}
+
+		if r.aggFn != nil {
+			if a := c.Aggregate(""); a.Count != 49 {
+				t.Fatalf("bad: %+v", a)
+			}
+		}
+		if r.insertionOrder {
+			var got []string
+			c.WalkInsertOrder(func(k string, v interface{}) bool {
+				got = append(got, k)
+				return false
+			})
+			if !reflect.DeepEqual(got, keys[1:]) {
+				t.Fatalf("bad order: %v", got)
+			}
+		}
+	}
+}
+
+func TestClone_TTL(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	r := New(WithClock(clock))
+	r.InsertWithTTL("a", 1, time.Second)
+	r.Insert("b", 2)
+	c := r.Clone()
+	clock.advance(2 * time.Second)
+	if n := c.EvictExpired(); n != 1 {
+		t.Fatalf("bad: %d", n)
+	}
+	checkKeys(t, c, []string{"b"})
+	if r.Len() != 2 {
+		t.Fatalf("eviction reached the original")
+	}
+}
+
+func TestClone_Concurrent(t *testing.T) {
+	r := New(WithPrefixProfiler(1, 2))
+	for i := 0; i < 100; i++ {
+		r.Insert(generateUUID(), i)
+	}
+	r.Get("miss")
+	c := r.Clone()
+	if p := c.HotPrefixes(0); len(p) != 0 {
+		t.Fatalf("expected an empty profile: %v", p)
+	}
+
+	// Reads sample into each tree's own profile
+	var wg sync.WaitGroup
+	for _, tree := range []*Tree{r, c} {
+		wg.Add(1)
+		go func(tree *Tree) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				tree.Get("abc")
+				tree.LongestPrefix("abcd")
+			}
+		}(tree)
+	}
+	wg.Wait()
+	if p := c.HotPrefixes(0); !reflect.DeepEqual(p, []PrefixCount{{"ab", 2000}}) {
+		t.Fatalf("bad: %v", p)
+	}
+	if p := r.HotPrefixes(1); !reflect.DeepEqual(p, []PrefixCount{{"ab", 2000}}) {
+		t.Fatalf("bad: %v", p)
+	}
+}
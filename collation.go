@@ -0,0 +1,104 @@
+package radix
+
+import "strings"
+
+// Collation defines a custom order for keys by giving the position
+// of every byte value in it. Keys are compared byte by byte using
+// those positions, with a shorter key sorting before any longer key
+// it is a prefix of. A Collation must be a permutation of the 256
+// byte values.
+type Collation [256]byte
+
+// CaseInsensitiveCollation returns a Collation that sorts each ASCII
+// letter next to its other case, upper case first, so that "apple",
+// "Banana" and "cherry" sort in that order. Other bytes keep their
+// relative order.
+func CaseInsensitiveCollation() *Collation {
+	var bytes [256]byte
+	for i := range bytes {
+		bytes[i] = byte(i)
+	}
+	fold := func(b byte) byte {
+		if 'A' <= b && b <= 'Z' {
+			return b + 'a' - 'A'
+		}
+		return b
+	}
+	// Insertion sort by folded value, keeping raw order for ties
+	for i := 1; i < len(bytes); i++ {
+		for j := i; j > 0 && fold(bytes[j]) < fold(bytes[j-1]); j-- {
+			bytes[j], bytes[j-1] = bytes[j-1], bytes[j]
+		}
+	}
+	c := new(Collation)
+	for pos, b := range bytes {
+		c[b] = byte(pos)
+	}
+	return c
+}
+
+// validate panics unless c is a permutation
+func (c *Collation) validate() {
+	var seen [256]bool
+	for _, pos := range c {
+		if seen[pos] {
+			panic("radix: collation is not a permutation")
+		}
+		seen[pos] = true
+	}
+}
+
+// Compare returns an integer comparing a and b in the collation
+// order: 0 if a == b, -1 if a < b and +1 if a > b. A nil Collation
+// compares in byte order.
+func (c *Collation) Compare(a, b string) int {
+	if c == nil {
+		return strings.Compare(a, b)
+	}
+	n := longestPrefix(a, b)
+	switch {
+	case n < len(a) && n < len(b):
+		if c[a[n]] < c[b[n]] {
+			return -1
+		}
+		return 1
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
+
+// less reports whether byte a sorts before byte b
+func (c *Collation) less(a, b byte) bool {
+	if c == nil {
+		return a < b
+	}
+	return c[a] < c[b]
+}
+
+// collatedEdgeIndex is edgeIndex for a node with a collation
+func (n *node) collatedEdgeIndex(label byte) int {
+	c := n.ext.collation
+	pos := c[label]
+	num := len(n.edges)
+	if num < linearSearchThreshold {
+		for i := 0; i < num; i++ {
+			if c[n.edges[i].label] >= pos {
+				return i
+			}
+		}
+		return num
+	}
+	lo, hi := 0, num
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if c[n.edges[mid].label] < pos {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
@@ -0,0 +1,97 @@
+package radix
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestCollation_Compare(t *testing.T) {
+	c := CaseInsensitiveCollation()
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"a", "a", 0},
+		{"A", "a", -1},
+		{"a", "B", -1},
+		{"Zebra", "apple", 1},
+		{"ab", "abc", -1},
+		{"b", "Ab", 1},
+		{"_", "A", -1},
+	}
+	for _, tc := range cases {
+		if got := c.Compare(tc.a, tc.b); got != tc.want {
+			t.Fatalf("Compare(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+
+	var nilc *Collation
+	if nilc.Compare("Zebra", "apple") != -1 {
+		t.Fatalf("nil collation should use byte order")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic")
+		}
+	}()
+	WithCollation(&Collation{})
+}
+
+func TestCollation_Order(t *testing.T) {
+	c := CaseInsensitiveCollation()
+	r := New(WithCollation(c), WithAggregate(func(k string, v interface{}) float64 {
+		return 1
+	}))
+	letters := "aAbBcCzZ09_"
+	var keys []string
+	for i := 0; i < 500; i++ {
+		b := make([]byte, 1+rand.Intn(4))
+		for j := range b {
+			b[j] = letters[rand.Intn(len(letters))]
+		}
+		if _, ok := r.Insert(string(b), i); !ok {
+			keys = append(keys, string(b))
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.Compare(keys[i], keys[j]) < 0
+	})
+	checkKeys(t, r, keys)
+	validateTree(t, r.root)
+
+	if k, _, _ := r.Minimum(); k != keys[0] {
+		t.Fatalf("bad: %q", k)
+	}
+	if k, _, _ := r.Maximum(); k != keys[len(keys)-1] {
+		t.Fatalf("bad: %q", k)
+	}
+	if k, _, ok := r.Successor("b"); !ok || k != keys[sort.Search(len(keys), func(i int) bool {
+		return c.Compare(keys[i], "b") > 0
+	})] {
+		t.Fatalf("bad: %q", k)
+	}
+	if a := r.AggregateRange("B", "c"); a.Count != sort.Search(len(keys), func(i int) bool {
+		return c.Compare(keys[i], "c") >= 0
+	})-sort.Search(len(keys), func(i int) bool {
+		return c.Compare(keys[i], "B") >= 0
+	}) {
+		t.Fatalf("bad: %d", a.Count)
+	}
+
+	f := r.Freeze()
+	for _, k := range keys {
+		if _, ok := f.Get(k); !ok {
+			t.Fatalf("missing %q", k)
+		}
+	}
+
+	mid := sort.Search(len(keys), func(i int) bool {
+		return c.Compare(keys[i], "b") >= 0
+	})
+	left, right := r.Split("b")
+	checkKeys(t, left, keys[:mid])
+	checkKeys(t, right, keys[mid:])
+}
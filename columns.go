@@ -0,0 +1,46 @@
+package radix
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ExportColumns writes the tree as two columns, streaming the keys in
+// key order to keys and the matching values to vals. Each column is a
+// single-column CSV file with a header of "key" or "value", where
+// line n of one file corresponds to line n of the other. format turns
+// values into text. Non-printable characters and invalid UTF-8 are
+// written as \xHH escapes, and backslashes as \\, so every entry is
+// exactly one line.
+//
+// The files can be loaded by analytics tools without further
+// processing, for example in DuckDB with
+//
+//	SELECT * FROM 'keys.csv' POSITIONAL JOIN 'values.csv'
+func (t *Tree) ExportColumns(keys, vals io.Writer, format func(v interface{}) string) error {
+	kw, vw := bufio.NewWriter(keys), bufio.NewWriter(vals)
+	writeCSVField(kw, "key")
+	writeCSVField(vw, "value")
+	for l := t.leaves.head; l != nil; l = l.next {
+		writeCSVField(kw, escapeKey(l.key, ""))
+		writeCSVField(vw, escapeKey(format(l.val), ""))
+	}
+	if err := kw.Flush(); err != nil {
+		return fmt.Errorf("radix: exporting keys: %w", err)
+	}
+	if err := vw.Flush(); err != nil {
+		return fmt.Errorf("radix: exporting values: %w", err)
+	}
+	return nil
+}
+
+// writeCSVField writes s as a quoted CSV record of its own. Quoting
+// every field keeps empty ones from reading as blank lines. Write
+// errors are sticky and reported by Flush.
+func writeCSVField(w *bufio.Writer, s string) {
+	w.WriteByte('"')
+	w.WriteString(strings.ReplaceAll(s, `"`, `""`))
+	w.WriteString("\"\n")
+}
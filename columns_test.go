@@ -0,0 +1,65 @@
+package radix
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExportColumns(t *testing.T) {
+	r := New()
+	r.Insert("b", "two, \"quoted\"")
+	r.Insert("a", 1)
+	r.Insert("c\nd", "line\nbreak\\")
+	r.Insert("", nil)
+
+	var keys, vals bytes.Buffer
+	err := r.ExportColumns(&keys, &vals, func(v interface{}) string {
+		return fmt.Sprint(v)
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	kr, err := csv.NewReader(&keys).ReadAll()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	vr, err := csv.NewReader(&vals).ReadAll()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	wantKeys := []string{"key", "", "a", "b", `c\x0ad`}
+	wantVals := []string{"value", "<nil>", "1", `two, "quoted"`, `line\x0abreak\\`}
+	if len(kr) != len(wantKeys) || len(vr) != len(wantVals) {
+		t.Fatalf("bad: %q %q", kr, vr)
+	}
+	for i := range wantKeys {
+		if kr[i][0] != wantKeys[i] || vr[i][0] != wantVals[i] {
+			t.Fatalf("bad row %d: %q %q", i, kr[i], vr[i])
+		}
+	}
+	if k, _ := unescapeKey(kr[4][0]); k != "c\nd" {
+		t.Fatalf("bad: %q", k)
+	}
+}
+
+// failWriter fails every write
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestExportColumns_Error(t *testing.T) {
+	r := New()
+	r.Insert("a", 1)
+	err := r.ExportColumns(&bytes.Buffer{}, failWriter{}, func(v interface{}) string {
+		return fmt.Sprint(v)
+	})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+}
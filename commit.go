@@ -0,0 +1,154 @@
+package radix
+
+import (
+	"sync"
+	"time"
+)
+
+// committer appends log records in batches, making each batch durable
+// with a single Sync. Records wait up to maxLatency for the batch to
+// fill before it is committed. See WithGroupCommit.
+type committer struct {
+	backend    Backend
+	onErr      func(error)
+	maxLatency time.Duration
+	maxBatch   int
+
+	mu      sync.Mutex
+	synced  *sync.Cond
+	pending [][]byte
+
+	// queued counts the records added, and durable those synced
+	queued, durable uint64
+
+	// err is the first failed commit. Nothing after it is durable.
+	err error
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+func newCommitter(b Backend, onErr func(error), maxLatency time.Duration, maxBatch int) *committer {
+	c := &committer{
+		backend:    b,
+		onErr:      onErr,
+		maxLatency: maxLatency,
+		maxBatch:   maxBatch,
+		wake:       make(chan struct{}, 1),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	c.synced = sync.NewCond(&c.mu)
+	go c.run()
+	return c
+}
+
+// add queues a record for the next batch
+func (c *committer) add(rec []byte) {
+	c.mu.Lock()
+	c.pending = append(c.pending, rec)
+	c.queued++
+	n := len(c.pending)
+	c.mu.Unlock()
+	if n == 1 || (c.maxBatch > 0 && n >= c.maxBatch) {
+		select {
+		case c.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// full reports whether the pending batch has reached its size limit
+func (c *committer) full() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.maxBatch > 0 && len(c.pending) >= c.maxBatch
+}
+
+// run commits batches until stopped
+func (c *committer) run() {
+	defer close(c.done)
+	for {
+		select {
+		case <-c.wake:
+		case <-c.stop:
+			c.commit()
+			return
+		}
+
+		// Give the batch time to fill
+		timer := time.NewTimer(c.maxLatency)
+		stopping := false
+	wait:
+		for !c.full() {
+			select {
+			case <-timer.C:
+				break wait
+			case <-c.wake:
+			case <-c.stop:
+				stopping = true
+				break wait
+			}
+		}
+		timer.Stop()
+		c.commit()
+		if stopping {
+			return
+		}
+	}
+}
+
+// commit appends and syncs the pending batch
+func (c *committer) commit() {
+	c.mu.Lock()
+	batch, seq, failed := c.pending, c.queued, c.err != nil
+	c.pending = nil
+	c.mu.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+
+	var err error
+	if !failed {
+		for _, rec := range batch {
+			if err = c.backend.Append(rec); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = c.backend.Sync()
+		}
+	}
+
+	c.mu.Lock()
+	if err != nil {
+		c.err = err
+	}
+	c.durable = seq
+	c.synced.Broadcast()
+	c.mu.Unlock()
+	if err != nil && c.onErr != nil {
+		c.onErr(&StoreError{Op: "commit", Err: err})
+	}
+}
+
+// sync waits until every record added so far has been committed
+func (c *committer) sync() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	seq := c.queued
+	for c.durable < seq {
+		c.synced.Wait()
+	}
+	return c.err
+}
+
+// close commits what is pending and stops the goroutine
+func (c *committer) close() {
+	c.once.Do(func() {
+		close(c.stop)
+	})
+	<-c.done
+}
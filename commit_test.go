@@ -0,0 +1,179 @@
+package radix
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncCounter is a backend that counts syncs and can fail them
+type syncCounter struct {
+	*MemBackend
+	mu    sync.Mutex
+	syncs int
+	fail  bool
+}
+
+func (b *syncCounter) Sync() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.syncs++
+	if b.fail {
+		return errors.New("disk gone")
+	}
+	return nil
+}
+
+func (b *syncCounter) count() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.syncs
+}
+
+func TestGroupCommit(t *testing.T) {
+	b := &syncCounter{MemBackend: NewMemBackend()}
+	r := New(WithBackend(b, stringCodec{}, nil), WithGroupCommit(10*time.Millisecond, 0))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			mu.Lock()
+			r.Insert(fmt.Sprintf("key%02d", i), "v")
+			mu.Unlock()
+			if err := r.Sync(); err != nil {
+				t.Errorf("err: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if n := b.count(); n < 1 || n >= 25 {
+		t.Fatalf("writers should share syncs: %d", n)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out := New(WithBackend(b, stringCodec{}, nil))
+	if err := out.Recover(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.Len() != 50 {
+		t.Fatalf("bad: %d", out.Len())
+	}
+}
+
+func TestGroupCommit_Batch(t *testing.T) {
+	b := &syncCounter{MemBackend: NewMemBackend()}
+	r := New(WithBackend(b, stringCodec{}, nil), WithGroupCommit(time.Hour, 3))
+	start := time.Now()
+	r.Insert("a", "1")
+	r.Insert("b", "2")
+	r.Insert("c", "3")
+	if err := r.Sync(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if time.Since(start) > time.Minute || b.count() != 1 {
+		t.Fatalf("a full batch should commit at once: %d", b.count())
+	}
+
+	// Close commits what is pending
+	r.Insert("d", "4")
+	if err := r.Close(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	n := 0
+	b.ReadLog(func([]byte) error { n++; return nil })
+	if n != 4 || b.count() != 2 {
+		t.Fatalf("bad: %d %d", n, b.count())
+	}
+}
+
+func TestGroupCommit_Error(t *testing.T) {
+	b := &syncCounter{MemBackend: NewMemBackend(), fail: true}
+	var errs []error
+	var mu sync.Mutex
+	r := New(WithBackend(b, stringCodec{}, func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}), WithGroupCommit(time.Millisecond, 0))
+	r.Insert("a", "1")
+	if err := r.Sync(); err == nil {
+		t.Fatalf("expected error")
+	}
+	b.mu.Lock()
+	b.fail = false
+	b.mu.Unlock()
+
+	// Nothing after a failed commit is durable
+	r.Insert("b", "2")
+	if err := r.Sync(); err == nil {
+		t.Fatalf("expected error")
+	}
+	if err := r.Close(); err == nil {
+		t.Fatalf("expected error")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	var se *StoreError
+	if len(errs) != 1 || !errors.As(errs[0], &se) || se.Op != "commit" {
+		t.Fatalf("bad: %v", errs)
+	}
+}
+
+func TestDurability(t *testing.T) {
+	cases := []struct {
+		d     Durability
+		syncs int
+	}{
+		{DurabilityNone, 0},
+		{DurabilitySync, 10},
+		{DurabilityEvery(3), 3},
+	}
+	for _, tc := range cases {
+		b := &syncCounter{MemBackend: NewMemBackend()}
+		r := New(WithBackend(b, stringCodec{}, nil), WithDurability(tc.d))
+		for i := 0; i < 10; i++ {
+			r.Insert(fmt.Sprint(i), "v")
+		}
+		if n := b.count(); n != tc.syncs {
+			t.Fatalf("%+v: bad: %d", tc.d, n)
+		}
+	}
+
+	// Async syncs in the background without being asked
+	b := &syncCounter{MemBackend: NewMemBackend()}
+	r := New(WithBackend(b, stringCodec{}, nil), WithDurability(DurabilityAsync), WithGroupCommit(time.Millisecond, 0))
+	r.Insert("a", "1")
+	deadline := time.Now().Add(5 * time.Second)
+	for b.count() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("log was not synced")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	r.Close()
+
+	// Failed syncs are reported
+	b = &syncCounter{MemBackend: NewMemBackend(), fail: true}
+	var errs []error
+	r = New(WithBackend(b, stringCodec{}, func(err error) {
+		errs = append(errs, err)
+	}), WithDurability(DurabilitySync))
+	r.Insert("a", "1")
+	if len(errs) != 1 {
+		t.Fatalf("bad: %v", errs)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic")
+		}
+	}()
+	DurabilityEvery(0)
+}
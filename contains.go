@@ -0,0 +1,59 @@
+package radix
+
+// ContainsAny reports whether any key of the tree occurs in text,
+// returning one such key. The empty key is not counted. The scan runs
+// on an Aho-Corasick automaton of the keys, see ToAhoCorasick, so it
+// takes time linear in the length of text. The automaton is built by
+// the first call after the tree changes and kept until the next
+// change, so interleaving changes and calls rebuilds it every time.
+func (t *Tree) ContainsAny(text string) (string, bool) {
+	return t.automaton().ContainsAny(text)
+}
+
+// cachedAutomaton is the automaton of a tree's entries as of a
+// generation
+type cachedAutomaton struct {
+	a          *AhoCorasick
+	generation uint64
+}
+
+// automaton returns the automaton of the tree's current entries,
+// building it if the tree changed since it was last built. Concurrent
+// readers may each build one, but never see a stale one.
+func (t *Tree) automaton() *AhoCorasick {
+	if c, ok := t.matcher.Load().(cachedAutomaton); ok && c.generation == t.generation {
+		return c.a
+	}
+	a := t.ToAhoCorasick()
+	t.matcher.Store(cachedAutomaton{a: a, generation: t.generation})
+	return a
+}
+
+// ContainsAny reports whether any of the keys occurs in text,
+// returning one such key, in time linear in the length of text.
+func (a *AhoCorasick) ContainsAny(text string) (string, bool) {
+	var found string
+	a.scan(text, func(k int32, start int) bool {
+		found = a.keys[k]
+		return true
+	})
+	return found, found != ""
+}
+
+// FindAll returns every occurrence of a key of the tree in text,
+// ordered by where they start and, for those starting at the same
+// byte, shortest first. The empty key is not matched. It descends the
+// tree once per offset of text, so for many texts the matcher returned
+// by ToAhoCorasick is faster.
+func (t *Tree) FindAll(text string) []Match {
+	var out []Match
+	for i := 0; i < len(text); i++ {
+		t.walkPath(text[i:], func(k string, v interface{}) bool {
+			if k != "" {
+				out = append(out, Match{Key: k, Value: v, Start: i, End: i + len(k)})
+			}
+			return false
+		})
+	}
+	return out
+}
@@ -0,0 +1,93 @@
+package radix
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestContainsAny(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithSmallTree(10)}} {
+		r := New(opts...)
+		for _, k := range []string{"", "bad", "worse", "badly"} {
+			r.Insert(k, nil)
+		}
+		ac := r.ToAhoCorasick()
+		for text, want := range map[string]string{
+			"":                  "",
+			"all good":          "",
+			"ba d":              "",
+			"not bad":           "bad",
+			"this is worse yet": "worse",
+			"abadly":            "bad",
+		} {
+			if k, ok := r.ContainsAny(text); k != want || ok != (want != "") {
+				t.Fatalf("%q: %q %v", text, k, ok)
+			}
+			if k, ok := ac.ContainsAny(text); k != want || ok != (want != "") {
+				t.Fatalf("%q: %q %v", text, k, ok)
+			}
+		}
+	}
+}
+
+func TestFindAll(t *testing.T) {
+	r := New()
+	for _, k := range []string{"", "he", "she", "his", "hers"} {
+		r.Insert(k, len(k))
+	}
+	text := "ushers his"
+	got := r.FindAll(text)
+	want := []Match{
+		{Key: "she", Value: 3, Start: 1, End: 4},
+		{Key: "he", Value: 2, Start: 2, End: 4},
+		{Key: "hers", Value: 4, Start: 2, End: 6},
+		{Key: "his", Value: 3, Start: 7, End: 10},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("bad: %+v", got)
+	}
+	for _, m := range got {
+		if text[m.Start:m.End] != m.Key {
+			t.Fatalf("bad offsets: %+v", m)
+		}
+	}
+	if got := r.FindAll("xyz"); len(got) != 0 {
+		t.Fatalf("bad: %+v", got)
+	}
+}
+
+func TestContainsAny_Cached(t *testing.T) {
+	r := New()
+	r.Insert("bad", nil)
+	if _, ok := r.ContainsAny("not bad"); !ok {
+		t.Fatalf("expected a match")
+	}
+	a := r.automaton()
+	if r.automaton() != a {
+		t.Fatalf("expected the automaton to be reused")
+	}
+
+	// Changes rebuild it
+	r.Insert("worse", nil)
+	if k, ok := r.ContainsAny("worse"); !ok || k != "worse" {
+		t.Fatalf("bad: %q %v", k, ok)
+	}
+	r.Delete("bad")
+	if _, ok := r.ContainsAny("not bad"); ok {
+		t.Fatalf("unexpected match")
+	}
+
+	// Concurrent readers may share it
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok := r.ContainsAny("it got worse"); !ok {
+				t.Errorf("expected a match")
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,48 @@
+package radix
+
+import "errors"
+
+// ErrNotCounter is returned by Add when the key holds a value that
+// is not an int64
+var ErrNotCounter = errors.New("radix: value is not an int64 counter")
+
+// Add treats the value of key as an int64 counter, adding delta to it
+// and returning the result. A missing or expired key is created with a
+// value of delta. It fails with ErrNotCounter if the key holds a value
+// of another type, and with the errors of TryInsert if the new value
+// can't be stored. An existing counter is found and updated in place
+// in a single descent of the tree, without calling the loader or
+// counting a hit; a new one is stored as if by TryInsert.
+func (t *Tree) Add(key string, delta int64) (int64, error) {
+	key = t.norm(key)
+	l := t.getLeaf(key)
+	if l != nil && t.expired(l) {
+		t.expire(l)
+		l = nil
+	}
+	if l == nil {
+		if err := t.checkInsert(key, delta); err != nil {
+			return 0, err
+		}
+		t.insert(key, delta, t.defaultTTL)
+		return delta, nil
+	}
+
+	c, ok := l.val.(int64)
+	if !ok {
+		return 0, ErrNotCounter
+	}
+	n := c + delta
+	if t.budget > 0 && t.sizer != nil {
+		if d := t.sizer(n) - t.sizer(c); d > 0 && t.bytes+d > t.budget {
+			return 0, ErrBudgetExceeded
+		}
+	}
+	l.val = n
+	t.leafUpdated(l, c)
+	if t.defaultTTL > 0 || l.expires() != 0 {
+		t.setExpiry(l, t.defaultTTL)
+	}
+	t.refreshPath(key)
+	return n, nil
+}
@@ -0,0 +1,76 @@
+package radix
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdd(t *testing.T) {
+	r := New()
+	if n, err := r.Add("foo", 3); err != nil || n != 3 {
+		t.Fatalf("bad: %d %v", n, err)
+	}
+	if n, err := r.Add("foo", -5); err != nil || n != -2 {
+		t.Fatalf("bad: %d %v", n, err)
+	}
+	if v, _ := r.Get("foo"); v != int64(-2) {
+		t.Fatalf("bad: %#v", v)
+	}
+
+	r.Insert("bar", 1)
+	if _, err := r.Add("bar", 1); err != ErrNotCounter {
+		t.Fatalf("bad: %v", err)
+	}
+	if v, _ := r.Get("bar"); v != 1 {
+		t.Fatalf("bad: %#v", v)
+	}
+
+	r = New(WithMemoryBudget(1))
+	if _, err := r.Add("foo", 1); err != ErrBudgetExceeded {
+		t.Fatalf("bad: %v", err)
+	}
+}
+
+func TestAdd_Expired(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	expired := 0
+	r := New(WithClock(clock))
+	r.OnExpire(func(string, interface{}) { expired++ })
+	r.InsertWithTTL("k", int64(5), time.Second)
+	clock.advance(time.Hour)
+	if n, err := r.Add("k", 1); err != nil || n != 1 {
+		t.Fatalf("bad: %d %v", n, err)
+	}
+	if v, ok := r.Get("k"); !ok || v != int64(1) || expired != 1 {
+		t.Fatalf("bad: %v %v %d", v, ok, expired)
+	}
+}
+
+func TestAdd_Update(t *testing.T) {
+	r := New(WithAggregate(func(_ string, v interface{}) float64 {
+		return float64(v.(int64))
+	}))
+	for i := 0; i < 3; i++ {
+		r.Add("a/x", 2)
+		r.Add("a/y", 1)
+	}
+	if a := r.Aggregate("a/"); a.Count != 2 || a.Sum != 9 {
+		t.Fatalf("bad: %+v", a)
+	}
+
+	r = New(WithMemoryBudget(entryOverhead+1+8), WithValueSizer(func(v interface{}) int {
+		if v.(int64) > 100 {
+			return 16
+		}
+		return 8
+	}))
+	if _, err := r.Add("k", 100); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := r.Add("k", 1); err != ErrBudgetExceeded {
+		t.Fatalf("bad: %v", err)
+	}
+	if v, _ := r.Get("k"); v != int64(100) {
+		t.Fatalf("bad: %#v", v)
+	}
+}
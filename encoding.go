@@ -0,0 +1,158 @@
+package radix
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// JSONCodec is a ValueCodec using encoding/json. Values are decoded
+// into a new value of Type, or as by json.Unmarshal into an
+// interface{} if Type is nil.
+type JSONCodec struct {
+	Type reflect.Type
+}
+
+// Encode implements ValueCodec
+func (c JSONCodec) Encode(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Decode implements ValueCodec
+func (c JSONCodec) Decode(b []byte) (interface{}, error) {
+	if c.Type == nil {
+		var v interface{}
+		err := json.Unmarshal(b, &v)
+		return v, err
+	}
+	p := reflect.New(c.Type)
+	if err := json.Unmarshal(b, p.Interface()); err != nil {
+		return nil, err
+	}
+	return p.Elem().Interface(), nil
+}
+
+// GobCodec is a ValueCodec using encoding/gob. Values are decoded into
+// a new value of Type. If Type is nil, values are encoded as
+// interfaces, so their concrete types must be registered with
+// gob.Register.
+type GobCodec struct {
+	Type reflect.Type
+}
+
+// Encode implements ValueCodec
+func (c GobCodec) Encode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	var err error
+	if c.Type == nil {
+		err = enc.Encode(&v)
+	} else {
+		err = enc.Encode(v)
+	}
+	return buf.Bytes(), err
+}
+
+// Decode implements ValueCodec
+func (c GobCodec) Decode(b []byte) (interface{}, error) {
+	dec := gob.NewDecoder(bytes.NewReader(b))
+	if c.Type == nil {
+		var v interface{}
+		err := dec.Decode(&v)
+		return v, err
+	}
+	p := reflect.New(c.Type)
+	if err := dec.Decode(p.Interface()); err != nil {
+		return nil, err
+	}
+	return p.Elem().Interface(), nil
+}
+
+// codec returns the codec set by WithValueCodec, or def
+func (t *Tree) codec(def ValueCodec) ValueCodec {
+	if t.valueCodec != nil {
+		return t.valueCodec
+	}
+	return def
+}
+
+// MarshalJSON encodes the entries as a JSON object, in key order,
+// with values encoded as set by WithValueCodec, or by encoding/json.
+// Keys that are not valid UTF-8 don't survive the trip, as with any
+// string encoded by encoding/json.
+func (t *Tree) MarshalJSON() ([]byte, error) {
+	c := t.codec(JSONCodec{})
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for l := t.leaves.head; l != nil; l = l.next {
+		if l != t.leaves.head {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(l.key)
+		if err != nil {
+			return nil, fmt.Errorf("radix: encoding key %q: %w", l.key, err)
+		}
+		v, err := c.Encode(l.val)
+		if err != nil {
+			return nil, fmt.Errorf("radix: encoding value of %q: %w", l.key, err)
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON inserts the entries of a JSON object, keeping any
+// entries already in the tree, as if by TryInsert. Values are decoded
+// as set by WithValueCodec, or by encoding/json. A zero Tree, as
+// allocated by encoding/json, becomes a tree with no options.
+func (t *Tree) UnmarshalJSON(b []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	t.init()
+	c := t.codec(JSONCodec{})
+	for k, raw := range m {
+		v, err := c.Decode(raw)
+		if err != nil {
+			return fmt.Errorf("radix: decoding value of %q: %w", k, err)
+		}
+		if _, _, err := t.TryInsert(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GobEncode writes the tree as a snapshot, see WriteSnapshot, with
+// values encoded as set by WithValueCodec, or by a GobCodec.
+func (t *Tree) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := t.WriteSnapshot(&buf, t.codec(GobCodec{})); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode inserts the entries of a snapshot written by GobEncode,
+// as ReadSnapshot does. A zero Tree, as allocated by encoding/gob,
+// becomes a tree with no options.
+func (t *Tree) GobDecode(b []byte) error {
+	t.init()
+	return t.ReadSnapshot(bytes.NewReader(b), t.codec(GobCodec{}))
+}
+
+// init readies a zero Tree for use
+func (t *Tree) init() {
+	if t.treeExt == nil {
+		t.treeExt = &treeExt{}
+	}
+	if t.root == nil {
+		t.root = t.newNode("", 0)
+	}
+}
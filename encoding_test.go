@@ -0,0 +1,83 @@
+package radix
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type point struct {
+	X, Y int
+}
+
+func TestTreeJSON(t *testing.T) {
+	r := New()
+	r.Insert("b", "two")
+	r.Insert("a", 1.5)
+	r.Insert("a/b", nil)
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if string(b) != `{"a":1.5,"a/b":null,"b":"two"}` {
+		t.Fatalf("bad: %s", b)
+	}
+
+	// A tree allocated by encoding/json
+	var out struct{ T *Tree }
+	if err := json.Unmarshal([]byte(`{"T":`+string(b)+`}`), &out); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(out.T.ToMap(), r.ToMap()) {
+		t.Fatalf("bad: %v", out.T.ToMap())
+	}
+
+	// Typed values
+	typed := New(WithValueCodec(JSONCodec{Type: reflect.TypeOf(point{})}))
+	if err := json.Unmarshal([]byte(`{"p":{"X":1,"Y":2}}`), typed); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if v, _ := typed.Get("p"); v != (point{1, 2}) {
+		t.Fatalf("bad: %#v", v)
+	}
+	if err := json.Unmarshal([]byte(`{"q":"x"}`), typed); err == nil {
+		t.Fatalf("expected a decoding error")
+	}
+}
+
+func TestTreeGob(t *testing.T) {
+	r := New(WithValueCodec(GobCodec{Type: reflect.TypeOf(point{})}))
+	r.Insert("a", point{1, 2})
+	r.Insert("b", point{3, 4})
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(r); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out := New(WithValueCodec(GobCodec{Type: reflect.TypeOf(point{})}))
+	if err := gob.NewDecoder(&buf).Decode(out); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(out.ToMap(), r.ToMap()) {
+		t.Fatalf("bad: %v", out.ToMap())
+	}
+
+	// Untyped values round trip through registered types
+	gob.Register(point{})
+	u := New()
+	u.Insert("p", point{5, 6})
+	u.Insert("s", "str")
+	buf.Reset()
+	if err := gob.NewEncoder(&buf).Encode(struct{ T *Tree }{u}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var got struct{ T *Tree }
+	if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(got.T.ToMap(), u.ToMap()) {
+		t.Fatalf("bad: %v", got.T.ToMap())
+	}
+}
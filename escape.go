@@ -0,0 +1,104 @@
+package radix
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const hexDigits = "0123456789abcdef"
+
+// escapeKey returns a form of the key that is safe to embed in a
+// line or delimiter oriented text export. Backslashes, non-printable
+// characters, invalid UTF-8 and any byte in delims are written as
+// \xHH (or \\ for a backslash), so the result never contains a
+// newline or one of the delimiters. unescapeKey reverses it.
+func escapeKey(s, delims string) string {
+	if !needsEscape(s, delims) {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s) + 8)
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c == '\\' {
+			b.WriteString(`\\`)
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if (r == utf8.RuneError && size == 1) || !unicode.IsPrint(r) ||
+			(size == 1 && strings.IndexByte(delims, c) >= 0) {
+			for j := 0; j < size; j++ {
+				b.WriteString(`\x`)
+				b.WriteByte(hexDigits[s[i+j]>>4])
+				b.WriteByte(hexDigits[s[i+j]&0xf])
+			}
+		} else {
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// needsEscape reports whether escapeKey would change s.
+func needsEscape(s, delims string) bool {
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c == '\\' || strings.IndexByte(delims, c) >= 0 {
+			return true
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if (r == utf8.RuneError && size == 1) || !unicode.IsPrint(r) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+// unescapeKey reverses escapeKey.
+func unescapeKey(s string) (string, error) {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s, nil
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '\\' {
+			b.WriteByte('\\')
+			i++
+			continue
+		}
+		if i+3 < len(s) && s[i+1] == 'x' {
+			hi, ok1 := unhex(s[i+2])
+			lo, ok2 := unhex(s[i+3])
+			if ok1 && ok2 {
+				b.WriteByte(hi<<4 | lo)
+				i += 3
+				continue
+			}
+		}
+		return "", fmt.Errorf("invalid escape sequence at offset %d in %q", i, s)
+	}
+	return b.String(), nil
+}
+
+func unhex(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
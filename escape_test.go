@@ -0,0 +1,48 @@
+package radix
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEscapeKey(t *testing.T) {
+	type exp struct {
+		inp string
+		out string
+	}
+	cases := []exp{
+		{"", ""},
+		{"foo/bar", "foo/bar"},
+		{"héllo", "héllo"},
+		{"a,b", `a\x2cb`},
+		{"line\nbreak", `line\x0abreak`},
+		{`back\slash`, `back\\slash`},
+		{"nul\x00", `nul\x00`},
+		{"bad\xffutf8", `bad\xffutf8`},
+		{`\x41`, `\\x41`},
+	}
+	for _, test := range cases {
+		out := escapeKey(test.inp, ",")
+		if out != test.out {
+			t.Fatalf("bad escape of %q: %q %q", test.inp, out, test.out)
+		}
+		if strings.ContainsAny(out, ",\n") {
+			t.Fatalf("delimiter leaked: %q", out)
+		}
+		back, err := unescapeKey(out)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if back != test.inp {
+			t.Fatalf("round trip mis-match: %q %q", back, test.inp)
+		}
+	}
+}
+
+func TestUnescapeKey_Invalid(t *testing.T) {
+	for _, inp := range []string{`\`, `\x4`, `\xzz`, `\q`} {
+		if _, err := unescapeKey(inp); err == nil {
+			t.Fatalf("expected error for %q", inp)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package radix
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ExportCSV writes every entry to w in key order as CSV, under a
+// header of "key,value", with format turning values into text. Commas,
+// quotes, non-printable characters and invalid UTF-8 in keys and
+// values are written as \xHH escapes, and backslashes as \\, so every
+// entry is exactly one line of two unquoted fields. ImportCSV reads
+// the result back.
+func (t *Tree) ExportCSV(w io.Writer, format func(v interface{}) string) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("key,value\n")
+	for l := t.leaves.head; l != nil; l = l.next {
+		bw.WriteString(escapeKey(l.key, csvDelims))
+		bw.WriteByte(',')
+		bw.WriteString(escapeKey(format(l.val), csvDelims))
+		bw.WriteByte('\n')
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("radix: exporting CSV: %w", err)
+	}
+	return nil
+}
+
+// csvDelims are the bytes escaped in CSV fields besides the
+// non-printable ones
+const csvDelims = `,"`
+
+// ImportCSV inserts the entries of a CSV stream as written by
+// ExportCSV, with parse turning the text of each value back into a
+// value. Entries are stored with the same limits as TryInsert, and an
+// entry that doesn't fit stops the import with its error.
+func (t *Tree) ImportCSV(r io.Reader, parse func(s string) (interface{}, error)) error {
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		s, err := br.ReadString('\n')
+		if err == io.EOF && s == "" {
+			return nil
+		} else if err != nil && err != io.EOF {
+			return fmt.Errorf("radix: CSV line %d: %w", line, err)
+		}
+		s = strings.TrimSuffix(s, "\n")
+		if line == 1 {
+			if s != "key,value" {
+				return fmt.Errorf("radix: CSV line 1: bad header %q", s)
+			}
+			continue
+		}
+		i := strings.IndexByte(s, ',')
+		if i < 0 || strings.IndexByte(s[i+1:], ',') >= 0 {
+			return fmt.Errorf("radix: CSV line %d: want 2 fields", line)
+		}
+		key, err := unescapeKey(s[:i])
+		if err != nil {
+			return fmt.Errorf("radix: CSV line %d: %w", line, err)
+		}
+		text, err := unescapeKey(s[i+1:])
+		if err != nil {
+			return fmt.Errorf("radix: CSV line %d: %w", line, err)
+		}
+		v, err := parse(text)
+		if err != nil {
+			return fmt.Errorf("radix: CSV line %d: %w", line, err)
+		}
+		if _, _, err := t.TryInsert(key, v); err != nil {
+			return err
+		}
+	}
+}
+
+// ExportDOT writes the structure of the tree to w as a Graphviz graph.
+// Each node is labelled with its prefix, except that nodes holding an
+// entry are drawn as boxes labelled with the entry's full key. Labels
+// are escaped like the keys of ExportCSV, with quotes escaped as well,
+// so a label never breaks out of its string.
+func (t *Tree) ExportDOT(w io.Writer) error {
+	t.promote()
+	bw := bufio.NewWriter(w)
+	bw.WriteString("digraph radix {\n")
+	id := 0
+	var visit func(n *node) int
+	visit = func(n *node) int {
+		self := id
+		id++
+		if n.leaf != nil {
+			fmt.Fprintf(bw, "\tn%d [shape=box, label=\"%s\"];\n", self, escapeKey(n.leaf.key, `"`))
+		} else {
+			fmt.Fprintf(bw, "\tn%d [label=\"%s\"];\n", self, escapeKey(n.prefix, `"`))
+		}
+		for _, e := range n.edges {
+			fmt.Fprintf(bw, "\tn%d -> n%d;\n", self, visit(e.node))
+		}
+		return self
+	}
+	visit(t.root)
+	bw.WriteString("}\n")
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("radix: exporting DOT: %w", err)
+	}
+	return nil
+}
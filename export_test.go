@@ -0,0 +1,84 @@
+package radix
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestExportCSV(t *testing.T) {
+	r := New()
+	for _, k := range []string{"", "a", "a,b", "c\nd", "q\"uote", `back\slash`, "bad\xff"} {
+		r.Insert(k, k+",v\n")
+	}
+
+	var buf bytes.Buffer
+	if err := r.ExportCSV(&buf, func(v interface{}) string { return v.(string) }); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	recs, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(recs) != r.Len()+1 {
+		t.Fatalf("bad: %q", recs)
+	}
+	if got := recs[3]; got[0] != `a\x2cb` || got[1] != `a\x2cb\x2cv\x0a` {
+		t.Fatalf("bad: %q", got)
+	}
+
+	back := New()
+	err = back.ImportCSV(&buf, func(s string) (interface{}, error) { return s, nil })
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(back.ToMap(), r.ToMap()) {
+		t.Fatalf("bad: %q", back.ToMap())
+	}
+}
+
+func TestImportCSV_Invalid(t *testing.T) {
+	parse := func(s string) (interface{}, error) { return s, nil }
+	for _, inp := range []string{"k,v\n", "key,value\na\n", "key,value\na,b,c\n", "key,value\n\\q,v\n"} {
+		if err := New().ImportCSV(strings.NewReader(inp), parse); err == nil {
+			t.Fatalf("expected error for %q", inp)
+		}
+	}
+}
+
+func TestExportDOT(t *testing.T) {
+	keys := []string{"a", "a\nc", "a\"b", "ab,d"}
+	r := New()
+	for _, k := range keys {
+		r.Insert(k, nil)
+	}
+	var buf bytes.Buffer
+	if err := r.ExportDOT(&buf); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out := buf.String()
+	stmt := regexp.MustCompile(`^\t(n\d+ \[(shape=box, )?label="[^"\n]*"\]|n\d+ -> n\d+);$`)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	for _, line := range lines[1 : len(lines)-1] {
+		if !stmt.MatchString(line) {
+			t.Fatalf("bad line %q in\n%s", line, out)
+		}
+	}
+
+	var got []string
+	for _, m := range regexp.MustCompile(`shape=box, label="([^"]*)"`).FindAllStringSubmatch(out, -1) {
+		k, err := unescapeKey(m[1])
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		got = append(got, k)
+	}
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, keys) {
+		t.Fatalf("bad: %q\n%s", got, out)
+	}
+}
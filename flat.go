@@ -0,0 +1,52 @@
+package radix
+
+import "sort"
+
+// Flat holds the entries of a tree in key order as flat arrays, for
+// consumers that want to binary search them or build their own
+// static structures. Key i is Keys[Offsets[i]:Offsets[i+1]] and its
+// value is Values[i].
+type Flat struct {
+	Keys    []byte
+	Offsets []int
+	Values  []interface{}
+
+	// collation orders the keys, nil for byte order
+	collation *Collation
+}
+
+// ExportFlat copies the entries of the tree into a Flat, in the order
+// of the tree's collation. The result shares nothing with the tree,
+// except for the values themselves.
+func (t *Tree) ExportFlat() *Flat {
+	f := &Flat{
+		Offsets:   make([]int, 1, t.size+1),
+		Values:    make([]interface{}, 0, t.size),
+		collation: t.collation,
+	}
+	for l := t.leaves.head; l != nil; l = l.next {
+		f.Keys = append(f.Keys, l.key...)
+		f.Offsets = append(f.Offsets, len(f.Keys))
+		f.Values = append(f.Values, l.val)
+	}
+	return f
+}
+
+// Len returns the number of entries
+func (f *Flat) Len() int {
+	return len(f.Values)
+}
+
+// Key returns the i'th key
+func (f *Flat) Key(i int) string {
+	return string(f.Keys[f.Offsets[i]:f.Offsets[i+1]])
+}
+
+// Search returns the index of the first key not less than key, and
+// whether that key is equal to it.
+func (f *Flat) Search(key string) (int, bool) {
+	i := sort.Search(f.Len(), func(i int) bool {
+		return f.collation.Compare(f.Key(i), key) >= 0
+	})
+	return i, i < f.Len() && f.Key(i) == key
+}
@@ -0,0 +1,51 @@
+package radix
+
+import "testing"
+
+func TestExportFlat(t *testing.T) {
+	r := New()
+	keys := []string{"", "a", "ab", "abc", "b", "ba"}
+	for i, k := range keys {
+		r.Insert(k, i)
+	}
+	f := r.ExportFlat()
+	if f.Len() != len(keys) || len(f.Offsets) != len(keys)+1 {
+		t.Fatalf("bad: %d %d", f.Len(), len(f.Offsets))
+	}
+	for i, k := range keys {
+		if f.Key(i) != k || f.Values[i] != i {
+			t.Fatalf("bad %d: %q %v", i, f.Key(i), f.Values[i])
+		}
+		if j, ok := f.Search(k); !ok || j != i {
+			t.Fatalf("bad search %q: %d %v", k, j, ok)
+		}
+	}
+	if i, ok := f.Search("aa"); ok || i != 2 {
+		t.Fatalf("bad: %d %v", i, ok)
+	}
+	if i, ok := f.Search("c"); ok || i != len(keys) {
+		t.Fatalf("bad: %d %v", i, ok)
+	}
+
+	// The export doesn't change with the tree
+	r.Delete("ab")
+	if f.Key(2) != "ab" {
+		t.Fatalf("bad: %q", f.Key(2))
+	}
+}
+
+func TestExportFlat_Collation(t *testing.T) {
+	r := New(WithCollation(CaseInsensitiveCollation()))
+	for _, k := range []string{"b", "A", "a", "C"} {
+		r.Insert(k, nil)
+	}
+	f := r.ExportFlat()
+	for _, k := range []string{"A", "a", "b", "C"} {
+		if _, ok := f.Search(k); !ok {
+			t.Fatalf("missing %q", k)
+		}
+	}
+	if i, _ := f.Search("B"); f.Key(i) != "b" {
+		t.Fatalf("bad: %q", f.Key(i))
+	}
+}
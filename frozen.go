@@ -0,0 +1,343 @@
+package radix
+
+import (
+	"math"
+	"strings"
+)
+
+// Frozen is an immutable copy of a Tree in which nodes, edges and
+// keys live in a handful of large slices addressed by integer
+// indexes rather than pointers. Apart from the values themselves
+// it contains nothing the garbage collector has to scan, which
+// keeps GC cost flat for trees with tens of millions of nodes.
+// A Frozen is created with Tree.Freeze and is safe for concurrent
+// readers.
+//
+// Keys are front coded: each byte of a key is stored once, in the
+// prefix of the node it passes through, rather than again in full at
+// its leaf, which roughly halves the memory taken by path-like keys.
+// Lookups return parts of the string they were given, while walks
+// and Minimum and Maximum assemble each key they report, allocating
+// it.
+type Frozen struct {
+	// nodes holds every node, with the root at index 0
+	nodes []frozenNode
+
+	// labels and children are parallel arrays holding the edges.
+	// A node's edges occupy a contiguous, label-sorted run.
+	labels   []byte
+	children []uint32
+
+	// vals holds the value of every leaf
+	vals []interface{}
+
+	// data holds the bytes of all prefixes
+	data string
+
+	// release frees off-heap memory, see FreezeOffHeap
+	release func() error
+
+	// collation orders the labels, as in the tree it was made from
+	collation *Collation
+}
+
+// frozenNode is the pointer-free counterpart of node
+type frozenNode struct {
+	prefixOff uint32
+	prefixLen uint32
+	edgeOff   uint32
+	edgeLen   uint32
+
+	// leaf is the index into vals plus one, or zero if the node
+	// holds no value
+	leaf uint32
+}
+
+// Freeze returns an immutable, index-based copy of the tree. The
+// tree itself is left untouched and may continue to be modified;
+// values are shared, not copied.
+func (t *Tree) Freeze() *Frozen {
+	root := t.root
+	if t.smallMode {
+		root = t.buildNode("", 0, t.small)
+	}
+	b := &frozenBuilder{f: &Frozen{collation: t.collation}}
+	b.build(root)
+	b.f.data = b.data.String()
+	return b.f
+}
+
+// frozenBuilder accumulates a Frozen during Freeze
+type frozenBuilder struct {
+	f    *Frozen
+	data strings.Builder
+}
+
+// build copies n and everything under it, returning its index
+func (b *frozenBuilder) build(n *node) uint32 {
+	f := b.f
+	idx := index32(len(f.nodes))
+	f.nodes = append(f.nodes, frozenNode{})
+
+	fn := frozenNode{
+		prefixOff: index32(b.data.Len()),
+		prefixLen: index32(len(n.prefix)),
+	}
+	b.data.WriteString(n.prefix)
+
+	if n.leaf != nil {
+		f.vals = append(f.vals, n.leaf.val)
+		fn.leaf = index32(len(f.vals))
+	}
+
+	// Reserve a contiguous run for the edges before recursing
+	fn.edgeOff = index32(len(f.labels))
+	fn.edgeLen = index32(len(n.edges))
+	for _, e := range n.edges {
+		f.labels = append(f.labels, e.label)
+		f.children = append(f.children, 0)
+	}
+	for i, e := range n.edges {
+		f.children[int(fn.edgeOff)+i] = b.build(e.node)
+	}
+
+	f.nodes[idx] = fn
+	return idx
+}
+
+// index32 narrows an index for storage, panicking if it doesn't fit
+func index32(i int) uint32 {
+	if i < 0 || i > math.MaxUint32 {
+		panic("radix: tree too large to freeze")
+	}
+	return uint32(i)
+}
+
+// Len returns the number of entries in the tree
+func (f *Frozen) Len() int {
+	return len(f.vals)
+}
+
+// prefix returns the prefix of a node
+func (f *Frozen) prefix(n *frozenNode) string {
+	return f.data[n.prefixOff : n.prefixOff+n.prefixLen]
+}
+
+// value returns the value stored at a node, if any
+func (f *Frozen) value(n *frozenNode) (interface{}, bool) {
+	if n.leaf == 0 {
+		return nil, false
+	}
+	return f.vals[n.leaf-1], true
+}
+
+// getEdge returns the child of n reached by label, or nil
+func (f *Frozen) getEdge(n *frozenNode, label byte) *frozenNode {
+	labels := f.labels[n.edgeOff : n.edgeOff+n.edgeLen]
+	lo, hi := 0, len(labels)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if f.collation.less(labels[mid], label) {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	if lo < len(labels) && labels[lo] == label {
+		return &f.nodes[f.children[int(n.edgeOff)+lo]]
+	}
+	return nil
+}
+
+// child returns the i-th child of n
+func (f *Frozen) child(n *frozenNode, i int) *frozenNode {
+	return &f.nodes[f.children[int(n.edgeOff)+i]]
+}
+
+// Get is used to lookup a specific key, returning
+// the value and if it was found
+func (f *Frozen) Get(s string) (interface{}, bool) {
+	n := &f.nodes[0]
+	search := s
+	for {
+		// Check for key exhaution
+		if len(search) == 0 {
+			return f.value(n)
+		}
+
+		// Look for an edge
+		n = f.getEdge(n, search[0])
+		if n == nil {
+			return nil, false
+		}
+
+		// Consume the search prefix
+		prefix := f.prefix(n)
+		if !strings.HasPrefix(search, prefix) {
+			return nil, false
+		}
+		search = search[len(prefix):]
+	}
+}
+
+// LongestPrefix is like Get, but instead of an
+// exact match, it will return the longest prefix match.
+func (f *Frozen) LongestPrefix(s string) (string, interface{}, bool) {
+	var lastKey string
+	var lastVal interface{}
+	var found bool
+	n := &f.nodes[0]
+	search := s
+	for {
+		// Look for a leaf node
+		if v, ok := f.value(n); ok {
+			lastKey, lastVal, found = s[:len(s)-len(search)], v, true
+		}
+
+		// Check for key exhaution
+		if len(search) == 0 {
+			break
+		}
+
+		// Look for an edge
+		n = f.getEdge(n, search[0])
+		if n == nil {
+			break
+		}
+
+		// Consume the search prefix
+		prefix := f.prefix(n)
+		if !strings.HasPrefix(search, prefix) {
+			break
+		}
+		search = search[len(prefix):]
+	}
+	return lastKey, lastVal, found
+}
+
+// Minimum is used to return the minimum value in the tree
+func (f *Frozen) Minimum() (string, interface{}, bool) {
+	var key strings.Builder
+	n := &f.nodes[0]
+	for {
+		key.WriteString(f.prefix(n))
+		if v, ok := f.value(n); ok {
+			return key.String(), v, true
+		}
+		if n.edgeLen == 0 {
+			return "", nil, false
+		}
+		n = f.child(n, 0)
+	}
+}
+
+// Maximum is used to return the maximum value in the tree
+func (f *Frozen) Maximum() (string, interface{}, bool) {
+	var key strings.Builder
+	n := &f.nodes[0]
+	for {
+		key.WriteString(f.prefix(n))
+		if n.edgeLen > 0 {
+			n = f.child(n, int(n.edgeLen)-1)
+			continue
+		}
+		if v, ok := f.value(n); ok {
+			return key.String(), v, true
+		}
+		return "", nil, false
+	}
+}
+
+// Walk is used to walk the tree
+func (f *Frozen) Walk(fn WalkFn) {
+	f.walk(&f.nodes[0], nil, fn)
+}
+
+// WalkPrefix is used to walk the tree under a prefix
+func (f *Frozen) WalkPrefix(prefix string, fn WalkFn) {
+	n := &f.nodes[0]
+	search := prefix
+	for {
+		// Check for key exhaustion
+		if len(search) == 0 {
+			f.walk(n, []byte(prefix), fn)
+			return
+		}
+
+		// Look for an edge
+		n = f.getEdge(n, search[0])
+		if n == nil {
+			return
+		}
+
+		// Consume the search prefix
+		p := f.prefix(n)
+		if strings.HasPrefix(search, p) {
+			search = search[len(p):]
+			continue
+		}
+		if strings.HasPrefix(p, search) {
+			// Child may be under our search prefix
+			key := append([]byte(prefix[:len(prefix)-len(search)]), p...)
+			f.walk(n, key, fn)
+		}
+		return
+	}
+}
+
+// WalkPath is used to walk the tree, but only visiting nodes
+// from the root down to a given leaf.
+func (f *Frozen) WalkPath(path string, fn WalkFn) {
+	n := &f.nodes[0]
+	search := path
+	for {
+		// Visit the leaf values if any
+		if v, ok := f.value(n); ok && fn(path[:len(path)-len(search)], v) {
+			return
+		}
+
+		// Check for key exhaution
+		if len(search) == 0 {
+			return
+		}
+
+		// Look for an edge
+		n = f.getEdge(n, search[0])
+		if n == nil {
+			return
+		}
+
+		// Consume the search prefix
+		p := f.prefix(n)
+		if !strings.HasPrefix(search, p) {
+			return
+		}
+		search = search[len(p):]
+	}
+}
+
+// walk does a pre-order walk of n, whose key is key, returning true
+// if aborted. The keys of children are built in key's spare capacity,
+// which each sibling reuses in turn.
+func (f *Frozen) walk(n *frozenNode, key []byte, fn WalkFn) bool {
+	if v, ok := f.value(n); ok && fn(string(key), v) {
+		return true
+	}
+	for i := 0; i < int(n.edgeLen); i++ {
+		c := f.child(n, i)
+		if f.walk(c, append(key, f.prefix(c)...), fn) {
+			return true
+		}
+	}
+	return false
+}
+
+// ToMap is used to walk the tree and convert it into a map
+func (f *Frozen) ToMap() map[string]interface{} {
+	out := make(map[string]interface{}, f.Len())
+	f.Walk(func(k string, v interface{}) bool {
+		out[k] = v
+		return false
+	})
+	return out
+}
@@ -0,0 +1,76 @@
+package radix
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// ErrOffHeapUnsupported is returned by FreezeOffHeap on platforms
+// that have no anonymous memory mappings.
+var ErrOffHeapUnsupported = errors.New("radix: off-heap storage is not supported on this platform")
+
+// FreezeOffHeap is like Freeze, but the frozen nodes, edges and keys
+// are placed in anonymous mapped memory outside the Go heap, so they
+// add nothing to heap size or GC work. Values stay on the heap.
+//
+// This is experimental. The caller must call Close once the Frozen
+// is no longer used, and must not use it afterwards.
+func (t *Tree) FreezeOffHeap() (*Frozen, error) {
+	f := t.Freeze()
+	if err := f.moveOffHeap(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Close releases off-heap memory held by the tree. It is a no-op
+// for trees created with Freeze.
+func (f *Frozen) Close() error {
+	if f.release == nil {
+		return nil
+	}
+	release := f.release
+	f.release = nil
+	f.nodes, f.labels, f.children, f.data = nil, nil, nil, ""
+	return release()
+}
+
+// moveOffHeap copies the pointer-free parts of f into a single
+// anonymous mapping. Every section is 4-byte aligned, and the
+// mapping itself is page aligned.
+func (f *Frozen) moveOffHeap() error {
+	nodesSize := len(f.nodes) * int(unsafe.Sizeof(frozenNode{}))
+	childrenSize := len(f.children) * 4
+	size := nodesSize + childrenSize + len(f.labels) + len(f.data)
+
+	buf, err := mapAnon(size)
+	if err != nil {
+		return err
+	}
+
+	off := 0
+	nodes := unsafe.Slice((*frozenNode)(unsafe.Pointer(&buf[off])), len(f.nodes))
+	copy(nodes, f.nodes)
+	off += nodesSize
+
+	var children []uint32
+	if len(f.children) > 0 {
+		children = unsafe.Slice((*uint32)(unsafe.Pointer(&buf[off])), len(f.children))
+		copy(children, f.children)
+		off += childrenSize
+	}
+
+	labels := buf[off : off+len(f.labels) : off+len(f.labels)]
+	copy(labels, f.labels)
+	off += len(f.labels)
+
+	data := buf[off : off+len(f.data)]
+	copy(data, f.data)
+
+	f.nodes, f.children, f.labels = nodes, children, labels
+	f.data = *(*string)(unsafe.Pointer(&data))
+	f.release = func() error {
+		return unmapAnon(buf)
+	}
+	return nil
+}
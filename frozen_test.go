@@ -0,0 +1,191 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	inp := make(map[string]interface{})
+	for i := 0; i < 1000; i++ {
+		inp[generateUUID()] = i
+	}
+	inp[""] = -1
+	r := NewFromMap(inp)
+	f := r.Freeze()
+
+	if f.Len() != r.Len() {
+		t.Fatalf("bad len: %d %d", f.Len(), r.Len())
+	}
+	for k, v := range inp {
+		out, ok := f.Get(k)
+		if !ok || out != v {
+			t.Fatalf("bad: %v %v %v", k, out, v)
+		}
+	}
+	if _, ok := f.Get("nope"); ok {
+		t.Fatalf("unexpected key")
+	}
+
+	var want, got []string
+	r.Walk(func(k string, v interface{}) bool {
+		want = append(want, k)
+		return false
+	})
+	f.Walk(func(k string, v interface{}) bool {
+		got = append(got, k)
+		return false
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("walk mis-match")
+	}
+
+	rMin, _, _ := r.Minimum()
+	fMin, _, _ := f.Minimum()
+	rMax, _, _ := r.Maximum()
+	fMax, _, _ := f.Maximum()
+	if rMin != fMin || rMax != fMax {
+		t.Fatalf("bad min/max: %q %q %q %q", rMin, fMin, rMax, fMax)
+	}
+	if !reflect.DeepEqual(f.ToMap(), inp) {
+		t.Fatalf("map mis-match")
+	}
+
+	// Changing the tree doesn't affect the frozen copy
+	r.Insert("new", 1)
+	if _, ok := f.Get("new"); ok {
+		t.Fatalf("frozen tree should not change")
+	}
+}
+
+func TestFreeze_Prefix(t *testing.T) {
+	r := New()
+	keys := []string{
+		"foo",
+		"foo/bar",
+		"foo/bar/baz",
+		"foo/baz/bar",
+		"foo/zip/zap",
+		"zipzap",
+	}
+	for _, k := range keys {
+		r.Insert(k, nil)
+	}
+	f := r.Freeze()
+
+	for _, s := range []string{"", "f", "foo", "foo/", "foo/ba", "foo/bar/bazoo", "z", "x"} {
+		var want, got []string
+		r.WalkPrefix(s, func(k string, v interface{}) bool {
+			want = append(want, k)
+			return false
+		})
+		f.WalkPrefix(s, func(k string, v interface{}) bool {
+			got = append(got, k)
+			return false
+		})
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("prefix %q mis-match: %v %v", s, got, want)
+		}
+
+		want, got = nil, nil
+		r.WalkPath(s, func(k string, v interface{}) bool {
+			want = append(want, k)
+			return false
+		})
+		f.WalkPath(s, func(k string, v interface{}) bool {
+			got = append(got, k)
+			return false
+		})
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("path %q mis-match: %v %v", s, got, want)
+		}
+
+		rk, _, rok := r.LongestPrefix(s)
+		fk, _, fok := f.LongestPrefix(s)
+		if rk != fk || rok != fok {
+			t.Fatalf("longest prefix %q mis-match: %q %q", s, fk, rk)
+		}
+	}
+}
+
+func TestFreeze_FrontCoded(t *testing.T) {
+	keys := []string{"/srv/app/config", "/srv/app/data/a", "/srv/app/data/b", "/srv/log"}
+	r := New()
+	var keyBytes int
+	for i, k := range keys {
+		r.Insert(k, i)
+		keyBytes += len(k)
+	}
+	f := r.Freeze()
+
+	// Every byte is stored once, in the prefix of a node
+	var prefixBytes int
+	for i := range f.nodes {
+		prefixBytes += len(f.prefix(&f.nodes[i]))
+	}
+	if len(f.data) != prefixBytes || len(f.data) >= keyBytes/2 {
+		t.Fatalf("bad: %d bytes of data for %d bytes of keys", len(f.data), keyBytes)
+	}
+
+	// Keys are rebuilt from the path, including under a prefix that
+	// ends inside a node
+	var got []string
+	f.WalkPrefix("/srv/app/d", func(k string, v interface{}) bool {
+		got = append(got, k)
+		return false
+	})
+	if !reflect.DeepEqual(got, keys[1:3]) {
+		t.Fatalf("bad: %v", got)
+	}
+	var path []string
+	f.WalkPath("/srv/app/data/a/x", func(k string, v interface{}) bool {
+		path = append(path, k)
+		return false
+	})
+	if !reflect.DeepEqual(path, keys[1:2]) {
+		t.Fatalf("bad: %v", path)
+	}
+	if k, v, ok := f.LongestPrefix("/srv/lo"); ok || k != "" || v != nil {
+		t.Fatalf("bad: %q", k)
+	}
+	if k, _, _ := f.LongestPrefix("/srv/log/x"); k != "/srv/log" {
+		t.Fatalf("bad: %q", k)
+	}
+}
+
+func TestFreezeOffHeap(t *testing.T) {
+	inp := make(map[string]interface{})
+	for i := 0; i < 1000; i++ {
+		inp[generateUUID()] = i
+	}
+	r := NewFromMap(inp)
+	f, err := r.FreezeOffHeap()
+	if err == ErrOffHeapUnsupported {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer f.Close()
+
+	if f.Len() != len(inp) {
+		t.Fatalf("bad len: %d %d", f.Len(), len(inp))
+	}
+	for k, v := range inp {
+		out, ok := f.Get(k)
+		if !ok || out != v {
+			t.Fatalf("bad: %v %v %v", k, out, v)
+		}
+	}
+	min, _, _ := r.Minimum()
+	if k, _, _ := f.Minimum(); k != min {
+		t.Fatalf("bad minimum: %q %q", k, min)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("second close should be a no-op: %v", err)
+	}
+}
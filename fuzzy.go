@@ -0,0 +1,74 @@
+package radix
+
+// FuzzyMatch is an entry found by FuzzySearch
+type FuzzyMatch struct {
+	Key      string
+	Value    interface{}
+	Distance int
+}
+
+// FuzzySearch returns every entry whose key is within maxDistance
+// edits of key, in key order, where an edit inserts, deletes or
+// substitutes one byte. It computes the distance a byte at a time
+// down the tree, sharing the work for keys with a common prefix, and
+// skips any subtree whose prefix is already too far from every prefix
+// of key, so it visits only a fraction of the tree for small
+// distances. Since distance is counted in bytes, a substituted
+// multi-byte character counts as several edits.
+func (t *Tree) FuzzySearch(key string, maxDistance int) []FuzzyMatch {
+	key = t.norm(key)
+	if maxDistance < 0 {
+		return nil
+	}
+	root := t.root
+	if t.smallMode {
+		root = t.buildNode("", 0, t.small)
+	}
+
+	// row[j] is the distance between the path so far and key[:j]
+	row := make([]int, len(key)+1)
+	for j := range row {
+		row[j] = j
+	}
+	var out []FuzzyMatch
+	fuzzy(root, key, row, maxDistance, &out)
+	return out
+}
+
+// fuzzy extends row, the distances for the path above n, through n's
+// prefix, collecting the matches at and under n
+func fuzzy(n *node, key string, row []int, max int, out *[]FuzzyMatch) {
+	for i := 0; i < len(n.prefix); i++ {
+		c := n.prefix[i]
+		next := make([]int, len(row))
+		next[0] = row[0] + 1
+		best := next[0]
+		for j := 1; j < len(row); j++ {
+			d := row[j-1]
+			if key[j-1] != c {
+				d++
+			}
+			if row[j]+1 < d {
+				d = row[j] + 1
+			}
+			if next[j-1]+1 < d {
+				d = next[j-1] + 1
+			}
+			next[j] = d
+			if d < best {
+				best = d
+			}
+		}
+		if best > max {
+			// Every key under n is too far
+			return
+		}
+		row = next
+	}
+	if n.leaf != nil && row[len(key)] <= max {
+		*out = append(*out, FuzzyMatch{Key: n.leaf.key, Value: n.leaf.val, Distance: row[len(key)]})
+	}
+	for _, e := range n.edges {
+		fuzzy(e.node, key, row, max, out)
+	}
+}
@@ -0,0 +1,59 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+)
+
+// levenshtein is the byte edit distance between a and b
+func levenshtein(a, b string) int {
+	row := make([]int, len(b)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 0; i < len(a); i++ {
+		prev := row[0]
+		row[0] = i + 1
+		for j := 1; j <= len(b); j++ {
+			d := prev
+			if a[i] != b[j-1] {
+				d++
+			}
+			if row[j]+1 < d {
+				d = row[j] + 1
+			}
+			if row[j-1]+1 < d {
+				d = row[j-1] + 1
+			}
+			prev, row[j] = row[j], d
+		}
+	}
+	return row[len(b)]
+}
+
+func TestFuzzySearch(t *testing.T) {
+	words := []string{"", "apple", "apply", "ape", "apricot", "banana", "band", "bandana", "can", "cane", "cat"}
+	for _, opts := range [][]Option{nil, {WithSmallTree(100)}} {
+		r := New(opts...)
+		for _, w := range words {
+			r.Insert(w, w)
+		}
+		for _, q := range []string{"", "aple", "appel", "bnd", "cat", "xyz", "bandanna"} {
+			for max := 0; max <= 3; max++ {
+				var want []FuzzyMatch
+				r.Walk(func(k string, v interface{}) bool {
+					if d := levenshtein(q, k); d <= max {
+						want = append(want, FuzzyMatch{Key: k, Value: v, Distance: d})
+					}
+					return false
+				})
+				if got := r.FuzzySearch(q, max); !reflect.DeepEqual(got, want) {
+					t.Fatalf("%q within %d: got %v, want %v", q, max, got, want)
+				}
+			}
+		}
+	}
+	if got := New().FuzzySearch("a", -1); got != nil {
+		t.Fatalf("bad: %v", got)
+	}
+}
@@ -0,0 +1,47 @@
+package radix
+
+import "testing"
+
+func TestGeneration(t *testing.T) {
+	r := New()
+	last := r.Generation()
+	seen := map[uint64]bool{last: true}
+	changed := func(what string) {
+		t.Helper()
+		g := r.Generation()
+		if seen[g] {
+			t.Fatalf("%s: generation %d repeated", what, g)
+		}
+		seen[g] = true
+		last = g
+	}
+	unchanged := func(what string) {
+		t.Helper()
+		if g := r.Generation(); g != last {
+			t.Fatalf("%s: generation changed to %d", what, g)
+		}
+	}
+
+	r.Insert("a", 1)
+	changed("insert")
+	r.Insert("ab", 1)
+	changed("insert")
+	r.Insert("a", 2)
+	changed("update")
+	r.Get("a")
+	r.Walk(func(k string, v interface{}) bool { return false })
+	r.Delete("missing")
+	unchanged("reads")
+	r.Delete("a")
+	changed("delete")
+	r.DeletePrefix("a")
+	changed("delete prefix")
+
+	r.Insert("x", 1)
+	r.Insert("y", 1)
+	changed("insert")
+	r.Partition([]string{"x"})
+	changed("partition")
+	r.Split("")
+	changed("split")
+}
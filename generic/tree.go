@@ -0,0 +1,460 @@
+// Package generic provides a radix tree whose keys and values have
+// static types, so values are stored and returned without boxing or
+// type assertions. Keys may be any string type, such as a named path
+// type. It carries the core of the radix.Tree API; the options of the
+// untyped tree, such as TTLs and persistence, are only available
+// there.
+package generic
+
+import (
+	"sort"
+	"strings"
+)
+
+// WalkFn is used when walking the tree. Takes a key and value,
+// returning if iteration should be terminated.
+type WalkFn[K ~string, V any] func(k K, v V) bool
+
+// leafNode is used to represent a value
+type leafNode[K ~string, V any] struct {
+	key K
+	val V
+}
+
+// edge is used to represent an edge node
+type edge[K ~string, V any] struct {
+	label byte
+	node  *node[K, V]
+}
+
+type node[K ~string, V any] struct {
+	// leaf is used to store possible leaf
+	leaf *leafNode[K, V]
+
+	// prefix is the common prefix we ignore
+	prefix string
+
+	// Edges should be stored in-order for iteration
+	edges []edge[K, V]
+}
+
+func (n *node[K, V]) edgeIndex(label byte) int {
+	return sort.Search(len(n.edges), func(i int) bool {
+		return n.edges[i].label >= label
+	})
+}
+
+func (n *node[K, V]) getEdge(label byte) *node[K, V] {
+	idx := n.edgeIndex(label)
+	if idx < len(n.edges) && n.edges[idx].label == label {
+		return n.edges[idx].node
+	}
+	return nil
+}
+
+func (n *node[K, V]) addEdge(e edge[K, V]) {
+	idx := n.edgeIndex(e.label)
+	n.edges = append(n.edges, edge[K, V]{})
+	copy(n.edges[idx+1:], n.edges[idx:])
+	n.edges[idx] = e
+}
+
+func (n *node[K, V]) updateEdge(label byte, child *node[K, V]) {
+	idx := n.edgeIndex(label)
+	if idx < len(n.edges) && n.edges[idx].label == label {
+		n.edges[idx].node = child
+		return
+	}
+	panic("replacing missing edge")
+}
+
+func (n *node[K, V]) delEdge(label byte) {
+	idx := n.edgeIndex(label)
+	if idx < len(n.edges) && n.edges[idx].label == label {
+		copy(n.edges[idx:], n.edges[idx+1:])
+		n.edges[len(n.edges)-1] = edge[K, V]{}
+		n.edges = n.edges[:len(n.edges)-1]
+	}
+}
+
+func (n *node[K, V]) mergeChild() {
+	child := n.edges[0].node
+	n.prefix = n.prefix + child.prefix
+	n.leaf = child.leaf
+	n.edges = child.edges
+}
+
+// Tree implements a radix tree with keys of type K and values of
+// type V. The zero value is not usable; create trees with New.
+type Tree[K ~string, V any] struct {
+	root *node[K, V]
+	size int
+}
+
+// New returns an empty Tree
+func New[K ~string, V any]() *Tree[K, V] {
+	return &Tree[K, V]{root: &node[K, V]{}}
+}
+
+// NewFromMap returns a new tree containing the keys
+// from an existing map
+func NewFromMap[K ~string, V any](m map[K]V) *Tree[K, V] {
+	t := New[K, V]()
+	for k, v := range m {
+		t.Insert(k, v)
+	}
+	return t
+}
+
+// Len is used to return the number of elements in the tree
+func (t *Tree[K, V]) Len() int {
+	return t.size
+}
+
+// longestPrefix finds the length of the shared prefix
+// of two strings
+func longestPrefix(k1, k2 string) int {
+	max := len(k1)
+	if l := len(k2); l < max {
+		max = l
+	}
+	var i int
+	for i = 0; i < max; i++ {
+		if k1[i] != k2[i] {
+			break
+		}
+	}
+	return i
+}
+
+// Insert is used to add a newentry or update
+// an existing entry. Returns true if an existing record is updated.
+func (t *Tree[K, V]) Insert(k K, v V) (V, bool) {
+	var zero V
+	var parent *node[K, V]
+	n := t.root
+	search := string(k)
+	for {
+		// Handle key exhaution
+		if len(search) == 0 {
+			if n.leaf != nil {
+				old := n.leaf.val
+				n.leaf.val = v
+				return old, true
+			}
+			n.leaf = &leafNode[K, V]{key: k, val: v}
+			t.size++
+			return zero, false
+		}
+
+		// Look for the edge
+		parent = n
+		n = n.getEdge(search[0])
+
+		// No edge, create one
+		if n == nil {
+			parent.addEdge(edge[K, V]{
+				label: search[0],
+				node: &node[K, V]{
+					leaf:   &leafNode[K, V]{key: k, val: v},
+					prefix: search,
+				},
+			})
+			t.size++
+			return zero, false
+		}
+
+		// Determine longest prefix of the search key on match
+		commonPrefix := longestPrefix(search, n.prefix)
+		if commonPrefix == len(n.prefix) {
+			search = search[commonPrefix:]
+			continue
+		}
+
+		// Split the node
+		t.size++
+		child := &node[K, V]{prefix: search[:commonPrefix]}
+		parent.updateEdge(search[0], child)
+
+		// Restore the existing node
+		child.addEdge(edge[K, V]{label: n.prefix[commonPrefix], node: n})
+		n.prefix = n.prefix[commonPrefix:]
+
+		// If the new key is a subset, add to this node
+		leaf := &leafNode[K, V]{key: k, val: v}
+		search = search[commonPrefix:]
+		if len(search) == 0 {
+			child.leaf = leaf
+			return zero, false
+		}
+
+		// Create a new edge for the node
+		child.addEdge(edge[K, V]{
+			label: search[0],
+			node:  &node[K, V]{leaf: leaf, prefix: search},
+		})
+		return zero, false
+	}
+}
+
+// Delete is used to delete a key, returning the previous
+// value and if it was deleted
+func (t *Tree[K, V]) Delete(k K) (V, bool) {
+	var zero V
+	var parent *node[K, V]
+	var label byte
+	n := t.root
+	search := string(k)
+	for len(search) > 0 {
+		// Look for an edge
+		parent = n
+		label = search[0]
+		n = n.getEdge(label)
+		if n == nil || !strings.HasPrefix(search, n.prefix) {
+			return zero, false
+		}
+
+		// Consume the search prefix
+		search = search[len(n.prefix):]
+	}
+	if n.leaf == nil {
+		return zero, false
+	}
+
+	// Delete the leaf
+	leaf := n.leaf
+	n.leaf = nil
+	t.size--
+
+	// Check if we should delete this node from the parent
+	if parent != nil && len(n.edges) == 0 {
+		parent.delEdge(label)
+	}
+
+	// Check if we should merge this node
+	if n != t.root && len(n.edges) == 1 {
+		n.mergeChild()
+	}
+
+	// Check if we should merge the parent's other child
+	if parent != nil && parent != t.root && len(parent.edges) == 1 && parent.leaf == nil {
+		parent.mergeChild()
+	}
+	return leaf.val, true
+}
+
+// DeletePrefix is used to delete the subtree under a prefix
+// Returns how many nodes were deleted
+// Use this to delete large subtrees efficiently
+func (t *Tree[K, V]) DeletePrefix(prefix K) int {
+	var parent *node[K, V]
+	n := t.root
+	search := string(prefix)
+	for len(search) > 0 {
+		// Look for an edge
+		parent = n
+		n = n.getEdge(search[0])
+		if n == nil {
+			return 0
+		}
+
+		// Consume the search prefix
+		if strings.HasPrefix(search, n.prefix) {
+			search = search[len(n.prefix):]
+			continue
+		}
+		if !strings.HasPrefix(n.prefix, search) {
+			return 0
+		}
+		break
+	}
+
+	// Count and detach the subtree
+	var deleted int
+	walk(n, func(K, V) bool {
+		deleted++
+		return false
+	})
+	if parent == nil {
+		t.root = &node[K, V]{}
+	} else {
+		parent.delEdge(n.prefix[0])
+		if parent != t.root && len(parent.edges) == 1 && parent.leaf == nil {
+			parent.mergeChild()
+		}
+	}
+	t.size -= deleted
+	return deleted
+}
+
+// Get is used to lookup a specific key, returning
+// the value and if it was found
+func (t *Tree[K, V]) Get(k K) (V, bool) {
+	n := t.root
+	search := string(k)
+	for len(search) > 0 {
+		// Look for an edge
+		n = n.getEdge(search[0])
+		if n == nil || !strings.HasPrefix(search, n.prefix) {
+			var zero V
+			return zero, false
+		}
+
+		// Consume the search prefix
+		search = search[len(n.prefix):]
+	}
+	if n.leaf == nil {
+		var zero V
+		return zero, false
+	}
+	return n.leaf.val, true
+}
+
+// LongestPrefix is like Get, but instead of an
+// exact match, it will return the longest prefix match.
+func (t *Tree[K, V]) LongestPrefix(s K) (K, V, bool) {
+	var last *leafNode[K, V]
+	n := t.root
+	search := string(s)
+	for {
+		// Look for a leaf node
+		if n.leaf != nil {
+			last = n.leaf
+		}
+
+		// Check for key exhaution
+		if len(search) == 0 {
+			break
+		}
+
+		// Look for an edge
+		n = n.getEdge(search[0])
+		if n == nil || !strings.HasPrefix(search, n.prefix) {
+			break
+		}
+
+		// Consume the search prefix
+		search = search[len(n.prefix):]
+	}
+	if last == nil {
+		var zero V
+		return "", zero, false
+	}
+	return last.key, last.val, true
+}
+
+// Minimum is used to return the minimum value in the tree
+func (t *Tree[K, V]) Minimum() (K, V, bool) {
+	n := t.root
+	for {
+		if n.leaf != nil {
+			return n.leaf.key, n.leaf.val, true
+		}
+		if len(n.edges) == 0 {
+			var zero V
+			return "", zero, false
+		}
+		n = n.edges[0].node
+	}
+}
+
+// Maximum is used to return the maximum value in the tree
+func (t *Tree[K, V]) Maximum() (K, V, bool) {
+	n := t.root
+	for {
+		if num := len(n.edges); num > 0 {
+			n = n.edges[num-1].node
+			continue
+		}
+		if n.leaf != nil {
+			return n.leaf.key, n.leaf.val, true
+		}
+		var zero V
+		return "", zero, false
+	}
+}
+
+// Walk is used to walk the tree
+func (t *Tree[K, V]) Walk(fn WalkFn[K, V]) {
+	walk(t.root, fn)
+}
+
+// WalkPrefix is used to walk the tree under a prefix
+func (t *Tree[K, V]) WalkPrefix(prefix K, fn WalkFn[K, V]) {
+	n := t.root
+	search := string(prefix)
+	for len(search) > 0 {
+		// Look for an edge
+		n = n.getEdge(search[0])
+		if n == nil {
+			return
+		}
+
+		// Consume the search prefix
+		if strings.HasPrefix(search, n.prefix) {
+			search = search[len(n.prefix):]
+			continue
+		}
+		if !strings.HasPrefix(n.prefix, search) {
+			return
+		}
+		break
+	}
+	walk(n, fn)
+}
+
+// WalkPath is used to walk the tree, but only visiting nodes
+// from the root down to a given leaf. Where WalkPrefix walks
+// all the entries *under* the given prefix, this walks the
+// entries *above* the given prefix.
+func (t *Tree[K, V]) WalkPath(path K, fn WalkFn[K, V]) {
+	n := t.root
+	search := string(path)
+	for {
+		// Visit the leaf values if any
+		if n.leaf != nil && fn(n.leaf.key, n.leaf.val) {
+			return
+		}
+
+		// Check for key exhaution
+		if len(search) == 0 {
+			return
+		}
+
+		// Look for an edge
+		n = n.getEdge(search[0])
+		if n == nil || !strings.HasPrefix(search, n.prefix) {
+			return
+		}
+
+		// Consume the search prefix
+		search = search[len(n.prefix):]
+	}
+}
+
+// walk is used to do a pre-order walk of a node
+// recursively. Returns true if the walk should be aborted
+func walk[K ~string, V any](n *node[K, V], fn WalkFn[K, V]) bool {
+	// Visit the leaf values if any
+	if n.leaf != nil && fn(n.leaf.key, n.leaf.val) {
+		return true
+	}
+
+	// Recurse on the children
+	for _, e := range n.edges {
+		if walk(e.node, fn) {
+			return true
+		}
+	}
+	return false
+}
+
+// ToMap is used to walk the tree and convert it into a map
+func (t *Tree[K, V]) ToMap() map[K]V {
+	out := make(map[K]V, t.size)
+	t.Walk(func(k K, v V) bool {
+		out[k] = v
+		return false
+	})
+	return out
+}
@@ -0,0 +1,128 @@
+package generic
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestTree(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	r := New[string, int]()
+	m := make(map[string]int)
+	for i := 0; i < 5000; i++ {
+		k := fmt.Sprintf("%03x", rng.Intn(2000))[:1+rng.Intn(3)]
+		if rng.Intn(3) == 0 {
+			old, ok := r.Delete(k)
+			want, wantOK := m[k]
+			if old != want || ok != wantOK {
+				t.Fatalf("delete %q: got %d %v", k, old, ok)
+			}
+			delete(m, k)
+			continue
+		}
+		old, updated := r.Insert(k, i)
+		if want, ok := m[k]; old != want || updated != ok {
+			t.Fatalf("insert %q: got %d %v", k, old, updated)
+		}
+		m[k] = i
+	}
+	if r.Len() != len(m) || !reflect.DeepEqual(r.ToMap(), m) {
+		t.Fatalf("bad: %d %d", r.Len(), len(m))
+	}
+	for k, v := range m {
+		if got, ok := r.Get(k); !ok || got != v {
+			t.Fatalf("get %q: got %d %v", k, got, ok)
+		}
+	}
+
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var walked []string
+	r.Walk(func(k string, v int) bool {
+		walked = append(walked, k)
+		return false
+	})
+	if !reflect.DeepEqual(walked, keys) {
+		t.Fatalf("bad walk order")
+	}
+	if k, _, _ := r.Minimum(); k != keys[0] {
+		t.Fatalf("bad: %q", k)
+	}
+	if k, _, _ := r.Maximum(); k != keys[len(keys)-1] {
+		t.Fatalf("bad: %q", k)
+	}
+}
+
+type path string
+
+func TestTree_Prefix(t *testing.T) {
+	r := NewFromMap(map[path]int{"": 0, "a": 1, "a/b": 2, "a/bc": 3, "a/c": 4, "b": 5})
+
+	if k, v, ok := r.LongestPrefix("a/x"); !ok || k != "a" || v != 1 {
+		t.Fatalf("bad: %q %d %v", k, v, ok)
+	}
+	var under []path
+	r.WalkPrefix("a/b", func(k path, v int) bool {
+		under = append(under, k)
+		return false
+	})
+	if !reflect.DeepEqual(under, []path{"a/b", "a/bc"}) {
+		t.Fatalf("bad: %v", under)
+	}
+	var above []path
+	r.WalkPath("a/bc/d", func(k path, v int) bool {
+		above = append(above, k)
+		return false
+	})
+	if !reflect.DeepEqual(above, []path{"", "a", "a/b", "a/bc"}) {
+		t.Fatalf("bad: %v", above)
+	}
+
+	if n := r.DeletePrefix("a/"); n != 3 || r.Len() != 3 {
+		t.Fatalf("bad: %d %v", n, r.ToMap())
+	}
+	if n := r.DeletePrefix("x"); n != 0 {
+		t.Fatalf("bad: %d", n)
+	}
+	if _, ok := r.Get("a/b"); ok {
+		t.Fatalf("expected a/b to be deleted")
+	}
+	if n := r.DeletePrefix(""); n != 3 || r.Len() != 0 {
+		t.Fatalf("bad: %d %v", n, r.ToMap())
+	}
+	r.Insert("z", 1)
+	if v, ok := r.Get("z"); !ok || v != 1 {
+		t.Fatalf("bad: %d %v", v, ok)
+	}
+}
+
+func TestTree_Unboxed(t *testing.T) {
+	r := New[string, error]()
+	r.Insert("b", nil)
+	r.Insert("a", errors.New("a"))
+	if v, ok := r.Get("b"); !ok || v != nil {
+		t.Fatalf("bad: %v %v", v, ok)
+	}
+	if v, ok := r.Get("missing"); ok || v != nil {
+		t.Fatalf("bad: %v %v", v, ok)
+	}
+
+	// Values that would need a heap allocation to be boxed are stored
+	// and returned as they are
+	n := New[string, int]()
+	n.Insert("k", 1000)
+	allocs := testing.AllocsPerRun(100, func() {
+		v, _ := n.Get("k")
+		n.Insert("k", v+1000)
+	})
+	if allocs != 0 {
+		t.Fatalf("bad: %v allocs", allocs)
+	}
+}
@@ -0,0 +1,186 @@
+package radix
+
+import "errors"
+
+// ErrBadPattern is returned for a malformed glob pattern
+var ErrBadPattern = errors.New("radix: malformed glob pattern")
+
+// globToken is one element of a compiled glob pattern
+type globToken struct {
+	star bool
+
+	// set holds the bytes matched by a token other than a star
+	set *[256]bool
+}
+
+// WalkGlob visits the entries whose keys match pattern, in key order,
+// until fn returns true. In the pattern, '*' matches any run of bytes,
+// '?' any single byte, and '[...]' any byte in the class, which may
+// hold ranges such as "a-z" and is negated by a leading '^' or '!'.
+// '\' quotes the byte after it. Unlike with path.Match, '*' and '?'
+// also match '/'. The pattern is matched a byte at a time down the
+// tree, so subtrees that can't match, such as those outside its
+// leading literal, are skipped. fn must not modify the tree. It
+// returns ErrBadPattern if the pattern is malformed, without visiting
+// anything.
+func (t *Tree) WalkGlob(pattern string, fn WalkFn) error {
+	toks, err := compileGlob(t.norm(pattern))
+	if err != nil {
+		return err
+	}
+	root := t.root
+	if t.smallMode {
+		root = t.buildNode("", 0, t.small)
+	}
+	state := make([]bool, len(toks)+1)
+	state[0] = true
+	globClose(toks, state)
+	globWalk(root, toks, state, fn)
+	return nil
+}
+
+// compileGlob parses pattern into tokens
+func compileGlob(pattern string) ([]globToken, error) {
+	var toks []globToken
+	for i := 0; i < len(pattern); i++ {
+		var set [256]bool
+		switch c := pattern[i]; c {
+		case '*':
+			if len(toks) == 0 || !toks[len(toks)-1].star {
+				toks = append(toks, globToken{star: true})
+			}
+			continue
+		case '?':
+			for b := range set {
+				set[b] = true
+			}
+		case '\\':
+			i++
+			if i == len(pattern) {
+				return nil, ErrBadPattern
+			}
+			set[pattern[i]] = true
+		case '[':
+			n, err := parseClass(pattern[i+1:], &set)
+			if err != nil {
+				return nil, err
+			}
+			i += n
+		default:
+			set[c] = true
+		}
+		toks = append(toks, globToken{set: &set})
+	}
+	return toks, nil
+}
+
+// parseClass fills set from the class at the start of s, which
+// follows the '[', and returns the length of the class up to and
+// including the ']'
+func parseClass(s string, set *[256]bool) (int, error) {
+	i := 0
+	negate := i < len(s) && (s[i] == '^' || s[i] == '!')
+	if negate {
+		i++
+	}
+	for {
+		if i == len(s) {
+			return 0, ErrBadPattern
+		}
+		if s[i] == ']' {
+			if i == 0 || (negate && i == 1) {
+				return 0, ErrBadPattern
+			}
+			break
+		}
+		lo, n, err := classByte(s[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n
+		hi := lo
+		if i+1 < len(s) && s[i] == '-' && s[i+1] != ']' {
+			if hi, n, err = classByte(s[i+1:]); err != nil {
+				return 0, err
+			}
+			i += 1 + n
+			if hi < lo {
+				return 0, ErrBadPattern
+			}
+		}
+		for b := int(lo); b <= int(hi); b++ {
+			set[b] = true
+		}
+	}
+	if negate {
+		for b := range set {
+			set[b] = !set[b]
+		}
+	}
+	return i + 1, nil
+}
+
+// classByte returns the possibly quoted byte at the start of s and
+// its length in s
+func classByte(s string) (byte, int, error) {
+	if s[0] != '\\' {
+		return s[0], 1, nil
+	}
+	if len(s) < 2 {
+		return 0, 0, ErrBadPattern
+	}
+	return s[1], 2, nil
+}
+
+// globClose adds to state the positions reached by matching a star
+// against nothing
+func globClose(toks []globToken, state []bool) {
+	for i, tok := range toks {
+		if state[i] && tok.star {
+			state[i+1] = true
+		}
+	}
+}
+
+// globStep returns the state after matching c, and whether any
+// position is left
+func globStep(toks []globToken, state []bool, c byte) ([]bool, bool) {
+	next := make([]bool, len(state))
+	alive := false
+	for i, tok := range toks {
+		if !state[i] {
+			continue
+		}
+		switch {
+		case tok.star:
+			next[i] = true
+			alive = true
+		case tok.set[c]:
+			next[i+1] = true
+			alive = true
+		}
+	}
+	globClose(toks, next)
+	return next, alive
+}
+
+// globWalk matches n's prefix against state, the positions reached by
+// the path above n, visiting the matches at and under n. It returns
+// true if fn stopped the walk.
+func globWalk(n *node, toks []globToken, state []bool, fn WalkFn) bool {
+	for i := 0; i < len(n.prefix); i++ {
+		var alive bool
+		if state, alive = globStep(toks, state, n.prefix[i]); !alive {
+			return false
+		}
+	}
+	if n.leaf != nil && state[len(toks)] && fn(n.leaf.key, n.leaf.val) {
+		return true
+	}
+	for _, e := range n.edges {
+		if globWalk(e.node, toks, state, fn) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package radix
+
+import (
+	"errors"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestWalkGlob(t *testing.T) {
+	keys := []string{"", "a", "ab", "abc", "abd", "b", "ba", "bab", "c", "c-", "c]", "x*y", "xay", "z^"}
+	patterns := []string{
+		"", "*", "a*", "*b", "a?", "?", "a?c", "*a*", "[ab]*", "[^ab]*", "[^a-b]",
+		"[a-c]b*", "x\\*y", "x*y", "c[\\]]", "c[\\-]", "[\\^]", "z[\\^]", "**", "a*d", "b*b",
+	}
+	for _, opts := range [][]Option{nil, {WithSmallTree(100)}} {
+		r := New(opts...)
+		for _, k := range keys {
+			r.Insert(k, nil)
+		}
+		for _, p := range patterns {
+			// path.Match agrees for keys without a '/'
+			var want []string
+			for _, k := range keys {
+				if ok, _ := path.Match(p, k); ok {
+					want = append(want, k)
+				}
+			}
+			var got []string
+			if err := r.WalkGlob(p, func(k string, v interface{}) bool {
+				got = append(got, k)
+				return false
+			}); err != nil {
+				t.Fatalf("%q: %v", p, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("%q: got %q, want %q", p, got, want)
+			}
+		}
+
+		// '!' negates as well as '^'
+		var neg []string
+		r.WalkGlob("[!a-b]", func(k string, v interface{}) bool {
+			neg = append(neg, k)
+			return false
+		})
+		if !reflect.DeepEqual(neg, []string{"c"}) {
+			t.Fatalf("bad: %q", neg)
+		}
+
+		// '*' crosses separators
+		r.Insert("a/b/c", nil)
+		var got []string
+		r.WalkGlob("a*c", func(k string, v interface{}) bool {
+			got = append(got, k)
+			return false
+		})
+		if !reflect.DeepEqual(got, []string{"a/b/c", "abc"}) {
+			t.Fatalf("bad: %q", got)
+		}
+	}
+
+	for _, p := range []string{"[", "[a", "[]", "a\\", "[z-a]", "[a-\\"} {
+		if err := New().WalkGlob(p, nil); !errors.Is(err, ErrBadPattern) {
+			t.Fatalf("%q: got %v", p, err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package radix
+
+// version is one state of a key in its history, which began at
+// revision rev and time at, in Unix nanoseconds
+type version struct {
+	rev     uint64
+	at      int64
+	val     interface{}
+	deleted bool
+}
+
+// record appends the state of key as of the current generation to
+// its history
+func (t *Tree) record(key string, val interface{}, deleted bool) {
+	if t.history == nil {
+		t.history = New()
+	}
+	var vs []version
+	if old, ok := t.history.get(key); ok {
+		vs = old.([]version)
+	}
+	now := t.now()
+	vs = append(vs, version{rev: t.generation, at: now, val: val, deleted: deleted})
+	t.history.insert(key, t.retain(vs, now), 0)
+}
+
+// retain drops the versions of a key that the retention limits no
+// longer cover
+func (t *Tree) retain(vs []version, now int64) []version {
+	drop := 0
+	if t.historyVersions > 0 && len(vs) > t.historyVersions {
+		drop = len(vs) - t.historyVersions
+	}
+	if t.historyAge > 0 {
+		// Keep the version in effect at the cutoff
+		cutoff := now - int64(t.historyAge)
+		for drop < len(vs)-1 && vs[drop+1].at <= cutoff {
+			drop++
+		}
+	}
+	if drop == 0 {
+		return vs
+	}
+	return append([]version(nil), vs[drop:]...)
+}
+
+// CompactHistory discards the history of the tree before revision
+// rev, keeping what is needed to read it as of rev and later, and
+// returns the number of versions discarded. Reads at earlier revisions
+// are no longer accurate. The history of keys deleted as of rev is
+// dropped altogether. The tree must have been created with
+// WithHistory.
+func (t *Tree) CompactHistory(rev uint64) int {
+	if !t.keepHistory {
+		panic("radix: CompactHistory requires WithHistory")
+	}
+	if t.history == nil {
+		return 0
+	}
+	var dropped int
+	t.history.WalkDelete(func(k string, v interface{}) (bool, bool) {
+		vs := v.([]version)
+		// Find the version in effect at rev
+		i := 0
+		for i < len(vs)-1 && vs[i+1].rev <= rev {
+			i++
+		}
+		if vs[i].rev > rev {
+			return false, false
+		}
+		if i == len(vs)-1 && vs[i].deleted {
+			dropped += len(vs)
+			return true, false
+		}
+		if i > 0 {
+			dropped += i
+			t.history.insert(k, append([]version(nil), vs[i:]...), 0)
+		}
+		return false, false
+	})
+	return dropped
+}
+
+// versionAt returns the state of a key at rev
+func versionAt(vs []version, rev uint64) (interface{}, bool) {
+	// Find the last version no later than rev
+	lo, hi := 0, len(vs)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if vs[mid].rev <= rev {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	if lo == 0 || vs[lo-1].deleted {
+		return nil, false
+	}
+	return vs[lo-1].val, true
+}
+
+// GetAt looks up key as it was at revision rev, a value returned by
+// Generation, returning the value and whether the key was present.
+// The tree must have been created with WithHistory.
+func (t *Tree) GetAt(key string, rev uint64) (interface{}, bool) {
+	if !t.keepHistory {
+		panic("radix: GetAt requires WithHistory")
+	}
+	key = t.norm(key)
+	if t.history == nil {
+		return nil, false
+	}
+	vs, ok := t.history.get(key)
+	if !ok {
+		return nil, false
+	}
+	return versionAt(vs.([]version), rev)
+}
+
+// WalkPrefixAt visits the entries under prefix as they were at
+// revision rev, in byte order, until fn returns true. The tree must
+// have been created with WithHistory.
+func (t *Tree) WalkPrefixAt(prefix string, rev uint64, fn WalkFn) {
+	if !t.keepHistory {
+		panic("radix: WalkPrefixAt requires WithHistory")
+	}
+	prefix = t.norm(prefix)
+	if t.history == nil {
+		return
+	}
+	t.history.WalkPrefix(prefix, func(k string, vs interface{}) bool {
+		if v, ok := versionAt(vs.([]version), rev); ok {
+			return fn(k, v)
+		}
+		return false
+	})
+}
@@ -0,0 +1,122 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	r := New(WithHistory())
+	r.Insert("foo", 1)
+	r.Insert("foobar", 2)
+	rev1 := r.Generation()
+	r.Insert("foo", 3)
+	r.Delete("foobar")
+	rev2 := r.Generation()
+	r.Insert("foobar", 4)
+
+	for _, c := range []struct {
+		key   string
+		rev   uint64
+		v     interface{}
+		found bool
+	}{
+		{"foo", 0, nil, false},
+		{"foo", rev1, 1, true},
+		{"foo", rev2, 3, true},
+		{"foobar", rev1, 2, true},
+		{"foobar", rev2, nil, false},
+		{"foobar", r.Generation(), 4, true},
+		{"nope", rev1, nil, false},
+	} {
+		if v, ok := r.GetAt(c.key, c.rev); v != c.v || ok != c.found {
+			t.Fatalf("%s@%d: got %v %v", c.key, c.rev, v, ok)
+		}
+	}
+
+	at := func(rev uint64) map[string]interface{} {
+		out := make(map[string]interface{})
+		r.WalkPrefixAt("foo", rev, func(k string, v interface{}) bool {
+			out[k] = v
+			return false
+		})
+		return out
+	}
+	if got := at(rev1); !reflect.DeepEqual(got, map[string]interface{}{"foo": 1, "foobar": 2}) {
+		t.Fatalf("bad: %v", got)
+	}
+	if got := at(rev2); !reflect.DeepEqual(got, map[string]interface{}{"foo": 3}) {
+		t.Fatalf("bad: %v", got)
+	}
+}
+
+func TestHistory_Unconfigured(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected a panic")
+		}
+	}()
+	New().GetAt("foo", 0)
+}
+
+func TestHistoryRetention(t *testing.T) {
+	r := New(WithHistoryRetention(2, 0))
+	for i := 0; i < 5; i++ {
+		r.Insert("foo", i)
+	}
+	if vs, _ := r.history.get("foo"); len(vs.([]version)) != 2 {
+		t.Fatalf("bad: %v", vs)
+	}
+	if v, _ := r.GetAt("foo", r.Generation()-1); v != 3 {
+		t.Fatalf("bad: %v", v)
+	}
+
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	r = New(WithClock(clock), WithHistoryRetention(0, time.Minute))
+	r.Insert("foo", 1)
+	clock.advance(time.Minute)
+	r.Insert("foo", 2)
+	rev := r.Generation()
+	clock.advance(time.Minute)
+	r.Insert("foo", 3)
+
+	// The version in effect a minute ago is kept
+	if vs, _ := r.history.get("foo"); len(vs.([]version)) != 2 {
+		t.Fatalf("bad: %v", vs)
+	}
+	if v, _ := r.GetAt("foo", rev); v != 2 {
+		t.Fatalf("bad: %v", v)
+	}
+}
+
+func TestCompactHistory(t *testing.T) {
+	r := New(WithHistory())
+	r.Insert("foo", 1)
+	r.Insert("bar", 1)
+	r.Insert("foo", 2)
+	r.Delete("bar")
+	rev := r.Generation()
+	r.Insert("foo", 3)
+	r.Insert("baz", 1)
+
+	// foo keeps 2 and 3, bar is gone and baz is untouched
+	if n := r.CompactHistory(rev); n != 3 {
+		t.Fatalf("bad: %d", n)
+	}
+	if v, _ := r.GetAt("foo", rev); v != 2 {
+		t.Fatalf("bad: %v", v)
+	}
+	if v, _ := r.GetAt("foo", r.Generation()); v != 3 {
+		t.Fatalf("bad: %v", v)
+	}
+	if _, ok := r.history.get("bar"); ok {
+		t.Fatalf("expected bar's history to be dropped")
+	}
+	if v, _ := r.GetAt("baz", r.Generation()); v != 1 {
+		t.Fatalf("bad: %v", v)
+	}
+	if n := r.CompactHistory(rev); n != 0 {
+		t.Fatalf("bad: %d", n)
+	}
+}
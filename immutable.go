@@ -0,0 +1,459 @@
+package radix
+
+import (
+	"sort"
+	"strings"
+)
+
+// Immutable is a radix tree that is never modified in place. Changes
+// are made through a Txn, whose Commit returns a new tree sharing all
+// unchanged nodes with the old one, so readers of any version never
+// need a lock and never see a change. It has none of the options of
+// Tree.
+type Immutable struct {
+	root *inode
+	size int
+}
+
+// ileaf is an entry of an Immutable
+type ileaf struct {
+	key string
+	val interface{}
+}
+
+// iedge leads to a child of an inode
+type iedge struct {
+	label byte
+	node  *inode
+}
+
+// inode is a node of an Immutable. Once reachable from a committed
+// tree it is never modified.
+type inode struct {
+	leaf   *ileaf
+	prefix string
+	edges  []iedge
+}
+
+// getEdge returns the index of the edge with label and its child,
+// or the insertion index and nil if there is none
+func (n *inode) getEdge(label byte) (int, *inode) {
+	idx := sort.Search(len(n.edges), func(i int) bool {
+		return n.edges[i].label >= label
+	})
+	if idx < len(n.edges) && n.edges[idx].label == label {
+		return idx, n.edges[idx].node
+	}
+	return idx, nil
+}
+
+// NewImmutable returns an empty Immutable
+func NewImmutable() *Immutable {
+	return &Immutable{root: &inode{}}
+}
+
+// Len returns the number of entries
+func (t *Immutable) Len() int {
+	return t.size
+}
+
+// Txn starts a transaction against t, which is left unchanged
+func (t *Immutable) Txn() *Txn {
+	return &Txn{root: t.root, size: t.size, writable: make(map[*inode]struct{})}
+}
+
+// Insert returns a tree with the entry added or updated, like
+// Tree.Insert, and the replaced value if any
+func (t *Immutable) Insert(k string, v interface{}) (*Immutable, interface{}, bool) {
+	txn := t.Txn()
+	old, updated := txn.Insert(k, v)
+	return txn.Commit(), old, updated
+}
+
+// Delete returns a tree without the entry for k, like Tree.Delete,
+// and the removed value if any
+func (t *Immutable) Delete(k string) (*Immutable, interface{}, bool) {
+	txn := t.Txn()
+	old, ok := txn.Delete(k)
+	return txn.Commit(), old, ok
+}
+
+// Get is used to lookup a specific key, returning
+// the value and if it was found
+func (t *Immutable) Get(k string) (interface{}, bool) {
+	return igetLeaf(t.root, k)
+}
+
+// igetLeaf looks k up under n
+func igetLeaf(n *inode, k string) (interface{}, bool) {
+	search := k
+	for len(search) > 0 {
+		_, n = n.getEdge(search[0])
+		if n == nil || !strings.HasPrefix(search, n.prefix) {
+			return nil, false
+		}
+		search = search[len(n.prefix):]
+	}
+	if n.leaf == nil {
+		return nil, false
+	}
+	return n.leaf.val, true
+}
+
+// LongestPrefix is like Get, but instead of an
+// exact match, it will return the longest prefix match.
+func (t *Immutable) LongestPrefix(s string) (string, interface{}, bool) {
+	var last *ileaf
+	n := t.root
+	search := s
+	for {
+		if n.leaf != nil {
+			last = n.leaf
+		}
+		if len(search) == 0 {
+			break
+		}
+		_, n = n.getEdge(search[0])
+		if n == nil || !strings.HasPrefix(search, n.prefix) {
+			break
+		}
+		search = search[len(n.prefix):]
+	}
+	if last == nil {
+		return "", nil, false
+	}
+	return last.key, last.val, true
+}
+
+// Minimum is used to return the minimum value in the tree
+func (t *Immutable) Minimum() (string, interface{}, bool) {
+	n := t.root
+	for {
+		if n.leaf != nil {
+			return n.leaf.key, n.leaf.val, true
+		}
+		if len(n.edges) == 0 {
+			return "", nil, false
+		}
+		n = n.edges[0].node
+	}
+}
+
+// Maximum is used to return the maximum value in the tree
+func (t *Immutable) Maximum() (string, interface{}, bool) {
+	n := t.root
+	for {
+		if num := len(n.edges); num > 0 {
+			n = n.edges[num-1].node
+			continue
+		}
+		if n.leaf != nil {
+			return n.leaf.key, n.leaf.val, true
+		}
+		return "", nil, false
+	}
+}
+
+// Walk is used to walk the tree
+func (t *Immutable) Walk(fn WalkFn) {
+	iwalk(t.root, fn)
+}
+
+// WalkPrefix is used to walk the tree under a prefix
+func (t *Immutable) WalkPrefix(prefix string, fn WalkFn) {
+	n := t.root
+	search := prefix
+	for len(search) > 0 {
+		_, n = n.getEdge(search[0])
+		if n == nil {
+			return
+		}
+		if strings.HasPrefix(search, n.prefix) {
+			search = search[len(n.prefix):]
+			continue
+		}
+		if !strings.HasPrefix(n.prefix, search) {
+			return
+		}
+		break
+	}
+	iwalk(n, fn)
+}
+
+// WalkPath is used to walk the tree, but only visiting nodes
+// from the root down to a given leaf.
+func (t *Immutable) WalkPath(path string, fn WalkFn) {
+	n := t.root
+	search := path
+	for {
+		if n.leaf != nil && fn(n.leaf.key, n.leaf.val) {
+			return
+		}
+		if len(search) == 0 {
+			return
+		}
+		_, n = n.getEdge(search[0])
+		if n == nil || !strings.HasPrefix(search, n.prefix) {
+			return
+		}
+		search = search[len(n.prefix):]
+	}
+}
+
+// iwalk does a pre-order walk of n, returning true if aborted
+func iwalk(n *inode, fn WalkFn) bool {
+	if n.leaf != nil && fn(n.leaf.key, n.leaf.val) {
+		return true
+	}
+	for _, e := range n.edges {
+		if iwalk(e.node, fn) {
+			return true
+		}
+	}
+	return false
+}
+
+// ToMap is used to walk the tree and convert it into a map
+func (t *Immutable) ToMap() map[string]interface{} {
+	out := make(map[string]interface{}, t.size)
+	t.Walk(func(k string, v interface{}) bool {
+		out[k] = v
+		return false
+	})
+	return out
+}
+
+// Txn batches changes to an Immutable. Nodes are copied the first
+// time the transaction changes them and modified in place after
+// that, so a batch costs little more than its changes would on a
+// Tree. A Txn is not safe for concurrent use, but the tree it started
+// from and those it committed may be read concurrently with it.
+type Txn struct {
+	root *inode
+	size int
+
+	// writable holds the nodes created by this transaction since
+	// its last commit, which no reader can see yet
+	writable map[*inode]struct{}
+}
+
+// writeNode returns a copy of n that the transaction may modify, or
+// n itself if it already may
+func (t *Txn) writeNode(n *inode) *inode {
+	if _, ok := t.writable[n]; ok {
+		return n
+	}
+	nc := &inode{leaf: n.leaf, prefix: n.prefix}
+	if len(n.edges) > 0 {
+		nc.edges = make([]iedge, len(n.edges))
+		copy(nc.edges, n.edges)
+	}
+	t.writable[nc] = struct{}{}
+	return nc
+}
+
+// newNode returns a new node the transaction may modify
+func (t *Txn) newNode(prefix string, leaf *ileaf) *inode {
+	n := &inode{prefix: prefix, leaf: leaf}
+	t.writable[n] = struct{}{}
+	return n
+}
+
+// mergeChild folds the only child of n, which must be writable, into n
+func (t *Txn) mergeChild(n *inode) {
+	child := n.edges[0].node
+	n.prefix = n.prefix + child.prefix
+	n.leaf = child.leaf
+	n.edges = nil
+	if len(child.edges) > 0 {
+		n.edges = make([]iedge, len(child.edges))
+		copy(n.edges, child.edges)
+	}
+}
+
+// Len returns the number of entries as of the changes so far
+func (t *Txn) Len() int {
+	return t.size
+}
+
+// Get looks up a key, seeing the changes made so far
+func (t *Txn) Get(k string) (interface{}, bool) {
+	return igetLeaf(t.root, k)
+}
+
+// Insert adds or updates an entry, returning the replaced value if
+// any
+func (t *Txn) Insert(k string, v interface{}) (interface{}, bool) {
+	root, old, updated := t.insert(t.root, k, k, v)
+	t.root = root
+	if !updated {
+		t.size++
+	}
+	return old, updated
+}
+
+// insert adds the entry under n, whose remaining key is search, and
+// returns n's replacement
+func (t *Txn) insert(n *inode, k, search string, v interface{}) (*inode, interface{}, bool) {
+	if len(search) == 0 {
+		var old interface{}
+		updated := n.leaf != nil
+		if updated {
+			old = n.leaf.val
+		}
+		nc := t.writeNode(n)
+		nc.leaf = &ileaf{key: k, val: v}
+		return nc, old, updated
+	}
+
+	idx, child := n.getEdge(search[0])
+
+	// No edge, create one
+	if child == nil {
+		nc := t.writeNode(n)
+		nc.edges = append(nc.edges, iedge{})
+		copy(nc.edges[idx+1:], nc.edges[idx:])
+		nc.edges[idx] = iedge{label: search[0], node: t.newNode(search, &ileaf{key: k, val: v})}
+		return nc, nil, false
+	}
+
+	// Descend if the child's prefix is consumed
+	common := longestPrefix(search, child.prefix)
+	if common == len(child.prefix) {
+		newChild, old, updated := t.insert(child, k, search[common:], v)
+		nc := t.writeNode(n)
+		nc.edges[idx].node = newChild
+		return nc, old, updated
+	}
+
+	// Split the child
+	nc := t.writeNode(n)
+	split := t.newNode(search[:common], nil)
+	nc.edges[idx].node = split
+	rest := t.writeNode(child)
+	rest.prefix = child.prefix[common:]
+	split.edges = []iedge{{label: rest.prefix[0], node: rest}}
+
+	leaf := &ileaf{key: k, val: v}
+	search = search[common:]
+	if len(search) == 0 {
+		split.leaf = leaf
+		return nc, nil, false
+	}
+	e := iedge{label: search[0], node: t.newNode(search, leaf)}
+	if e.label < rest.prefix[0] {
+		split.edges = []iedge{e, split.edges[0]}
+	} else {
+		split.edges = append(split.edges, e)
+	}
+	return nc, nil, false
+}
+
+// Delete removes an entry, returning its value if it was present
+func (t *Txn) Delete(k string) (interface{}, bool) {
+	root, leaf := t.delete(true, t.root, k)
+	if leaf == nil {
+		return nil, false
+	}
+	t.root = root
+	t.size--
+	return leaf.val, true
+}
+
+// delete removes the entry under n, whose remaining key is search,
+// returning n's replacement and the removed leaf, or a nil leaf if
+// there was nothing to remove
+func (t *Txn) delete(isRoot bool, n *inode, search string) (*inode, *ileaf) {
+	if len(search) == 0 {
+		leaf := n.leaf
+		if leaf == nil {
+			return nil, nil
+		}
+		nc := t.writeNode(n)
+		nc.leaf = nil
+		if !isRoot && len(nc.edges) == 1 {
+			t.mergeChild(nc)
+		}
+		return nc, leaf
+	}
+
+	idx, child := n.getEdge(search[0])
+	if child == nil || !strings.HasPrefix(search, child.prefix) {
+		return nil, nil
+	}
+	newChild, leaf := t.delete(false, child, search[len(child.prefix):])
+	if leaf == nil {
+		return nil, nil
+	}
+	return t.replaceChild(isRoot, n, idx, newChild), leaf
+}
+
+// replaceChild returns a replacement for n with the child at idx
+// replaced by newChild, which is dropped if empty
+func (t *Txn) replaceChild(isRoot bool, n *inode, idx int, newChild *inode) *inode {
+	nc := t.writeNode(n)
+	if newChild.leaf != nil || len(newChild.edges) > 0 {
+		nc.edges[idx].node = newChild
+		return nc
+	}
+	copy(nc.edges[idx:], nc.edges[idx+1:])
+	nc.edges[len(nc.edges)-1] = iedge{}
+	nc.edges = nc.edges[:len(nc.edges)-1]
+	if !isRoot && len(nc.edges) == 1 && nc.leaf == nil {
+		t.mergeChild(nc)
+	}
+	return nc
+}
+
+// DeletePrefix removes every entry whose key starts with prefix and
+// returns how many there were
+func (t *Txn) DeletePrefix(prefix string) int {
+	root, n := t.deletePrefix(true, t.root, prefix)
+	if n == 0 {
+		return 0
+	}
+	t.root = root
+	t.size -= n
+	return n
+}
+
+// deletePrefix removes the entries under n starting with search,
+// returning n's replacement and the number removed
+func (t *Txn) deletePrefix(isRoot bool, n *inode, search string) (*inode, int) {
+	if len(search) == 0 {
+		var count int
+		iwalk(n, func(string, interface{}) bool {
+			count++
+			return false
+		})
+		if count == 0 {
+			return nil, 0
+		}
+		nc := t.writeNode(n)
+		nc.leaf, nc.edges = nil, nil
+		return nc, count
+	}
+
+	idx, child := n.getEdge(search[0])
+	if child == nil || !(strings.HasPrefix(child.prefix, search) || strings.HasPrefix(search, child.prefix)) {
+		return nil, 0
+	}
+	if len(child.prefix) >= len(search) {
+		search = ""
+	} else {
+		search = search[len(child.prefix):]
+	}
+	newChild, count := t.deletePrefix(false, child, search)
+	if count == 0 {
+		return nil, 0
+	}
+	return t.replaceChild(isRoot, n, idx, newChild), count
+}
+
+// Commit returns the tree holding the transaction's changes. The
+// transaction may carry on afterwards, without affecting the
+// committed tree.
+func (t *Txn) Commit() *Immutable {
+	t.writable = make(map[*inode]struct{})
+	return &Immutable{root: t.root, size: t.size}
+}
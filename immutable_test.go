@@ -0,0 +1,133 @@
+package radix
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// checkImmutable checks the structure of an Immutable: edges sorted
+// and matching their children, and no node that could be merged
+func checkImmutable(t *testing.T, n *inode, isRoot bool) int {
+	t.Helper()
+	if !isRoot && n.leaf == nil && len(n.edges) < 2 {
+		t.Fatalf("node %q should have been merged", n.prefix)
+	}
+	count := 0
+	if n.leaf != nil {
+		count++
+	}
+	for i, e := range n.edges {
+		if e.node.prefix == "" || e.node.prefix[0] != e.label || (i > 0 && n.edges[i-1].label >= e.label) {
+			t.Fatalf("bad edge %q under %q", e.label, n.prefix)
+		}
+		count += checkImmutable(t, e.node, false)
+	}
+	return count
+}
+
+func TestImmutable(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	r := NewImmutable()
+	m := make(map[string]interface{})
+	var versions []*Immutable
+	var maps []map[string]interface{}
+	for round := 0; round < 50; round++ {
+		txn := r.Txn()
+		for i := 0; i < 40; i++ {
+			k := fmt.Sprintf("%03x", rng.Intn(1000))[:1+rng.Intn(3)]
+			switch rng.Intn(8) {
+			case 0:
+				n := txn.DeletePrefix(k[:1])
+				var want int
+				for mk := range m {
+					if mk[0] == k[0] {
+						delete(m, mk)
+						want++
+					}
+				}
+				if n != want {
+					t.Fatalf("delete prefix %q: got %d, want %d", k[:1], n, want)
+				}
+			case 1, 2:
+				old, ok := txn.Delete(k)
+				want, wantOK := m[k]
+				if old != want || ok != wantOK {
+					t.Fatalf("delete %q: got %v %v", k, old, ok)
+				}
+				delete(m, k)
+			default:
+				old, updated := txn.Insert(k, i)
+				if want, ok := m[k]; old != want || updated != ok {
+					t.Fatalf("insert %q: got %v %v", k, old, updated)
+				}
+				m[k] = i
+			}
+			if v, ok := txn.Get(k); v != m[k] || ok != (m[k] != nil) {
+				t.Fatalf("get %q: got %v %v", k, v, ok)
+			}
+		}
+		r = txn.Commit()
+
+		// Carrying on with the transaction leaves the commit alone
+		txn.Insert("zzz", 1)
+		txn.DeletePrefix("")
+
+		snap := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			snap[k] = v
+		}
+		versions = append(versions, r)
+		maps = append(maps, snap)
+	}
+
+	// Every version still holds what it held when committed
+	for i, v := range versions {
+		if v.Len() != len(maps[i]) || !reflect.DeepEqual(v.ToMap(), maps[i]) {
+			t.Fatalf("version %d changed", i)
+		}
+		if n := checkImmutable(t, v.root, true); n != v.Len() {
+			t.Fatalf("version %d: %d leaves for %d entries", i, n, v.Len())
+		}
+	}
+}
+
+func TestImmutable_Reads(t *testing.T) {
+	r := NewImmutable()
+	r, _, _ = r.Insert("", 0)
+	r, _, _ = r.Insert("a", 1)
+	r, _, _ = r.Insert("a/b", 2)
+	old, _, _ := r.Insert("a/c", 3)
+	r, _, ok := old.Delete("a")
+	if !ok || r.Len() != 3 || old.Len() != 4 {
+		t.Fatalf("bad: %d %d", r.Len(), old.Len())
+	}
+	if _, ok := old.Get("a"); !ok {
+		t.Fatalf("expected the old version to keep a")
+	}
+	if k, v, _ := r.LongestPrefix("a/bx"); k != "a/b" || v != 2 {
+		t.Fatalf("bad: %q %v", k, v)
+	}
+	if k, _, _ := r.LongestPrefix("a/x"); k != "" {
+		t.Fatalf("bad: %q", k)
+	}
+	if k, _, _ := r.Minimum(); k != "" {
+		t.Fatalf("bad: %q", k)
+	}
+	if k, _, _ := r.Maximum(); k != "a/c" {
+		t.Fatalf("bad: %q", k)
+	}
+	var under, above []string
+	old.WalkPrefix("a/", func(k string, v interface{}) bool {
+		under = append(under, k)
+		return false
+	})
+	old.WalkPath("a/b/c", func(k string, v interface{}) bool {
+		above = append(above, k)
+		return false
+	})
+	if !reflect.DeepEqual(under, []string{"a/b", "a/c"}) || !reflect.DeepEqual(above, []string{"", "a", "a/b"}) {
+		t.Fatalf("bad: %v %v", under, above)
+	}
+}
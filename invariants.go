@@ -0,0 +1,68 @@
+package radix
+
+import "fmt"
+
+// validate performs cheap, local sanity checks on a node: edges
+// must be strictly sorted by label, every label must match the
+// first byte of the child's prefix and any dense table or bitmap must
+// agree with the edges. It does not recurse.
+func (n *node) validate() error {
+	var x nodeExt
+	if n.ext != nil {
+		x = *n.ext
+	}
+	for i, e := range n.edges {
+		if e.node == nil {
+			return fmt.Errorf("edge %d (label %q) has a nil node", i, e.label)
+		}
+		if len(e.node.prefix) == 0 {
+			return fmt.Errorf("edge %d (label %q) leads to a node with an empty prefix", i, e.label)
+		}
+		if e.node.prefix[0] != e.label {
+			return fmt.Errorf("edge %d label %q does not match child prefix %q", i, e.label, e.node.prefix)
+		}
+		if i > 0 && !x.collation.less(n.edges[i-1].label, e.label) {
+			return fmt.Errorf("edges out of order at %d: %q before %q", i, n.edges[i-1].label, e.label)
+		}
+		if x.dense != nil && x.dense[e.label] != e.node {
+			return fmt.Errorf("dense table entry for label %q does not match edge %d", e.label, i)
+		}
+	}
+	if x.bitmap != nil {
+		for i, e := range n.edges {
+			if !x.bitmap.has(n.pos(e.label)) {
+				return fmt.Errorf("bitmap lacks the label %q of edge %d", e.label, i)
+			}
+		}
+		if count := x.bitmap.count(); count != len(n.edges) {
+			return fmt.Errorf("bitmap has %d entries for %d edges", count, len(n.edges))
+		}
+	}
+	if x.dense != nil {
+		count := 0
+		for _, child := range x.dense {
+			if child != nil {
+				count++
+			}
+		}
+		if count != len(n.edges) {
+			return fmt.Errorf("dense table has %d entries for %d edges", count, len(n.edges))
+		}
+	}
+	return nil
+}
+
+// assertValid panics with the operation and key that caused the
+// corruption if any of the given nodes fail validation. It is only
+// called when the radixdebug build tag is set.
+func assertValid(op, key string, nodes ...*node) {
+	for _, n := range nodes {
+		if n == nil {
+			continue
+		}
+		if err := n.validate(); err != nil {
+			panic(fmt.Sprintf("radix: invariant violated after %s(%q) at node with prefix %q (%d edges, leaf=%v): %v",
+				op, key, n.prefix, len(n.edges), n.isLeaf(), err))
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package radix
+
+import "net/netip"
+
+// IPTree maps IP prefixes, such as 10.0.0.0/8, to values, for route
+// table style lookups. Each prefix is stored bit by bit, so a
+// prefix's key is a prefix of the keys of the prefixes it contains,
+// whatever its length. IPv4 and IPv6 prefixes are kept apart; an
+// IPv4-mapped IPv6 address is looked up as IPv6.
+type IPTree struct {
+	t *Tree
+}
+
+// IPWalkFn is used when walking an IPTree. Returning true stops
+// the walk.
+type IPWalkFn func(p netip.Prefix, v interface{}) bool
+
+// NewIPTree returns an empty IPTree
+func NewIPTree() *IPTree {
+	return &IPTree{t: New()}
+}
+
+// ipKey returns the key of p, which must be valid: a byte for the
+// family, followed by a '0' or '1' for each bit of the masked prefix
+func ipKey(p netip.Prefix) string {
+	if !p.IsValid() {
+		panic("radix: invalid IP prefix")
+	}
+	return ipBits(p.Addr(), p.Bits())
+}
+
+// ipBits returns the key of the first n bits of addr
+func ipBits(addr netip.Addr, n int) string {
+	b := make([]byte, 1, 1+n)
+	b[0] = '6'
+	if addr.Is4() {
+		b[0] = '4'
+	}
+	raw := addr.AsSlice()
+	for i := 0; i < n; i++ {
+		b = append(b, '0'+raw[i/8]>>(7-i%8)&1)
+	}
+	return string(b)
+}
+
+// ipPrefix returns the prefix whose key is k
+func ipPrefix(k string) netip.Prefix {
+	raw := make([]byte, 16)
+	if k[0] == '4' {
+		raw = raw[:4]
+	}
+	bits := k[1:]
+	for i := 0; i < len(bits); i++ {
+		raw[i/8] |= (bits[i] - '0') << (7 - i%8)
+	}
+	addr, _ := netip.AddrFromSlice(raw)
+	return netip.PrefixFrom(addr, len(bits))
+}
+
+// ipWalk adapts fn to the keys of the underlying tree
+func ipWalk(fn IPWalkFn) WalkFn {
+	return func(k string, v interface{}) bool {
+		return fn(ipPrefix(k), v)
+	}
+}
+
+// Tree returns the underlying tree
+func (t *IPTree) Tree() *Tree {
+	return t.t
+}
+
+// Len returns the number of prefixes in the tree
+func (t *IPTree) Len() int {
+	return t.t.Len()
+}
+
+// Insert adds or updates the value of p, ignoring any bits of its
+// address past its length, and returns the previous value if any. It
+// panics if p is not valid.
+func (t *IPTree) Insert(p netip.Prefix, v interface{}) (interface{}, bool) {
+	return t.t.Insert(ipKey(p), v)
+}
+
+// Delete removes p, returning its value if it was present
+func (t *IPTree) Delete(p netip.Prefix) (interface{}, bool) {
+	return t.t.Delete(ipKey(p))
+}
+
+// Get returns the value of p exactly
+func (t *IPTree) Get(p netip.Prefix) (interface{}, bool) {
+	return t.t.Get(ipKey(p))
+}
+
+// LongestPrefixMatch returns the most specific prefix containing addr,
+// as a router picks a route
+func (t *IPTree) LongestPrefixMatch(addr netip.Addr) (netip.Prefix, interface{}, bool) {
+	if !addr.IsValid() {
+		return netip.Prefix{}, nil, false
+	}
+	k, v, ok := t.t.LongestPrefix(ipBits(addr, addr.BitLen()))
+	if !ok {
+		return netip.Prefix{}, nil, false
+	}
+	return ipPrefix(k), v, true
+}
+
+// Walk visits every prefix, IPv4 first, each before the prefixes it
+// contains
+func (t *IPTree) Walk(fn IPWalkFn) {
+	t.t.Walk(ipWalk(fn))
+}
+
+// WalkCovered visits the prefixes contained in p, p included, each
+// before the prefixes it contains
+func (t *IPTree) WalkCovered(p netip.Prefix, fn IPWalkFn) {
+	t.t.WalkPrefix(ipKey(p), ipWalk(fn))
+}
+
+// WalkCovering visits the prefixes that contain p, p included, from
+// the least specific
+func (t *IPTree) WalkCovering(p netip.Prefix, fn IPWalkFn) {
+	t.t.WalkPath(ipKey(p), ipWalk(fn))
+}
@@ -0,0 +1,76 @@
+package radix
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestIPTree(t *testing.T) {
+	r := NewIPTree()
+	for _, s := range []string{"0.0.0.0/0", "10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24", "192.168.0.0/16", "2001:db8::/32", "::/0"} {
+		r.Insert(netip.MustParsePrefix(s), s)
+	}
+	if r.Len() != 7 {
+		t.Fatalf("bad: %d", r.Len())
+	}
+
+	// Bits past the length are ignored
+	if _, ok := r.Insert(netip.MustParsePrefix("10.1.2.3/24"), "10.1.2.0/24"); !ok {
+		t.Fatalf("expected an update")
+	}
+
+	for addr, want := range map[string]string{
+		"10.1.2.3":        "10.1.2.0/24",
+		"10.1.3.1":        "10.1.0.0/16",
+		"10.200.0.1":      "10.0.0.0/8",
+		"8.8.8.8":         "0.0.0.0/0",
+		"192.168.1.1":     "192.168.0.0/16",
+		"2001:db8::1":     "2001:db8::/32",
+		"2001:db9::1":     "::/0",
+		"::ffff:10.1.2.3": "::/0",
+	} {
+		p, v, ok := r.LongestPrefixMatch(netip.MustParseAddr(addr))
+		if !ok || p.String() != want || v != want {
+			t.Fatalf("%s: got %v %v %v, want %s", addr, p, v, ok, want)
+		}
+	}
+	if _, _, ok := r.LongestPrefixMatch(netip.Addr{}); ok {
+		t.Fatalf("matched the zero address")
+	}
+
+	collect := func(walk func(netip.Prefix, IPWalkFn), p string) []string {
+		var out []string
+		walk(netip.MustParsePrefix(p), func(p netip.Prefix, v interface{}) bool {
+			out = append(out, p.String())
+			return false
+		})
+		return out
+	}
+	if got := collect(r.WalkCovered, "10.0.0.0/8"); !reflect.DeepEqual(got, []string{"10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24"}) {
+		t.Fatalf("bad: %v", got)
+	}
+	if got := collect(r.WalkCovering, "10.1.2.128/25"); !reflect.DeepEqual(got, []string{"0.0.0.0/0", "10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24"}) {
+		t.Fatalf("bad: %v", got)
+	}
+
+	var all []string
+	r.Walk(func(p netip.Prefix, v interface{}) bool {
+		all = append(all, p.String())
+		return false
+	})
+	want := []string{"0.0.0.0/0", "10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24", "192.168.0.0/16", "::/0", "2001:db8::/32"}
+	if !reflect.DeepEqual(all, want) {
+		t.Fatalf("bad: %v", all)
+	}
+
+	if _, ok := r.Delete(netip.MustParsePrefix("10.1.0.0/16")); !ok {
+		t.Fatalf("expected a delete")
+	}
+	if p, _, _ := r.LongestPrefixMatch(netip.MustParseAddr("10.1.3.1")); p.String() != "10.0.0.0/8" {
+		t.Fatalf("bad: %v", p)
+	}
+	if _, ok := r.Get(netip.MustParsePrefix("10.1.2.0/24")); !ok {
+		t.Fatalf("missing 10.1.2.0/24")
+	}
+}
@@ -0,0 +1,84 @@
+package radix
+
+import "strings"
+
+// Iterator pulls the entries of a tree one at a time, in key order,
+// so scans can be paginated or stopped without a callback. It
+// ignores WithNaturalOrder. The tree may be modified between calls to
+// Next: the iterator then carries on from the first key after the one
+// it returned last.
+type Iterator struct {
+	t *Tree
+
+	// next is the leaf Next returns, valid while mods matches the
+	// tree's
+	next *leafNode
+	mods uint64
+
+	// pos is where the iterator stands: next is the first key after
+	// pos, or at or after it if inclusive
+	pos       string
+	inclusive bool
+
+	// prefix bounds the keys returned, as does hi if bounded
+	prefix  string
+	hi      string
+	bounded bool
+}
+
+// Iterator returns an iterator positioned at the start of the tree
+func (t *Tree) Iterator() *Iterator {
+	it := &Iterator{t: t}
+	it.SeekLowerBound("")
+	return it
+}
+
+// SeekPrefix positions the iterator at the first key starting with
+// prefix, and limits it to those keys
+func (it *Iterator) SeekPrefix(prefix string) {
+	prefix = it.t.norm(prefix)
+	it.seek(prefix, true)
+	it.prefix, it.bounded = prefix, false
+}
+
+// SeekLowerBound positions the iterator at the first key at or after
+// key, with no limit
+func (it *Iterator) SeekLowerBound(key string) {
+	it.seek(it.t.norm(key), true)
+	it.prefix, it.bounded = "", false
+}
+
+// SeekRange positions the iterator at the first key at or after lo,
+// and limits it to the keys before hi
+func (it *Iterator) SeekRange(lo, hi string) {
+	it.seek(it.t.norm(lo), true)
+	it.prefix, it.hi, it.bounded = "", it.t.norm(hi), true
+}
+
+// seek positions the iterator at pos
+func (it *Iterator) seek(pos string, inclusive bool) {
+	it.pos, it.inclusive = pos, inclusive
+	exact, _, succ := it.t.seek(pos)
+	if inclusive && exact != nil {
+		succ = exact
+	}
+	it.next, it.mods = succ, it.t.mods
+}
+
+// Next returns the next entry, or false once there are none left
+func (it *Iterator) Next() (string, interface{}, bool) {
+	if it.mods != it.t.mods {
+		// The tree changed, so next may be gone
+		it.seek(it.pos, it.inclusive)
+	}
+	l := it.next
+	if l == nil || !strings.HasPrefix(l.key, it.prefix) {
+		return "", nil, false
+	}
+	if it.bounded && it.t.collation.Compare(l.key, it.hi) >= 0 {
+		return "", nil, false
+	}
+	it.next = l.next
+	it.pos, it.inclusive = l.key, false
+	return l.key, l.val, true
+}
@@ -0,0 +1,60 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+)
+
+// drain returns the keys left in it
+func drain(it *Iterator) []string {
+	var out []string
+	for {
+		k, _, ok := it.Next()
+		if !ok {
+			return out
+		}
+		out = append(out, k)
+	}
+}
+
+func TestIterator(t *testing.T) {
+	if _, _, ok := New().Iterator().Next(); ok {
+		t.Fatalf("expected an empty tree to have no entries")
+	}
+	for _, opts := range [][]Option{nil, {WithSmallTree(100)}} {
+		r := New(opts...)
+		for _, k := range []string{"a", "ab", "abc", "b", "ba", "c"} {
+			r.Insert(k, nil)
+		}
+		it := r.Iterator()
+		if got := drain(it); !reflect.DeepEqual(got, []string{"a", "ab", "abc", "b", "ba", "c"}) {
+			t.Fatalf("bad: %v", got)
+		}
+		it.SeekPrefix("ab")
+		if got := drain(it); !reflect.DeepEqual(got, []string{"ab", "abc"}) {
+			t.Fatalf("bad: %v", got)
+		}
+		it.SeekPrefix("bb")
+		if got := drain(it); got != nil {
+			t.Fatalf("bad: %v", got)
+		}
+		it.SeekLowerBound("abd")
+		if got := drain(it); !reflect.DeepEqual(got, []string{"b", "ba", "c"}) {
+			t.Fatalf("bad: %v", got)
+		}
+
+		// Changes between calls are picked up
+		it = r.Iterator()
+		it.Next()
+		r.Delete("ab")
+		r.Insert("aa", nil)
+		r.Insert("0", nil)
+		if k, _, _ := it.Next(); k != "aa" {
+			t.Fatalf("bad: %q", k)
+		}
+		r.DeletePrefix("a")
+		if got := drain(it); !reflect.DeepEqual(got, []string{"b", "ba", "c"}) {
+			t.Fatalf("bad: %v", got)
+		}
+	}
+}
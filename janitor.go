@@ -0,0 +1,65 @@
+package radix
+
+import (
+	"sync"
+	"time"
+)
+
+// janitor periodically evicts expired entries in the background
+type janitor struct {
+	interval time.Duration
+	batch    int
+	maxTime  time.Duration
+	lock     sync.Locker
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// start runs the janitor for t until stopped
+func (j *janitor) start(t *Tree) {
+	j.stop = make(chan struct{})
+	j.done = make(chan struct{})
+	go j.run(t)
+}
+
+// inheritJanitor starts a janitor for t, a tree derived from another,
+// with the settings of that tree's janitor j, if any
+func (t *Tree) inheritJanitor(j *janitor) {
+	if j == nil {
+		return
+	}
+	t.janitor = &janitor{
+		interval: j.interval,
+		batch:    j.batch,
+		maxTime:  j.maxTime,
+		lock:     j.lock,
+	}
+	t.janitor.start(t)
+}
+
+// run sweeps t every interval
+func (j *janitor) run(t *Tree) {
+	defer close(j.done)
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-j.stop:
+			return
+		case <-ticker.C:
+			j.lock.Lock()
+			t.evictExpired(j.batch, j.maxTime)
+			j.lock.Unlock()
+		}
+	}
+}
+
+// close stops the janitor and waits for a sweep in progress
+func (j *janitor) close() {
+	j.once.Do(func() {
+		close(j.stop)
+	})
+	<-j.done
+}
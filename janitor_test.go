@@ -0,0 +1,119 @@
+package radix
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestJanitor(t *testing.T) {
+	var mu sync.Mutex
+	r := New(WithJanitor(time.Millisecond, 2, 0, &mu))
+
+	mu.Lock()
+	r.SetDefaultTTL(time.Hour)
+	for _, k := range []string{"a", "b", "c", "d", "e"} {
+		r.Insert(k, k)
+	}
+	for _, k := range []string{"a", "c", "d", "e"} {
+		backdate(t, r, k)
+	}
+	mu.Unlock()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := r.Len()
+		mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("janitor did not evict expired entries: %d left", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	checkKeys(t, r, []string{"b"})
+}
+
+func TestEvictExpired_Limits(t *testing.T) {
+	r := New()
+	r.SetDefaultTTL(time.Hour)
+	for _, k := range []string{"a", "b", "c", "d", "e"} {
+		r.Insert(k, k)
+		backdate(t, r, k)
+	}
+	if n := r.evictExpired(2, 0); n != 2 {
+		t.Fatalf("bad: %d", n)
+	}
+	// A sweep always makes progress, even with no time to spare
+	if n := r.evictExpired(0, time.Nanosecond); n < 1 {
+		t.Fatalf("bad: %d", n)
+	}
+	r.EvictExpired()
+	if r.Len() != 0 {
+		t.Fatalf("bad: %d", r.Len())
+	}
+}
+
+func TestJanitor_Options(t *testing.T) {
+	for _, fn := range []func(){
+		func() { WithJanitor(0, 0, 0, &sync.Mutex{}) },
+		func() { WithJanitor(time.Second, 0, 0, nil) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("expected panic")
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+func TestJanitor_Derived(t *testing.T) {
+	var mu sync.Mutex
+	r := New(WithJanitor(time.Millisecond, 0, 0, &mu))
+
+	mu.Lock()
+	r.SetDefaultTTL(time.Hour)
+	for _, k := range []string{"a", "b", "c", "d", "x/1", "x/2"} {
+		r.Insert(k, k)
+	}
+	parts := r.Partition([]string{"x/"})
+	left, right := r.Split("c")
+	trees := []*Tree{left, right, parts["x/"]}
+	for _, d := range trees {
+		d.Walk(func(k string, v interface{}) bool {
+			backdate(t, d, k)
+			return false
+		})
+	}
+	mu.Unlock()
+
+	// Expired entries disappear from every derived tree
+	deadline := time.Now().Add(5 * time.Second)
+	for _, d := range trees {
+		for {
+			mu.Lock()
+			n := d.Len()
+			mu.Unlock()
+			if n == 0 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("janitor did not sweep a derived tree: %d left", n)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	for _, d := range append(trees, r) {
+		if err := d.Close(); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+}
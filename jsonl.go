@@ -0,0 +1,66 @@
+package radix
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// jsonlRecord is one line of a JSON Lines export. Keys that are
+// valid UTF-8 are stored as is in k; other keys can't be represented
+// in JSON and are stored escaped in kx instead.
+type jsonlRecord struct {
+	K  string      `json:"k,omitempty"`
+	KX string      `json:"kx,omitempty"`
+	V  interface{} `json:"v"`
+}
+
+// ExportJSONL writes every entry to w in key order as JSON Lines, one
+// {"k":key,"v":value} object per line. Values are encoded with
+// encoding/json. Keys that are not valid UTF-8 are written escaped
+// as "kx" instead of "k". Entries are streamed, so the output is never
+// held in memory as a whole.
+func (t *Tree) ExportJSONL(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	for l := t.leaves.head; l != nil; l = l.next {
+		rec := jsonlRecord{K: l.key, V: l.val}
+		if !utf8.ValidString(l.key) {
+			rec = jsonlRecord{KX: escapeKey(l.key, ""), V: l.val}
+		}
+		if err := enc.Encode(&rec); err != nil {
+			return fmt.Errorf("radix: exporting %q: %w", l.key, err)
+		}
+	}
+	return bw.Flush()
+}
+
+// ImportJSONL inserts the entries of a JSON Lines stream as written by
+// ExportJSONL, reading one record at a time. Values are decoded by
+// encoding/json into interface{}, so numbers become float64. Entries
+// are stored with the same limits as TryInsert, and an entry that
+// doesn't fit stops the import with its error.
+func (t *Tree) ImportJSONL(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		var rec jsonlRecord
+		if err := dec.Decode(&rec); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("radix: JSON Lines record %d: %w", line, err)
+		}
+		key := rec.K
+		if rec.KX != "" {
+			var err error
+			if key, err = unescapeKey(rec.KX); err != nil {
+				return fmt.Errorf("radix: JSON Lines record %d: %w", line, err)
+			}
+		}
+		if _, _, err := t.TryInsert(key, rec.V); err != nil {
+			return err
+		}
+	}
+}
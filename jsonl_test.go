@@ -0,0 +1,51 @@
+package radix
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestJSONL(t *testing.T) {
+	r := New()
+	r.Insert("", "empty")
+	r.Insert("foo", 1.5)
+	r.Insert("foo/bar", map[string]interface{}{"a": true})
+	r.Insert("bin\xff\\", []interface{}{"x"})
+	r.Insert("<html>", nil)
+
+	var buf bytes.Buffer
+	if err := r.ExportJSONL(&buf); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("bad: %q", buf.String())
+	}
+	if lines[0] != `{"v":"empty"}` || lines[1] != `{"k":"<html>","v":null}` ||
+		lines[2] != `{"kx":"bin\\xff\\\\","v":["x"]}` || lines[3] != `{"k":"foo","v":1.5}` {
+		t.Fatalf("bad: %q", lines)
+	}
+
+	out := New()
+	if err := out.ImportJSONL(&buf); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	checkKeys(t, out, []string{"", "<html>", "bin\xff\\", "foo", "foo/bar"})
+	if v, _ := out.Get("foo"); v != 1.5 {
+		t.Fatalf("bad: %v", v)
+	}
+	if v, _ := out.Get("foo/bar"); v.(map[string]interface{})["a"] != true {
+		t.Fatalf("bad: %v", v)
+	}
+
+	// Errors report the record
+	err := New().ImportJSONL(strings.NewReader("{\"k\":\"a\",\"v\":1}\n{\"k\":\n"))
+	if err == nil || !strings.Contains(err.Error(), "record 2") {
+		t.Fatalf("err: %v", err)
+	}
+	r.Insert("chan", make(chan int))
+	if err := r.ExportJSONL(&bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), `"chan"`) {
+		t.Fatalf("err: %v", err)
+	}
+}
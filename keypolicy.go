@@ -0,0 +1,73 @@
+package radix
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// ErrInvalidKey is matched, with errors.Is, by the *KeyError returned
+// for keys a tree's KeyPolicy rejects
+var ErrInvalidKey = errors.New("radix: invalid key")
+
+// KeyPolicy restricts the keys a tree stores, see WithKeyPolicy
+type KeyPolicy struct {
+	// ValidUTF8 rejects keys that are not valid UTF-8
+	ValidUTF8 bool
+
+	// MaxLen rejects keys longer than MaxLen bytes, if positive
+	MaxLen int
+
+	// Allowed, if not empty, holds every byte a key may contain
+	Allowed string
+}
+
+// KeyError reports a key rejected by a tree's KeyPolicy
+type KeyError struct {
+	Key    string
+	Reason string
+}
+
+func (e *KeyError) Error() string {
+	return fmt.Sprintf("radix: invalid key %q: %s", e.Key, e.Reason)
+}
+
+// Is makes a KeyError match ErrInvalidKey
+func (e *KeyError) Is(target error) bool {
+	return target == ErrInvalidKey
+}
+
+// keyPolicy is a KeyPolicy ready for checking keys
+type keyPolicy struct {
+	KeyPolicy
+	allowed *[256]bool
+}
+
+func newKeyPolicy(p KeyPolicy) *keyPolicy {
+	kp := &keyPolicy{KeyPolicy: p}
+	if p.Allowed != "" {
+		kp.allowed = new([256]bool)
+		for i := 0; i < len(p.Allowed); i++ {
+			kp.allowed[p.Allowed[i]] = true
+		}
+	}
+	return kp
+}
+
+// check returns a *KeyError if key breaks the policy
+func (p *keyPolicy) check(key string) error {
+	if p.MaxLen > 0 && len(key) > p.MaxLen {
+		return &KeyError{Key: key, Reason: fmt.Sprintf("longer than %d bytes", p.MaxLen)}
+	}
+	if p.ValidUTF8 && !utf8.ValidString(key) {
+		return &KeyError{Key: key, Reason: "not valid UTF-8"}
+	}
+	if p.allowed != nil {
+		for i := 0; i < len(key); i++ {
+			if !p.allowed[key[i]] {
+				return &KeyError{Key: key, Reason: fmt.Sprintf("byte %q not allowed", key[i])}
+			}
+		}
+	}
+	return nil
+}
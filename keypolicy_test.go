@@ -0,0 +1,71 @@
+package radix
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestKeyPolicy(t *testing.T) {
+	r := New(WithKeyPolicy(KeyPolicy{
+		ValidUTF8: true,
+		MaxLen:    8,
+		Allowed:   "abcdefghijklmnopqrstuvwxyz/\xff",
+	}))
+	for key, ok := range map[string]bool{
+		"foo/bar":   true,
+		"foo/bar/b": false,
+		"Foo":       false,
+		"foo\xff":   false,
+		"":          true,
+	} {
+		_, _, err := r.TryInsert(key, nil)
+		if (err == nil) != ok {
+			t.Fatalf("%q: bad: %v", key, err)
+		}
+		if err != nil {
+			var ke *KeyError
+			if !errors.Is(err, ErrInvalidKey) || !errors.As(err, &ke) || ke.Key != key {
+				t.Fatalf("%q: bad: %v", key, err)
+			}
+		}
+	}
+	if old, updated := r.Insert("Bad", nil); old != nil || updated {
+		t.Fatalf("bad: %v %v", old, updated)
+	}
+	r.InsertWithTTL("Bad", nil, 0)
+	if _, ok := r.Get("Bad"); ok {
+		t.Fatalf("rejected key should not be stored")
+	}
+	if r.Len() != 2 {
+		t.Fatalf("bad: %v", r.ToMap())
+	}
+
+	// Keys are checked after normalization
+	r = New(WithKeyNormalizer(func(k string) string { return k[:len(k)/2] }), WithKeyPolicy(KeyPolicy{MaxLen: 2}))
+	if _, _, err := r.TryInsert("abcd", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Loads refuse bad keys too
+	src := New()
+	src.Insert("ok", "v")
+	src.Insert("not ok", "v")
+	var snap bytes.Buffer
+	if err := src.WriteSnapshot(&snap, stringCodec{}); err != nil {
+		t.Fatal(err)
+	}
+	r = New(WithKeyPolicy(KeyPolicy{Allowed: "ko"}))
+	if err := r.ReadSnapshot(&snap, stringCodec{}); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("bad: %v", err)
+	}
+	records := sortedRecords([]string{"a b", "c"})
+	if _, err := BuildFromSortedReader(records, stringCodec{}, WithKeyPolicy(KeyPolicy{Allowed: "abc"})); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("bad: %v", err)
+	}
+	r = New(WithKeyPolicy(KeyPolicy{Allowed: "ko"}))
+	r.LoadParallel(map[string]interface{}{"ok": 1, "not ok": 2}, 2)
+	if r.Len() != 1 {
+		t.Fatalf("bad: %v", r.ToMap())
+	}
+}
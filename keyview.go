@@ -0,0 +1,126 @@
+package radix
+
+import "strings"
+
+// KeyView presents part of a tree under different keys, translating
+// every key on the way in and out. It lets several logical trees
+// share one physical tree, for example one per tenant with
+// PrefixView, with each view only ever seeing its own entries.
+type KeyView struct {
+	t    *Tree
+	to   func(key string) string
+	from func(key string) (string, bool)
+}
+
+// KeyView returns a view of t whose key k is stored in t under
+// to(k). from reverses to, and reports false for keys of t that are
+// outside the view, which the view then never exposes. to must map
+// prefixes to prefixes, so that to(a) is a prefix of to(a+b), as
+// adding a prefix or escaping bytes does.
+func (t *Tree) KeyView(to func(key string) string, from func(key string) (string, bool)) *KeyView {
+	return &KeyView{t: t, to: to, from: from}
+}
+
+// PrefixView returns a view of the entries of t under prefix, with
+// the prefix stripped from their keys. The prefix must end in a
+// separator, a byte that is not an ASCII letter or digit, and it
+// panics otherwise: a view of "t1" would also see, walk and delete the
+// keys of "t10". Views only keep apart when no name they are built
+// from contains the separator, as with "t1/" and "t10/" for tenant
+// names free of '/'.
+func (t *Tree) PrefixView(prefix string) *KeyView {
+	if prefix != "" && isWordByte(prefix[len(prefix)-1]) {
+		panic("radix: PrefixView prefix must end in a separator")
+	}
+	return t.KeyView(func(k string) string {
+		return prefix + k
+	}, func(k string) (string, bool) {
+		if !strings.HasPrefix(k, prefix) {
+			return "", false
+		}
+		return k[len(prefix):], true
+	})
+}
+
+// Tree returns the underlying tree
+func (v *KeyView) Tree() *Tree {
+	return v.t
+}
+
+// Insert adds or updates an entry, like Tree.Insert
+func (v *KeyView) Insert(k string, val interface{}) (interface{}, bool) {
+	return v.t.Insert(v.to(k), val)
+}
+
+// Delete removes an entry, like Tree.Delete
+func (v *KeyView) Delete(k string) (interface{}, bool) {
+	return v.t.Delete(v.to(k))
+}
+
+// DeletePrefix removes the entries under prefix, like
+// Tree.DeletePrefix
+func (v *KeyView) DeletePrefix(prefix string) int {
+	return v.t.DeletePrefix(v.to(prefix))
+}
+
+// Get looks up an entry, like Tree.Get
+func (v *KeyView) Get(k string) (interface{}, bool) {
+	return v.t.Get(v.to(k))
+}
+
+// LongestPrefix returns the entry of the view with the longest key
+// that is a prefix of k, like Tree.LongestPrefix
+func (v *KeyView) LongestPrefix(k string) (string, interface{}, bool) {
+	var lastKey string
+	var lastVal interface{}
+	var found bool
+	v.t.WalkPath(v.to(k), func(tk string, val interface{}) bool {
+		if vk, ok := v.from(tk); ok {
+			lastKey, lastVal, found = vk, val, true
+		}
+		return false
+	})
+	return lastKey, lastVal, found
+}
+
+// Walk visits every entry of the view, like Tree.Walk
+func (v *KeyView) Walk(fn WalkFn) {
+	v.WalkPrefix("", fn)
+}
+
+// WalkPrefix visits the entries of the view under prefix, like
+// Tree.WalkPrefix
+func (v *KeyView) WalkPrefix(prefix string, fn WalkFn) {
+	v.t.WalkPrefix(v.to(prefix), func(tk string, val interface{}) bool {
+		if vk, ok := v.from(tk); ok {
+			return fn(vk, val)
+		}
+		return false
+	})
+}
+
+// Len returns the number of entries in the view. It has to count
+// them, taking time proportional to the size of the view.
+func (v *KeyView) Len() int {
+	n := 0
+	v.Walk(func(string, interface{}) bool {
+		n++
+		return false
+	})
+	return n
+}
+
+// ToMap returns the entries of the view as a map
+func (v *KeyView) ToMap() map[string]interface{} {
+	out := make(map[string]interface{})
+	v.Walk(func(k string, val interface{}) bool {
+		out[k] = val
+		return false
+	})
+	return out
+}
+
+// isWordByte reports whether c is an ASCII letter or digit
+func isWordByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
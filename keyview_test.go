@@ -0,0 +1,107 @@
+package radix
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPrefixView(t *testing.T) {
+	r := New()
+	a, b := r.PrefixView("a/"), r.PrefixView("b/")
+	a.Insert("x", 1)
+	a.Insert("x/y", 2)
+	b.Insert("x", 3)
+	r.Insert("other", 4)
+
+	if v, ok := a.Get("x"); !ok || v != 1 {
+		t.Fatalf("bad: %v %v", v, ok)
+	}
+	if v, ok := b.Get("x"); !ok || v != 3 {
+		t.Fatalf("bad: %v %v", v, ok)
+	}
+	if a.Len() != 2 || b.Len() != 1 || r.Len() != 4 {
+		t.Fatalf("bad: %d %d %d", a.Len(), b.Len(), r.Len())
+	}
+	if got := a.ToMap(); !reflect.DeepEqual(got, map[string]interface{}{"x": 1, "x/y": 2}) {
+		t.Fatalf("bad: %v", got)
+	}
+	if k, v, ok := a.LongestPrefix("x/y/z"); !ok || k != "x/y" || v != 2 {
+		t.Fatalf("bad: %q %v %v", k, v, ok)
+	}
+	if _, _, ok := b.LongestPrefix("y"); ok {
+		t.Fatalf("bad")
+	}
+
+	if n := a.DeletePrefix(""); n != 2 {
+		t.Fatalf("bad: %d", n)
+	}
+	if _, ok := b.Delete("x"); !ok {
+		t.Fatalf("bad")
+	}
+	checkKeys(t, r, []string{"other"})
+	if a.Tree() != r {
+		t.Fatalf("bad")
+	}
+}
+
+func TestPrefixView_Siblings(t *testing.T) {
+	r := New()
+	for _, prefix := range []string{"t1", "t1.x", "t"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("expected panic for %q", prefix)
+				}
+			}()
+			r.PrefixView(prefix)
+		}()
+	}
+
+	t1, t10 := r.PrefixView("t1/"), r.PrefixView("t10/")
+	t1.Insert("a", 1)
+	t10.Insert("a", 10)
+	t10.Insert("b", 11)
+	if got := t1.ToMap(); !reflect.DeepEqual(got, map[string]interface{}{"a": 1}) {
+		t.Fatalf("bad: %v", got)
+	}
+	if _, ok := t1.Get("0/a"); ok {
+		t.Fatalf("bad")
+	}
+	if n := t1.DeletePrefix(""); n != 1 {
+		t.Fatalf("bad: %d", n)
+	}
+	checkKeys(t, r, []string{"t10/a", "t10/b"})
+}
+
+func TestKeyView(t *testing.T) {
+	// Escape separators so view keys can contain them
+	r := New()
+	v := r.KeyView(func(k string) string {
+		return "t1/" + strings.ReplaceAll(k, "/", "%")
+	}, func(k string) (string, bool) {
+		if !strings.HasPrefix(k, "t1/") {
+			return "", false
+		}
+		return strings.ReplaceAll(k[3:], "%", "/"), true
+	})
+	v.Insert("a/b", 1)
+	v.Insert("a/c", 2)
+	v.Insert("d", 3)
+	r.Insert("t2/a", 4)
+
+	if _, ok := r.Get("t1/a%b"); !ok {
+		t.Fatalf("bad")
+	}
+	var keys []string
+	v.WalkPrefix("a/", func(k string, val interface{}) bool {
+		keys = append(keys, k)
+		return false
+	})
+	if !reflect.DeepEqual(keys, []string{"a/b", "a/c"}) {
+		t.Fatalf("bad: %q", keys)
+	}
+	if v.Len() != 3 {
+		t.Fatalf("bad: %d", v.Len())
+	}
+}
@@ -0,0 +1,198 @@
+package radix
+
+// leafList threads every leaf of a tree in key order, so the
+// neighbours of a leaf are reachable without a descent from the root
+type leafList struct {
+	head, tail *leafNode
+}
+
+// insert links l between pred and succ, which must be adjacent. At
+// most one of them needs to be given; if both are nil the list must
+// be empty.
+func (ll *leafList) insert(l, pred, succ *leafNode) {
+	switch {
+	case succ != nil:
+		pred = succ.prev
+	case pred != nil:
+		succ = pred.next
+	}
+	l.prev, l.next = pred, succ
+	if pred != nil {
+		pred.next = l
+	} else {
+		ll.head = l
+	}
+	if succ != nil {
+		succ.prev = l
+	} else {
+		ll.tail = l
+	}
+}
+
+// remove unlinks l from the list
+func (ll *leafList) remove(l *leafNode) {
+	ll.removeRange(l, l)
+}
+
+// removeRange unlinks the run of leaves from first to last
+func (ll *leafList) removeRange(first, last *leafNode) {
+	if first.prev != nil {
+		first.prev.next = last.next
+	} else {
+		ll.head = last.next
+	}
+	if last.next != nil {
+		last.next.prev = first.prev
+	} else {
+		ll.tail = first.prev
+	}
+	first.prev, last.next = nil, nil
+}
+
+// linkNewEdge links a leaf that was just added as the only entry
+// under the edge with the given label from parent.
+func (t *Tree) linkNewEdge(parent *node, label byte, l *leafNode) {
+	idx := parent.edgeIndex(label)
+	switch {
+	case idx+1 < len(parent.edges):
+		t.leaves.insert(l, nil, minLeaf(parent.edges[idx+1].node))
+	case idx > 0:
+		t.leaves.insert(l, maxLeaf(parent.edges[idx-1].node), nil)
+	default:
+		// The parent's own leaf, if any, is the only smaller key
+		// in its subtree, and nothing in it is larger. If there is
+		// none, the parent is the root of an empty tree.
+		t.leaves.insert(l, parent.leaf, nil)
+	}
+}
+
+// minLeaf returns the smallest leaf under n, or nil if there is none
+func minLeaf(n *node) *leafNode {
+	for {
+		if n.leaf != nil {
+			return n.leaf
+		}
+		if len(n.edges) == 0 {
+			return nil
+		}
+		n = n.edges[0].node
+	}
+}
+
+// maxLeaf returns the largest leaf under n, or nil if there is none
+func maxLeaf(n *node) *leafNode {
+	for {
+		if num := len(n.edges); num > 0 {
+			n = n.edges[num-1].node
+			continue
+		}
+		return n.leaf
+	}
+}
+
+// seek locates s in the key order. If s is stored, exact is its leaf.
+// pred and succ are the leaves immediately before and after s.
+func (t *Tree) seek(s string) (exact, pred, succ *leafNode) {
+	if t.smallMode {
+		return t.seekSmall(s)
+	}
+	n := t.root
+	search := s
+	for {
+		// Check for key exhaution
+		if len(search) == 0 {
+			if n.leaf != nil {
+				return n.leaf, n.leaf.prev, n.leaf.next
+			}
+			succ = minLeaf(n)
+			break
+		}
+
+		// Look for an edge
+		idx := n.edgeIndex(search[0])
+		if idx < len(n.edges) && n.edges[idx].label == search[0] {
+			child := n.edges[idx].node
+			common := longestPrefix(search, child.prefix)
+			if common == len(child.prefix) {
+				n = child
+				search = search[common:]
+				continue
+			}
+
+			// The search diverges inside the child's prefix
+			if common == len(search) || t.collation.less(search[common], child.prefix[common]) {
+				succ = minLeaf(child)
+			} else {
+				pred = maxLeaf(child)
+			}
+			break
+		}
+
+		// No edge, so the neighbours are in the adjacent subtrees
+		switch {
+		case idx < len(n.edges):
+			succ = minLeaf(n.edges[idx].node)
+		case idx > 0:
+			pred = maxLeaf(n.edges[idx-1].node)
+		default:
+			pred = n.leaf
+		}
+		break
+	}
+
+	if succ != nil {
+		pred = succ.prev
+	} else if pred != nil {
+		succ = pred.next
+	}
+	return nil, pred, succ
+}
+
+// Successor returns the smallest key in the tree that is strictly
+// greater than s, which need not be stored itself. Once s has been
+// located, finding its successor takes constant time.
+func (t *Tree) Successor(s string) (string, interface{}, bool) {
+	s = t.norm(s)
+	if _, _, succ := t.seek(s); succ != nil {
+		return succ.key, succ.val, true
+	}
+	return "", nil, false
+}
+
+// Predecessor returns the largest key in the tree that is strictly
+// less than s, which need not be stored itself.
+func (t *Tree) Predecessor(s string) (string, interface{}, bool) {
+	s = t.norm(s)
+	if _, pred, _ := t.seek(s); pred != nil {
+		return pred.key, pred.val, true
+	}
+	return "", nil, false
+}
+
+// Ceiling returns the smallest key in the tree that is greater than or
+// equal to s, which need not be stored itself
+func (t *Tree) Ceiling(s string) (string, interface{}, bool) {
+	s = t.norm(s)
+	exact, _, succ := t.seek(s)
+	if exact != nil {
+		succ = exact
+	}
+	if succ != nil {
+		return succ.key, succ.val, true
+	}
+	return "", nil, false
+}
+
+// Floor returns the largest key in the tree that is less than or
+// equal to s, which need not be stored itself
+func (t *Tree) Floor(s string) (string, interface{}, bool) {
+	s = t.norm(s)
+	exact, pred, _ := t.seek(s)
+	if exact != nil {
+		pred = exact
+	}
+	if pred != nil {
+		return pred.key, pred.val, true
+	}
+	return "", nil, false
+}
@@ -0,0 +1,134 @@
+package radix
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestLeafLinks(t *testing.T) {
+	r := New()
+	keys := make(map[string]bool)
+	check := func() {
+		t.Helper()
+		var sorted []string
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		i := 0
+		var prev *leafNode
+		for l := r.leaves.head; l != nil; l = l.next {
+			if i >= len(sorted) || l.key != sorted[i] {
+				t.Fatalf("bad order at %d: %q %q", i, l.key, sorted)
+			}
+			if l.prev != prev {
+				t.Fatalf("bad prev link at %q", l.key)
+			}
+			prev = l
+			i++
+		}
+		if i != len(sorted) || r.leaves.tail != prev {
+			t.Fatalf("bad list length or tail: %d %d", i, len(sorted))
+		}
+	}
+
+	for _, k := range []string{"foo", "", "foobar", "fo", "bar", "baz", "foozip", "f", "z", "a"} {
+		r.Insert(k, k)
+		keys[k] = true
+		check()
+	}
+	for i := 0; i < 200; i++ {
+		k := generateUUID()[:i%8+1]
+		r.Insert(k, k)
+		keys[k] = true
+	}
+	check()
+
+	for _, k := range []string{"foo", "", "z", "bar"} {
+		r.Delete(k)
+		delete(keys, k)
+		check()
+	}
+	r.DeletePrefix("f")
+	for k := range keys {
+		if len(k) > 0 && k[0] == 'f' {
+			delete(keys, k)
+		}
+	}
+	check()
+}
+
+func TestSuccessorPredecessor(t *testing.T) {
+	r := New()
+	keys := []string{"", "a", "ab", "abc", "abd", "b", "ba", "bab", "c", "foo/bar", "foo/baz", "foobar"}
+	for _, k := range keys {
+		r.Insert(k, k)
+	}
+	probes := append([]string{"0", "aa", "abb", "abz", "ac", "bb", "d", "foo", "foo/", "foo/bas", "foo/c", "fooa", "zzz"}, keys...)
+	for _, p := range probes {
+		i := sort.SearchStrings(keys, p)
+		j := i
+		if j < len(keys) && keys[j] == p {
+			j++
+		}
+
+		k, v, ok := r.Successor(p)
+		if j < len(keys) {
+			if !ok || k != keys[j] || v != keys[j] {
+				t.Fatalf("bad successor of %q: %q %v", p, k, ok)
+			}
+		} else if ok {
+			t.Fatalf("unexpected successor of %q: %q", p, k)
+		}
+
+		k, v, ok = r.Predecessor(p)
+		if i > 0 {
+			if !ok || k != keys[i-1] || v != keys[i-1] {
+				t.Fatalf("bad predecessor of %q: %q %v", p, k, ok)
+			}
+		} else if ok {
+			t.Fatalf("unexpected predecessor of %q: %q", p, k)
+		}
+	}
+
+	if _, _, ok := New().Successor(""); ok {
+		t.Fatalf("empty tree has no successor")
+	}
+}
+
+func TestCeilingFloor(t *testing.T) {
+	keys := []string{"a", "ab", "abc", "abd", "b", "ba", "bab", "c", "foo/bar", "foobar"}
+	for _, opts := range [][]Option{nil, {WithSmallTree(100)}} {
+		r := New(opts...)
+		for _, k := range keys {
+			r.Insert(k, k)
+		}
+		probes := append([]string{"", "0", "aa", "abb", "abz", "bb", "foo", "fooa", "zzz"}, keys...)
+		for _, p := range probes {
+			i := sort.SearchStrings(keys, p)
+
+			k, v, ok := r.Ceiling(p)
+			if i < len(keys) {
+				if !ok || k != keys[i] || v != keys[i] {
+					t.Fatalf("bad ceiling of %q: %q %v", p, k, ok)
+				}
+			} else if ok {
+				t.Fatalf("unexpected ceiling of %q: %q", p, k)
+			}
+
+			j := i
+			if j < len(keys) && keys[j] == p {
+				j++
+			}
+			k, v, ok = r.Floor(p)
+			if j > 0 {
+				if !ok || k != keys[j-1] || v != keys[j-1] {
+					t.Fatalf("bad floor of %q: %q %v", p, k, ok)
+				}
+			} else if ok {
+				t.Fatalf("unexpected floor of %q: %q", p, k)
+			}
+		}
+	}
+}
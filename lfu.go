@@ -0,0 +1,67 @@
+package radix
+
+import "container/heap"
+
+// Hits returns how many times Get has found the entry for key since
+// it was inserted, and whether the key is present. Hits are only
+// counted in trees created with WithLFU.
+func (t *Tree) Hits(key string) (uint64, bool) {
+	l := t.getLeaf(t.norm(key))
+	if l == nil {
+		return 0, false
+	}
+	if l.ext == nil {
+		return 0, true
+	}
+	return l.ext.hits, true
+}
+
+// hit records a Get of l
+func (t *Tree) hit(l *leafNode) {
+	x := l.x()
+	x.hits++
+	if t.lfuCap > 0 {
+		heap.Fix(&t.lfu, x.lfuIdx)
+	}
+}
+
+// makeRoom evicts the least frequently used entry if storing the new
+// key s would take the tree over its capacity
+func (t *Tree) makeRoom(s string) {
+	if t.size < t.lfuCap || len(t.lfu) == 0 || t.getLeaf(s) != nil {
+		return
+	}
+	t.Delete(t.lfu[0].key)
+}
+
+// lfuHeap is a min-heap of the leaves by hits, so the least frequently
+// used is at the top. Each leaf tracks its own index.
+type lfuHeap []*leafNode
+
+func (h lfuHeap) Len() int {
+	return len(h)
+}
+
+func (h lfuHeap) Less(i, j int) bool {
+	return h[i].ext.hits < h[j].ext.hits
+}
+
+func (h lfuHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].ext.lfuIdx = i
+	h[j].ext.lfuIdx = j
+}
+
+func (h *lfuHeap) Push(x interface{}) {
+	l := x.(*leafNode)
+	l.x().lfuIdx = len(*h)
+	*h = append(*h, l)
+}
+
+func (h *lfuHeap) Pop() interface{} {
+	old := *h
+	l := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return l
+}
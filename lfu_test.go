@@ -0,0 +1,85 @@
+package radix
+
+import (
+	"strings"
+	"testing"
+)
+
+// walkKeys returns the keys of r in order, without counting hits
+func walkKeys(r *Tree) string {
+	var keys []string
+	r.Walk(func(k string, v interface{}) bool {
+		keys = append(keys, k)
+		return false
+	})
+	return strings.Join(keys, ",")
+}
+
+func TestLFU(t *testing.T) {
+	r := New(WithLFU(3))
+	for _, k := range []string{"a", "b", "c"} {
+		r.Insert(k, k)
+	}
+	for i := 0; i < 3; i++ {
+		r.Get("a")
+		r.Get("c")
+	}
+	r.Get("b")
+	r.Get("missing")
+	if n, ok := r.Hits("a"); !ok || n != 3 {
+		t.Fatalf("bad: %d %v", n, ok)
+	}
+
+	// Updates don't evict, new keys evict the least used
+	r.Insert("a", "A")
+	if r.Len() != 3 {
+		t.Fatalf("bad: %d", r.Len())
+	}
+	r.Insert("d", "d")
+	if keys := walkKeys(r); keys != "a,c,d" {
+		t.Fatalf("bad: %s", keys)
+	}
+	if n, _ := r.Hits("a"); n != 3 {
+		t.Fatalf("updates should keep hits: %d", n)
+	}
+	r.Get("d")
+	r.Insert("e", "e")
+	if keys := walkKeys(r); keys != "a,c,e" {
+		t.Fatalf("bad: %s", keys)
+	}
+
+	// Removing entries makes room
+	r.Delete("a")
+	r.Insert("f", "f")
+	if keys := walkKeys(r); keys != "c,e,f" {
+		t.Fatalf("bad: %s", keys)
+	}
+
+	// Split trees keep their own heaps
+	left, right := r.Split("d")
+	if len(left.lfu) != 1 || len(right.lfu) != 2 {
+		t.Fatalf("bad: %d %d", len(left.lfu), len(right.lfu))
+	}
+	parts := right.Partition([]string{"e"})
+	if len(right.lfu) != 1 || len(parts["e"].lfu) != 1 {
+		t.Fatalf("bad: %d %d", len(right.lfu), len(parts["e"].lfu))
+	}
+}
+
+func TestLFU_CountOnly(t *testing.T) {
+	r := New(WithLFU(0))
+	for i := 0; i < 10; i++ {
+		r.Insert(string(rune('a'+i)), i)
+	}
+	r.Get("a")
+	if n, _ := r.Hits("a"); n != 1 || r.Len() != 10 || len(r.lfu) != 0 {
+		t.Fatalf("bad: %d %d", n, r.Len())
+	}
+
+	r = New()
+	r.Insert("a", 1)
+	r.Get("a")
+	if n, ok := r.Hits("a"); !ok || n != 0 {
+		t.Fatalf("hits should only be counted with WithLFU: %d", n)
+	}
+}
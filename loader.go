@@ -0,0 +1,80 @@
+package radix
+
+import "time"
+
+// LoaderFunc loads the value for a key missing from the tree,
+// returning false if the key doesn't exist in the backing source.
+type LoaderFunc func(key string) (interface{}, bool)
+
+// load fetches a missing key from the loader and caches it. The
+// value is returned even if a limit such as the memory budget keeps
+// it from being stored.
+func (t *Tree) load(s string) (interface{}, bool) {
+	if t.knownAbsent(s) {
+		return nil, false
+	}
+	v, ok := t.loader(s)
+	t.loaded(s, v, ok)
+	return v, ok
+}
+
+// knownAbsent reports whether the negative cache holds s
+func (t *Tree) knownAbsent(s string) bool {
+	return t.negativeTTL > 0 && t.absent.contains(s, t.currentTime())
+}
+
+// loaded caches what the loader returned for s
+func (t *Tree) loaded(s string, v interface{}, ok bool) {
+	switch {
+	case ok:
+		t.TryInsert(s, v)
+	case t.negativeTTL > 0:
+		now := t.currentTime()
+		t.absent.add(s, now.Add(t.negativeTTL), now)
+	}
+}
+
+// negativeCache remembers keys known to be missing until they expire
+type negativeCache struct {
+	expires map[string]time.Time
+
+	// sweepAt is the size at which expired keys are next purged,
+	// which bounds the map to about twice its live size
+	sweepAt int
+}
+
+// contains reports whether key is cached as missing at time now
+func (c *negativeCache) contains(key string, now time.Time) bool {
+	exp, ok := c.expires[key]
+	if !ok {
+		return false
+	}
+	if now.Before(exp) {
+		return true
+	}
+	delete(c.expires, key)
+	return false
+}
+
+// add caches key as missing until exp, the time now being now
+func (c *negativeCache) add(key string, exp, now time.Time) {
+	if c.expires == nil {
+		c.expires = make(map[string]time.Time)
+	}
+	c.expires[key] = exp
+	if len(c.expires) >= c.sweepAt {
+		for k, e := range c.expires {
+			if !now.Before(e) {
+				delete(c.expires, k)
+			}
+		}
+		c.sweepAt = 2*len(c.expires) + 16
+	}
+}
+
+// forget drops key from the cache
+func (c *negativeCache) forget(key string) {
+	if len(c.expires) > 0 {
+		delete(c.expires, key)
+	}
+}
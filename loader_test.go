@@ -0,0 +1,134 @@
+package radix
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoader(t *testing.T) {
+	calls := 0
+	loader := func(k string) (interface{}, bool) {
+		calls++
+		if strings.HasPrefix(k, "missing") {
+			return nil, false
+		}
+		return strings.ToUpper(k), true
+	}
+	r := New(WithLoader(loader))
+	r.Insert("foo", "stored")
+
+	if v, ok := r.Get("foo"); !ok || v != "stored" || calls != 0 {
+		t.Fatalf("bad: %v %v %d", v, ok, calls)
+	}
+	for i := 0; i < 2; i++ {
+		if v, ok := r.Get("bar"); !ok || v != "BAR" || calls != 1 {
+			t.Fatalf("bad: %v %v %d", v, ok, calls)
+		}
+	}
+	if r.Len() != 2 {
+		t.Fatalf("loaded entry should be cached: %d", r.Len())
+	}
+
+	if _, ok := r.Get("missing"); ok {
+		t.Fatalf("bad")
+	}
+	if r.Len() != 2 || calls != 2 {
+		t.Fatalf("bad: %d %d", r.Len(), calls)
+	}
+
+	// Prefix lookups don't trigger the loader
+	r.WalkPrefix("baz", func(k string, v interface{}) bool {
+		t.Fatalf("unexpected key %q", k)
+		return false
+	})
+	if calls != 2 {
+		t.Fatalf("bad: %d", calls)
+	}
+}
+
+func TestLoader_Budget(t *testing.T) {
+	loader := func(k string) (interface{}, bool) {
+		return k, true
+	}
+	r := New(WithLoader(loader), WithMemoryBudget(entryOverhead+1))
+	if v, ok := r.Get("a"); !ok || v != "a" {
+		t.Fatalf("bad: %v %v", v, ok)
+	}
+	if v, ok := r.Get("b"); !ok || v != "b" {
+		t.Fatalf("loaded value should be returned even if not cached: %v %v", v, ok)
+	}
+	if r.Len() != 1 {
+		t.Fatalf("bad len: %d", r.Len())
+	}
+}
+
+func TestLoader_NegativeCache(t *testing.T) {
+	calls := 0
+	loader := func(k string) (interface{}, bool) {
+		calls++
+		return nil, false
+	}
+	r := New(WithLoader(loader), WithNegativeCache(time.Hour))
+
+	for i := 0; i < 3; i++ {
+		if _, ok := r.Get("typo"); ok {
+			t.Fatalf("bad")
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("misses should be cached: %d", calls)
+	}
+
+	// Expired entries go back to the loader
+	r.absent.expires["typo"] = time.Now().Add(-time.Second)
+	r.Get("typo")
+	if calls != 2 {
+		t.Fatalf("expired miss should reload: %d", calls)
+	}
+
+	// Inserting the key clears the negative entry
+	r.Insert("typo", 1)
+	if _, ok := r.absent.expires["typo"]; ok {
+		t.Fatalf("negative entry should be cleared")
+	}
+	r.Delete("typo")
+	r.Get("typo")
+	if calls != 3 {
+		t.Fatalf("bad: %d", calls)
+	}
+}
+
+func TestNegativeCache_Sweep(t *testing.T) {
+	var c negativeCache
+	now := time.Now()
+	past := now.Add(-time.Second)
+	for i := 0; i < 1000; i++ {
+		c.add(fmt.Sprintf("k%d", i), past, now)
+	}
+	if len(c.expires) > 100 {
+		t.Fatalf("expired entries should be swept: %d", len(c.expires))
+	}
+}
+
+func TestNegativeCache_Clock(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	calls := 0
+	loader := func(k string) (interface{}, bool) {
+		calls++
+		return nil, false
+	}
+	r := New(WithClock(clock), WithLoader(loader), WithNegativeCache(time.Minute))
+	r.Get("foo")
+	clock.advance(59 * time.Second)
+	r.Get("foo")
+	if calls != 1 {
+		t.Fatalf("bad: %d", calls)
+	}
+	clock.advance(time.Second)
+	r.Get("foo")
+	if calls != 2 {
+		t.Fatalf("bad: %d", calls)
+	}
+}
@@ -0,0 +1,48 @@
+package radix
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// ErrBudgetExceeded is returned by TryInsert when storing an entry
+// would take the tree over its memory budget.
+var ErrBudgetExceeded = errors.New("radix: memory budget exceeded")
+
+// entryOverhead approximates the fixed cost of one entry: its leaf,
+// and the node and edge that usually come with it.
+const entryOverhead = int(unsafe.Sizeof(leafNode{}) + unsafe.Sizeof(node{}) + unsafe.Sizeof(edge{}))
+
+// BytesUsed returns the approximate number of bytes held by the
+// entries in the tree. It counts keys and per-entry structure, plus
+// values if a sizer was configured with WithValueSizer.
+func (t *Tree) BytesUsed() int {
+	return t.bytes
+}
+
+// entryBytes is the approximate cost of storing a single entry
+func (t *Tree) entryBytes(key string, v interface{}) int {
+	n := entryOverhead + len(key)
+	if t.sizer != nil {
+		n += t.sizer(v)
+	}
+	return n
+}
+
+// checkBudget returns ErrBudgetExceeded if inserting or updating
+// the given entry would exceed the memory budget.
+func (t *Tree) checkBudget(s string, v interface{}) error {
+	var delta int
+	if old, ok := t.get(s); ok {
+		if t.sizer == nil {
+			return nil
+		}
+		delta = t.sizer(v) - t.sizer(old)
+	} else {
+		delta = t.entryBytes(s, v)
+	}
+	if delta > 0 && t.bytes+delta > t.budget {
+		return ErrBudgetExceeded
+	}
+	return nil
+}
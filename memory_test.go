@@ -0,0 +1,109 @@
+package radix
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestBytesUsed(t *testing.T) {
+	r := New()
+	if r.BytesUsed() != 0 {
+		t.Fatalf("bad: %d", r.BytesUsed())
+	}
+	r.Insert("foo", 1)
+	r.Insert("foobar", 2)
+	r.Insert("zip", 3)
+	want := 3*entryOverhead + len("foo") + len("foobar") + len("zip")
+	if r.BytesUsed() != want {
+		t.Fatalf("bad: %d %d", r.BytesUsed(), want)
+	}
+
+	// Updates don't change the size without a value sizer
+	r.Insert("foo", 4)
+	if r.BytesUsed() != want {
+		t.Fatalf("bad: %d %d", r.BytesUsed(), want)
+	}
+
+	r.Delete("zip")
+	want -= entryOverhead + len("zip")
+	if r.BytesUsed() != want {
+		t.Fatalf("bad: %d %d", r.BytesUsed(), want)
+	}
+
+	r.DeletePrefix("foo")
+	if r.BytesUsed() != 0 {
+		t.Fatalf("bad: %d", r.BytesUsed())
+	}
+}
+
+func TestMemoryBudget(t *testing.T) {
+	sizer := func(v interface{}) int {
+		return len(v.(string))
+	}
+	budget := 2*entryOverhead + 20
+	r := New(WithMemoryBudget(budget), WithValueSizer(sizer))
+
+	if _, _, err := r.TryInsert("a", "0123456789"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, _, err := r.TryInsert("b", "01234567"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if r.BytesUsed() != budget {
+		t.Fatalf("bad: %d %d", r.BytesUsed(), budget)
+	}
+
+	// A new key doesn't fit
+	if _, _, err := r.TryInsert("c", ""); err != ErrBudgetExceeded {
+		t.Fatalf("expected budget error, got %v", err)
+	}
+	if _, ok := r.Get("c"); ok {
+		t.Fatalf("rejected key should not be stored")
+	}
+
+	// Neither does growing a value, but shrinking one does
+	if _, _, err := r.TryInsert("a", "0123456789x"); err != ErrBudgetExceeded {
+		t.Fatalf("expected budget error, got %v", err)
+	}
+	if _, _, err := r.TryInsert("a", "012345678"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Insert drops entries that don't fit
+	if _, updated := r.Insert("dd", "xx"); updated {
+		t.Fatalf("bad")
+	}
+	if _, ok := r.Get("dd"); ok {
+		t.Fatalf("rejected key should not be stored")
+	}
+	if old, updated := r.Insert("a", "0123456789xyz"); old != nil || updated {
+		t.Fatalf("bad: %v %v", old, updated)
+	}
+	if v, _ := r.Get("a"); v != "012345678" {
+		t.Fatalf("rejected update should keep the old value, got %v", v)
+	}
+
+	// Freeing space lets new entries in
+	r.Delete("b")
+	if _, _, err := r.TryInsert("c", "01234567"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if r.Len() != 2 {
+		t.Fatalf("bad len: %d", r.Len())
+	}
+}
+
+func TestStructSizes(t *testing.T) {
+	// Optional features keep their state out of line, so they don't
+	// grow every leaf, node and tree
+	word := unsafe.Sizeof(uintptr(0))
+	for name, size := range map[string]uintptr{
+		"leafNode": unsafe.Sizeof(leafNode{}),
+		"node":     unsafe.Sizeof(node{}),
+		"Tree":     unsafe.Sizeof(Tree{}),
+	} {
+		if size > 8*word {
+			t.Fatalf("%s takes %d bytes", name, size)
+		}
+	}
+}
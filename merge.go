@@ -0,0 +1,212 @@
+package radix
+
+// ResolveFunc picks the value for a key held by both trees being
+// combined, given its value in each
+type ResolveFunc func(key string, a, b interface{}) interface{}
+
+// Merge stores every entry of other in t. Where both trees hold a
+// key, resolve picks the value from t's and other's, or other's value
+// wins if resolve is nil. other is left untouched.
+//
+// The trees are merged structurally: one ordered pass over both
+// produces the merged entries, from which t's nodes are rebuilt bottom
+// up, as Union does, rather than inserting the entries of other one at
+// a time. Entries t already held keep their leaves, and with them
+// their expiry, hits and place in insertion order. If t refuses any
+// new entry, for its key policy or memory budget, Merge returns the
+// error and leaves t as it was.
+//
+// Trees with an LFU capacity or a key normalizer, or whose collation
+// differs from other's, are merged one entry at a time, as if by
+// TryInsert, stopping at the first entry t refuses.
+func (t *Tree) Merge(other *Tree, resolve ResolveFunc) error {
+	if t.lfuCap > 0 || t.normalize != nil || !sameCollation(t.collation, other.collation) {
+		return t.mergeEach(other, resolve)
+	}
+
+	// Resolve every key first, so a refused entry changes nothing.
+	// leaves holds t's leaf for each key, or nil if only other has it,
+	// and updated whether the value comes from other.
+	var keys []string
+	var vals []interface{}
+	var leaves []*leafNode
+	var updated []bool
+	var delta int
+	err := t.zip(other, func(k string, a, b *leafNode) error {
+		var v interface{}
+		switch {
+		case b == nil:
+			v = a.val
+		case a == nil:
+			if t.keyPolicy != nil {
+				if err := t.keyPolicy.check(k); err != nil {
+					return err
+				}
+			}
+			v = b.val
+			delta += t.entryBytes(k, v)
+		default:
+			v = b.val
+			if resolve != nil {
+				v = resolve(k, a.val, b.val)
+			}
+			if t.sizer != nil {
+				delta += t.sizer(v) - t.sizer(a.val)
+			}
+		}
+		keys, vals = append(keys, k), append(vals, v)
+		leaves, updated = append(leaves, a), append(updated, b != nil)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if t.budget > 0 && t.bytes+delta > t.budget {
+		return ErrBudgetExceeded
+	}
+
+	// Rebuild the structure from the merged entries
+	t.root, t.small = t.newNode("", 0), nil
+	t.leaves, t.size = leafList{}, 0
+	t.mods++
+	app := newAppender(t)
+	for i, k := range keys {
+		l := leaves[i]
+		if l == nil {
+			l = t.newLeaf(k, vals[i])
+			app.addLeaf(l)
+			t.added(l)
+			continue
+		}
+		app.addLeaf(l)
+		t.leaves.insert(l, t.leaves.tail, nil)
+		t.size++
+		if updated[i] {
+			old := l.val
+			l.val = vals[i]
+			t.leafUpdated(l, old)
+			if t.defaultTTL > 0 || l.expires() != 0 {
+				t.setExpiry(l, t.defaultTTL)
+			}
+		}
+	}
+	app.finish()
+	return nil
+}
+
+// mergeEach is Merge by inserting the entries of other one at a time
+func (t *Tree) mergeEach(other *Tree, resolve ResolveFunc) error {
+	for l := other.leaves.head; l != nil; l = l.next {
+		v := l.val
+		if resolve != nil {
+			if old, ok := t.get(t.norm(l.key)); ok {
+				v = resolve(l.key, old, v)
+			}
+		}
+		if _, _, err := t.TryInsert(l.key, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Union returns a new tree holding every key of t or other. Where both
+// hold a key, resolve picks the value from t's and other's, or other's
+// value wins if resolve is nil.
+func (t *Tree) Union(other *Tree, resolve ResolveFunc) *Tree {
+	return t.combine(other, func(k string, a, b *leafNode) (interface{}, bool) {
+		switch {
+		case a == nil:
+			return b.val, true
+		case b == nil:
+			return a.val, true
+		case resolve != nil:
+			return resolve(k, a.val, b.val), true
+		}
+		return b.val, true
+	})
+}
+
+// Intersect returns a new tree holding the keys of t that other holds
+// as well. resolve picks the value from t's and other's, or t's value
+// is kept if resolve is nil.
+func (t *Tree) Intersect(other *Tree, resolve ResolveFunc) *Tree {
+	return t.combine(other, func(k string, a, b *leafNode) (interface{}, bool) {
+		switch {
+		case a == nil || b == nil:
+			return nil, false
+		case resolve != nil:
+			return resolve(k, a.val, b.val), true
+		}
+		return a.val, true
+	})
+}
+
+// Difference returns a new tree holding the entries of t whose keys
+// other doesn't hold
+func (t *Tree) Difference(other *Tree) *Tree {
+	return t.combine(other, func(k string, a, b *leafNode) (interface{}, bool) {
+		if a == nil || b != nil {
+			return nil, false
+		}
+		return a.val, true
+	})
+}
+
+// combine builds a tree with t's configuration from one ordered pass
+// over the entries of t and other. fn is given the leaf of each key in
+// either tree, or nil where a tree lacks the key, and returns the
+// value to keep, if any. Like a Clone, the result doesn't write to t's
+// store or log and runs no janitor. Its entries don't keep their
+// expiry, but get the tree's default TTL, if any.
+func (t *Tree) combine(other *Tree, fn func(k string, a, b *leafNode) (interface{}, bool)) *Tree {
+	if !sameCollation(t.collation, other.collation) {
+		panic("radix: trees have different collations")
+	}
+	d := t.derive()
+	d.store, d.wal = nil, nil
+	a := newAppender(d)
+	t.zip(other, func(k string, x, y *leafNode) error {
+		if v, ok := fn(k, x, y); ok {
+			a.add(k, v)
+		}
+		return nil
+	})
+	a.finish()
+	return d
+}
+
+// zip calls fn in key order for every key of t or other, which must
+// have the same collation, with the leaf of each tree holding it, or
+// nil where a tree lacks it. It stops at the first error fn returns.
+func (t *Tree) zip(other *Tree, fn func(k string, a, b *leafNode) error) error {
+	x, y := t.leaves.head, other.leaves.head
+	for x != nil || y != nil {
+		var lx, ly *leafNode
+		var k string
+		switch {
+		case y == nil:
+			lx, k, x = x, x.key, x.next
+		case x == nil:
+			ly, k, y = y, y.key, y.next
+		default:
+			switch c := t.collation.Compare(x.key, y.key); {
+			case c < 0:
+				lx, k, x = x, x.key, x.next
+			case c > 0:
+				ly, k, y = y, y.key, y.next
+			default:
+				lx, ly, k, x, y = x, y, x.key, x.next, y.next
+			}
+		}
+		if err := fn(k, lx, ly); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sameCollation reports whether a and b order keys the same way
+func sameCollation(a, b *Collation) bool {
+	return a == b || (a != nil && b != nil && *a == *b)
+}
@@ -0,0 +1,164 @@
+package radix
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestMerge(t *testing.T) {
+	a := New()
+	b := New()
+	for _, k := range []string{"a", "ab", "b"} {
+		a.Insert(k, 1)
+	}
+	for _, k := range []string{"ab", "abc", "c"} {
+		b.Insert(k, 10)
+	}
+	sum := func(k string, x, y interface{}) interface{} {
+		return x.(int) + y.(int)
+	}
+	if err := a.Merge(b, sum); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	want := map[string]interface{}{"a": 1, "ab": 11, "abc": 10, "b": 1, "c": 10}
+	if got := a.ToMap(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("bad: %v", got)
+	}
+	if b.Len() != 3 {
+		t.Fatalf("other was modified")
+	}
+
+	// A refused entry leaves the tree as it was
+	small := New(WithKeyPolicy(KeyPolicy{MaxLen: 2}))
+	small.Insert("a", 1)
+	if err := small.Merge(b, nil); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("bad: %v", err)
+	}
+	checkKeys(t, small, []string{"a"})
+	small = New(WithMemoryBudget(3 * entryOverhead))
+	small.Insert("a", 1)
+	if err := small.Merge(b, nil); err != ErrBudgetExceeded {
+		t.Fatalf("bad: %v", err)
+	}
+	checkKeys(t, small, []string{"a"})
+}
+
+func TestMerge_Structural(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	agg := WithAggregate(func(k string, v interface{}) float64 { return 1 })
+	for _, opts := range [][]Option{
+		nil,
+		{WithSmallTree(4)},
+		{WithSmallTree(100)},
+		{agg, WithAdaptiveNodes()},
+		{WithInsertionOrder(), WithClock(clock)},
+	} {
+		a, b := New(opts...), New()
+		want := make(map[string]interface{})
+		for i := 0; i < 100; i++ {
+			k := generateUUID()[:4]
+			a.Insert(k, i)
+			want[k] = i
+		}
+		for k := range want {
+			b.Insert(k, -1)
+			break
+		}
+		for i := 0; i < 100; i++ {
+			k := generateUUID()[:3]
+			b.Insert(k, i)
+		}
+		b.Walk(func(k string, v interface{}) bool {
+			want[k] = v
+			return false
+		})
+		a.InsertWithTTL("ttl", 1, time.Hour)
+		want["ttl"] = 1
+		union := a.Union(b, nil)
+
+		if err := a.Merge(b, nil); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(a.ToMap(), want) || !reflect.DeepEqual(a.ToMap(), union.ToMap()) {
+			t.Fatalf("bad merge")
+		}
+		if !a.smallMode {
+			validateTree(t, a.root)
+		}
+		if d, ok := a.TTL("ttl"); !ok || d < 59*time.Minute {
+			t.Fatalf("expected the expiry to be kept: %v %v", d, ok)
+		}
+		if a.aggFn != nil && a.root.agg().Count != len(want) {
+			t.Fatalf("bad aggregate: %v", a.root.agg())
+		}
+		if a.insertionOrder {
+			var n int
+			a.WalkInsertOrder(func(string, interface{}) bool {
+				n++
+				return false
+			})
+			if n != len(want) {
+				t.Fatalf("bad: %d", n)
+			}
+		}
+		a.Insert("zzz", 1)
+		if _, ok := a.Get("zzz"); !ok {
+			t.Fatalf("expected the tree to stay usable")
+		}
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	sum := func(k string, x, y interface{}) interface{} {
+		return x.(int) + y.(int)
+	}
+	for _, opts := range [][]Option{nil, {WithSmallTree(3)}} {
+		a := New(opts...)
+		b := New(opts...)
+		for _, k := range []string{"a", "ab", "b", "ba"} {
+			a.Insert(k, 1)
+		}
+		for _, k := range []string{"ab", "abc", "ba", "c"} {
+			b.Insert(k, 10)
+		}
+		for _, tc := range []struct {
+			got  *Tree
+			want map[string]interface{}
+		}{
+			{a.Union(b, sum), map[string]interface{}{"a": 1, "ab": 11, "abc": 10, "b": 1, "ba": 11, "c": 10}},
+			{a.Union(b, nil), map[string]interface{}{"a": 1, "ab": 10, "abc": 10, "b": 1, "ba": 10, "c": 10}},
+			{a.Intersect(b, nil), map[string]interface{}{"ab": 1, "ba": 1}},
+			{b.Intersect(a, sum), map[string]interface{}{"ab": 11, "ba": 11}},
+			{a.Difference(b), map[string]interface{}{"a": 1, "b": 1}},
+			{b.Difference(New()), b.ToMap()},
+		} {
+			if got := tc.got.ToMap(); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+			if !tc.got.smallMode {
+				validateTree(t, tc.got.root)
+			}
+			checkKeys(t, tc.got, sortedKeys(tc.want))
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected a panic")
+		}
+	}()
+	New().Union(New(WithCollation(CaseInsensitiveCollation())), nil)
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]interface{}) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package radix
+
+// mapAnon returns size bytes of zeroed memory outside the Go heap
+func mapAnon(size int) ([]byte, error) {
+	return nil, ErrOffHeapUnsupported
+}
+
+// unmapAnon releases memory obtained from mapAnon
+func unmapAnon(b []byte) error {
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package radix
+
+import "syscall"
+
+// mapAnon returns size bytes of zeroed memory outside the Go heap
+func mapAnon(size int) ([]byte, error) {
+	return syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+}
+
+// unmapAnon releases memory obtained from mapAnon
+func unmapAnon(b []byte) error {
+	return syscall.Munmap(b)
+}
@@ -0,0 +1,73 @@
+package radix
+
+// Multiset counts occurrences of strings in a radix tree, for
+// frequency tables that are also queried by prefix. A key is a member
+// while its count is positive, and is removed when its count drops to
+// zero.
+type Multiset struct {
+	t *Tree
+}
+
+// NewMultiset returns an empty multiset
+func NewMultiset(opts ...Option) *Multiset {
+	return &Multiset{t: New(opts...)}
+}
+
+// Tree returns the underlying tree, whose values are the counts as
+// ints
+func (m *Multiset) Tree() *Tree {
+	return m.t
+}
+
+// Incr adds delta, which may be negative, to the count of key and
+// returns the new count. Keys whose count reaches zero or less are
+// removed, and 0 is returned.
+func (m *Multiset) Incr(key string, delta int) int {
+	n := m.Count(key) + delta
+	if n <= 0 {
+		m.t.Delete(key)
+		return 0
+	}
+	m.t.Insert(key, n)
+	return n
+}
+
+// Count returns the count of key, or 0 if it is not a member
+func (m *Multiset) Count(key string) int {
+	if v, ok := m.t.Get(key); ok {
+		return v.(int)
+	}
+	return 0
+}
+
+// CountPrefix returns the sum of the counts of the keys starting with
+// prefix
+func (m *Multiset) CountPrefix(prefix string) int {
+	var n int
+	m.t.WalkPrefix(prefix, func(_ string, v interface{}) bool {
+		n += v.(int)
+		return false
+	})
+	return n
+}
+
+// Len returns the number of distinct members
+func (m *Multiset) Len() int {
+	return m.t.Len()
+}
+
+// Walk visits the members and their counts in order until fn returns
+// true
+func (m *Multiset) Walk(fn func(key string, count int) bool) {
+	m.t.Walk(func(k string, v interface{}) bool {
+		return fn(k, v.(int))
+	})
+}
+
+// WalkPrefix visits the members starting with prefix and their counts
+// in order until fn returns true
+func (m *Multiset) WalkPrefix(prefix string, fn func(key string, count int) bool) {
+	m.t.WalkPrefix(prefix, func(k string, v interface{}) bool {
+		return fn(k, v.(int))
+	})
+}
@@ -0,0 +1,38 @@
+package radix
+
+import "testing"
+
+func TestMultiset(t *testing.T) {
+	m := NewMultiset()
+	if n := m.Incr("foo", 2); n != 2 {
+		t.Fatalf("bad: %d", n)
+	}
+	m.Incr("foo", 1)
+	m.Incr("foobar", 5)
+	m.Incr("bar", 1)
+	if m.Count("foo") != 3 || m.Count("baz") != 0 || m.Len() != 3 {
+		t.Fatalf("bad: %v", m.Tree().ToMap())
+	}
+	if n := m.CountPrefix("foo"); n != 8 {
+		t.Fatalf("bad: %d", n)
+	}
+
+	// Dropping to zero or below removes the key
+	if n := m.Incr("foo", -3); n != 0 {
+		t.Fatalf("bad: %d", n)
+	}
+	if n := m.Incr("bar", -5); n != 0 {
+		t.Fatalf("bad: %d", n)
+	}
+	if n := m.Incr("baz", -1); n != 0 {
+		t.Fatalf("bad: %d", n)
+	}
+	var keys []string
+	m.Walk(func(k string, n int) bool {
+		keys = append(keys, k)
+		return false
+	})
+	if len(keys) != 1 || keys[0] != "foobar" {
+		t.Fatalf("bad: %v", keys)
+	}
+}
@@ -0,0 +1,113 @@
+package radix
+
+import "sort"
+
+// NaturalCompare compares a and b in natural order, where runs of
+// ASCII digits compare by their numeric value, so "item2" sorts
+// before "item10". Everything else compares byte by byte. It returns
+// 0 if a == b, -1 if a < b and +1 if a > b.
+func NaturalCompare(a, b string) int {
+	return naturalCompare(nil, a, b)
+}
+
+// naturalCompare is NaturalCompare with non-digit bytes ordered by c
+func naturalCompare(c *Collation, a, b string) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			// Compare the runs by value: skip leading zeros, then
+			// the longer run is larger, else the first difference
+			// decides
+			ai, bj := skipZeros(a, i), skipZeros(b, j)
+			ae, be := digitsEnd(a, ai), digitsEnd(b, bj)
+			if d := (ae - ai) - (be - bj); d != 0 {
+				return sign(d)
+			}
+			for ai < ae {
+				if a[ai] != b[bj] {
+					return sign(int(a[ai]) - int(b[bj]))
+				}
+				ai++
+				bj++
+			}
+			i, j = ae, be
+			continue
+		}
+		if a[i] != b[j] {
+			if c.less(a[i], b[j]) {
+				return -1
+			}
+			return 1
+		}
+		i++
+		j++
+	}
+	switch {
+	case len(a)-i < len(b)-j:
+		return -1
+	case len(a)-i > len(b)-j:
+		return 1
+	}
+
+	// Equal but for leading zeros, so fall back to a total order
+	return c.Compare(a, b)
+}
+
+func isDigit(b byte) bool {
+	return '0' <= b && b <= '9'
+}
+
+// skipZeros returns the index of the first significant digit of the
+// run starting at i, keeping a final zero
+func skipZeros(s string, i int) int {
+	for i+1 < len(s) && s[i] == '0' && isDigit(s[i+1]) {
+		i++
+	}
+	return i
+}
+
+// digitsEnd returns the index just past the run of digits at i
+func digitsEnd(s string, i int) int {
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return i
+}
+
+func sign(d int) int {
+	switch {
+	case d < 0:
+		return -1
+	case d > 0:
+		return 1
+	}
+	return 0
+}
+
+// walk visits n and everything under it in the tree's walk order,
+// returning true if aborted
+func (t *Tree) walk(n *node, fn WalkFn) bool {
+	if !t.naturalOrder {
+		return recursiveWalk(n, fn)
+	}
+
+	// The structure can't capture numeric order, so sort a snapshot
+	var leaves []*leafNode
+	forEachLeaf(n, func(l *leafNode) {
+		leaves = append(leaves, l)
+	})
+	t.sortNatural(leaves)
+	for _, l := range leaves {
+		if fn(l.key, l.val) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortNatural sorts leaves by key in natural order
+func (t *Tree) sortNatural(leaves []*leafNode) {
+	sort.Slice(leaves, func(i, j int) bool {
+		return naturalCompare(t.collation, leaves[i].key, leaves[j].key) < 0
+	})
+}
@@ -0,0 +1,81 @@
+package radix
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestNaturalCompare(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"item2", "item10", -1},
+		{"item10", "item10", 0},
+		{"item10", "item9x", 1},
+		{"a1b2", "a1b10", -1},
+		{"a007", "a7", -1},
+		{"a7", "a007", 1},
+		{"a7b", "a007c", -1},
+		{"x", "x0", -1},
+		{"10", "9a", 1},
+		{"abc", "abd", -1},
+		{"0", "00", -1},
+	}
+	for _, tc := range cases {
+		if got := NaturalCompare(tc.a, tc.b); got != tc.want {
+			t.Fatalf("NaturalCompare(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestNaturalOrder(t *testing.T) {
+	keys := []string{
+		"file1", "file2", "file10", "file20", "file100",
+		"file2a", "file2b", "img", "img3", "img12",
+	}
+	r := New(WithNaturalOrder())
+	for i, k := range keys {
+		r.Insert(k, i)
+	}
+	want := append([]string(nil), keys...)
+	sort.Slice(want, func(i, j int) bool {
+		return NaturalCompare(want[i], want[j]) < 0
+	})
+
+	var got []string
+	r.Walk(func(k string, v interface{}) bool {
+		got = append(got, k)
+		return false
+	})
+	if len(got) != len(want) {
+		t.Fatalf("bad: %q", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("mis-match: %q %q", got, want)
+		}
+	}
+	if want[2] != "file2a" || want[4] != "file10" {
+		t.Fatalf("bad order: %q", want)
+	}
+
+	got = got[:0]
+	r.WalkPrefix("img", func(k string, v interface{}) bool {
+		got = append(got, k)
+		return len(got) == 2
+	})
+	if len(got) != 2 || got[0] != "img" || got[1] != "img3" {
+		t.Fatalf("bad: %q", got)
+	}
+
+	// Entries may be deleted while walking
+	r.Walk(func(k string, v interface{}) bool {
+		r.Delete(k)
+		return false
+	})
+	if r.Len() != 0 {
+		t.Fatalf("bad: %d", r.Len())
+	}
+}
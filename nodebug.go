@@ -0,0 +1,7 @@
+//go:build !radixdebug
+
+package radix
+
+// debug enables invariant checks after every mutation. It is
+// switched on with the radixdebug build tag.
+const debug = false
@@ -0,0 +1,9 @@
+package radix
+
+// norm returns the normalized form of the key s
+func (t *Tree) norm(s string) string {
+	if t.normalize == nil {
+		return s
+	}
+	return t.normalize(s)
+}
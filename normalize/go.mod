@@ -0,0 +1,11 @@
+module github.com/armon/go-radix/normalize
+
+go 1.18
+
+require (
+	github.com/armon/go-radix v0.0.0
+	golang.org/x/net v0.17.0
+	golang.org/x/text v0.13.0
+)
+
+replace github.com/armon/go-radix => ../
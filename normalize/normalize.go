@@ -0,0 +1,67 @@
+// Package normalize provides key normalizers for radix trees, to be
+// installed with radix.WithKeyNormalizer. It is a module of its own,
+// so that the radix module doesn't depend on golang.org/x/text and
+// golang.org/x/net.
+package normalize
+
+import (
+	"strings"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Form is a Unicode normalization form for keys, see Unicode
+type Form int
+
+const (
+	// NFC composes characters, so that canonically equivalent
+	// keys, such as "é" written as one code point or as "e" and a
+	// combining accent, are the same key.
+	NFC Form = iota + 1
+
+	// NFKC also folds compatibility characters, such as the "ﬁ"
+	// ligature or full-width letters, into their plain equivalents.
+	NFKC
+)
+
+// Unicode returns a normalizer to the given form, which also case
+// folds keys if fold is set, so visually identical keys aren't stored
+// as distinct entries:
+//
+//	r := radix.New(radix.WithKeyNormalizer(normalize.Unicode(normalize.NFKC, true)))
+func Unicode(form Form, fold bool) func(key string) string {
+	var f norm.Form
+	switch form {
+	case NFC:
+		f = norm.NFC
+	case NFKC:
+		f = norm.NFKC
+	default:
+		panic("normalize: unknown form")
+	}
+	if !fold {
+		return f.String
+	}
+	return func(s string) string {
+		// Folding can denormalize, so normalize again after.
+		// Casers are stateful, so each call needs its own.
+		return f.String(cases.Fold().String(f.String(s)))
+	}
+}
+
+// Domain treats keys as host names, converting them to their ASCII
+// form and mapping case and equivalent characters the way lookups do,
+// so "bücher.de" and "xn--bcher-kva.de" are the same key. Keys that
+// aren't valid host names are just lower cased. A prefix is converted
+// label by label, so prefixes should end at a label boundary, as in
+// "bücher.":
+//
+//	r := radix.New(radix.WithKeyNormalizer(normalize.Domain))
+func Domain(key string) string {
+	if a, err := idna.Lookup.ToASCII(key); err == nil {
+		return a
+	}
+	return strings.ToLower(key)
+}
@@ -0,0 +1,79 @@
+package normalize_test
+
+import (
+	"reflect"
+	"testing"
+
+	radix "github.com/armon/go-radix"
+	"github.com/armon/go-radix/normalize"
+)
+
+func keys(r *radix.Tree) []string {
+	var out []string
+	r.Walk(func(k string, v interface{}) bool {
+		out = append(out, k)
+		return false
+	})
+	return out
+}
+
+func TestUnicode(t *testing.T) {
+	composed, decomposed := "caf\u00e9", "cafe\u0301"
+
+	r := radix.New(radix.WithKeyNormalizer(normalize.Unicode(normalize.NFC, false)))
+	r.Insert(composed, 1)
+	if old, ok := r.Insert(decomposed, 2); !ok || old != 1 || r.Len() != 1 {
+		t.Fatalf("equivalent keys should be the same entry")
+	}
+	if got := keys(r); !reflect.DeepEqual(got, []string{composed}) {
+		t.Fatalf("bad: %q", got)
+	}
+	if v, ok := r.Get(decomposed); !ok || v != 2 {
+		t.Fatalf("bad: %v %v", v, ok)
+	}
+	if k, _, ok := r.LongestPrefix(decomposed + "s"); !ok || k != composed {
+		t.Fatalf("bad: %q", k)
+	}
+	if _, ok := r.Delete(decomposed); !ok || r.Len() != 0 {
+		t.Fatalf("bad")
+	}
+
+	// Compatibility characters and case
+	r = radix.New(radix.WithKeyNormalizer(normalize.Unicode(normalize.NFKC, true)))
+	r.Insert("\ufb01le", 1) // ligature
+	for _, k := range []string{"file", "FILE", "\uff26\uff29\uff2c\uff25"} {
+		if _, ok := r.Get(k); !ok {
+			t.Fatalf("missing %q", k)
+		}
+	}
+
+	// Offsets found in a text index into it as given
+	r.Insert("x", 2)
+	text := "\ufb01x"
+	if m := r.FindAll(text); len(m) != 1 || text[m[0].Start:m[0].End] != "x" {
+		t.Fatalf("bad: %+v", m)
+	}
+	if n := r.DeletePrefix("FI"); n != 1 {
+		t.Fatalf("bad: %d", n)
+	}
+}
+
+func TestDomain(t *testing.T) {
+	r := radix.New(radix.WithKeyNormalizer(normalize.Domain))
+	r.Insert("bücher.de", 1)
+	r.Insert("Example.COM", 2)
+	if got := keys(r); !reflect.DeepEqual(got, []string{"example.com", "xn--bcher-kva.de"}) {
+		t.Fatalf("bad: %q", got)
+	}
+	for _, k := range []string{"xn--bcher-kva.de", "BÜCHER.de", "bücher.de"} {
+		if v, ok := r.Get(k); !ok || v != 1 {
+			t.Fatalf("missing %q", k)
+		}
+	}
+
+	// Names that don't convert are still stored
+	r.Insert("not a host!", 3)
+	if _, ok := r.Get("NOT A HOST!"); !ok {
+		t.Fatalf("bad")
+	}
+}
@@ -0,0 +1,56 @@
+package radix
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeyNormalizer(t *testing.T) {
+	r := New(
+		WithKeyNormalizer(strings.TrimSpace),
+		WithKeyNormalizer(strings.ToLower),
+	)
+	r.Insert(" Foo ", 1)
+	r.Insert("bar", 2)
+	checkKeys(t, r, []string{"bar", "foo"})
+	if _, ok := r.Get("FOO"); !ok {
+		t.Fatalf("bad")
+	}
+	var keys []string
+	r.WalkPrefixRelative(" F", func(k string, v interface{}) bool {
+		keys = append(keys, k)
+		return false
+	})
+	if len(keys) != 1 || keys[0] != "oo" {
+		t.Fatalf("bad: %v", keys)
+	}
+	if m := r.LongestPrefixMany([]string{"FOOD"}); !m[0].Found || m[0].Key != "foo" {
+		t.Fatalf("bad: %+v", m)
+	}
+	parts := r.Partition([]string{"F"})
+	checkKeys(t, parts["F"], []string{"foo"})
+
+	// Loading goes through the normalizer too
+	r = New(WithKeyNormalizer(strings.ToLower))
+	r.LoadParallel(map[string]interface{}{"A": 1, "a": 1, "B": 2}, 2)
+	checkKeys(t, r, []string{"a", "b"})
+}
+
+func TestKeyNormalizer_Scans(t *testing.T) {
+	// Texts are scanned as given, so offsets index into them
+	r := New(WithKeyNormalizer(strings.NewReplacer("\ufb01", "fi").Replace))
+	r.Insert("\ufb01", 1)
+	r.Insert("x", 2)
+	checkKeys(t, r, []string{"fi", "x"})
+	text := "\ufb01x"
+	if m := r.FindAll(text); len(m) != 1 || m[0].Start != 3 || m[0].End != 4 {
+		t.Fatalf("bad: %+v", m)
+	}
+	if _, ok := r.ContainsAny(text[:3]); ok {
+		t.Fatalf("unexpected match")
+	}
+	toks := r.Segment(text)
+	if len(toks) != 2 || toks[0].Text != "\ufb01" || toks[0].Known || toks[1].Start != 3 || !toks[1].Known {
+		t.Fatalf("bad: %+v", toks)
+	}
+}
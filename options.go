@@ -0,0 +1,388 @@
+package radix
+
+import (
+	"sync"
+	"time"
+)
+
+// Option configures optional behaviour of a Tree. Options are
+// passed to New or NewFromMap.
+type Option func(*Tree)
+
+// WithDenseDispatch gives every node that branches on one of the
+// first depth bytes of a key a 256-entry dispatch table, so those
+// lookups are a direct index instead of a search. A depth of 1
+// covers just the root. Each indexed node costs about 2KB, so this
+// is meant for the few hot, high-fanout levels near the root.
+func WithDenseDispatch(depth int) Option {
+	return func(t *Tree) {
+		t.denseDepth = depth
+	}
+}
+
+// WithAdaptiveNodes lets every node pick how its edges are looked up
+// from its fanout, switching between a search of the sorted edges, a
+// bitmap of the labels present and a dense dispatch table as edges
+// come and go, so the representation needn't be chosen up front. It
+// takes precedence over WithDenseDispatch.
+func WithAdaptiveNodes() Option {
+	return func(t *Tree) {
+		t.adaptive = true
+	}
+}
+
+// WithKeyNormalizer passes every key given to the tree through fn
+// before it is stored or looked up, so keys that fn maps to the same
+// string are the same key. Keys are stored and reported in their
+// normalized form. fn must be idempotent, and where it is applied to
+// a prefix, as by WalkPrefix, it should map prefixes of a key to
+// prefixes of the key's normalized form. Texts scanned for keys, as
+// by FindAll, Segment and ContainsAny, are not normalized, so offsets
+// into them stay valid; only the stored keys are. Normalizers given
+// in several options are applied in order. The normalize package has
+// ready-made ones for Unicode normalization forms and host names.
+func WithKeyNormalizer(fn func(key string) string) Option {
+	return func(t *Tree) {
+		if prev := t.normalize; prev != nil {
+			t.normalize = func(key string) string {
+				return fn(prev(key))
+			}
+			return
+		}
+		t.normalize = fn
+	}
+}
+
+// WithLFU counts the Gets that find each entry, see Hits. If capacity
+// is positive, the tree holds at most that many entries: storing a
+// new key in a full tree first evicts the least frequently used
+// entry, with ties broken arbitrarily. Unlike evicting the least
+// recently used, this keeps popular entries through scans of
+// entries that are read once.
+func WithLFU(capacity int) Option {
+	return func(t *Tree) {
+		t.countHits = true
+		t.lfuCap = capacity
+	}
+}
+
+// WithPrefixProfiler samples one in every lookups made by Get and
+// LongestPrefix, counting the first depth bytes of the key looked up,
+// so HotPrefixes can report the busiest parts of the tree. That helps
+// find candidates for a dedicated cache or for WithDenseDispatch.
+// The profiler counts at most 1024 distinct prefixes, so its memory
+// is fixed: past that, a new prefix takes the place of the least
+// sampled one. Trees derived from this one, such as the results of
+// Split, share the profile.
+func WithPrefixProfiler(every, depth int) Option {
+	if every <= 0 || depth <= 0 {
+		panic("radix: profiler rate and depth must be positive")
+	}
+	return func(t *Tree) {
+		t.profile = newPrefixProfile(every, depth)
+	}
+}
+
+// WithKeyPolicy makes the tree refuse keys that break p, as checked
+// after any normalization. TryInsert returns a *KeyError for such a
+// key, and Insert silently drops it, so keys that would later break
+// exports or separator logic are caught where they come in.
+func WithKeyPolicy(p KeyPolicy) Option {
+	return func(t *Tree) {
+		t.keyPolicy = newKeyPolicy(p)
+	}
+}
+
+// WithHistory keeps every version of every key, each stamped with the
+// Generation that produced it, so GetAt and WalkPrefixAt can read the
+// tree as it was at an earlier revision. Deleted keys keep their
+// history. Entries moved between trees by Split or Partition are not
+// recorded. History grows with every change, unless bounded with
+// WithHistoryRetention or CompactHistory, and is not counted by
+// BytesUsed.
+func WithHistory() Option {
+	return func(t *Tree) {
+		t.keepHistory = true
+	}
+}
+
+// WithHistoryRetention bounds the history kept by WithHistory, which
+// it implies, to the last versions versions of each key and to those
+// in effect at most maxAge ago, as told by the tree's clock; zero
+// means no limit. Limits are applied to a key's history when the key
+// changes, so the history of keys left alone is only reclaimed by
+// CompactHistory.
+func WithHistoryRetention(versions int, maxAge time.Duration) Option {
+	return func(t *Tree) {
+		t.keepHistory = true
+		t.historyVersions = versions
+		t.historyAge = maxAge
+	}
+}
+
+// WithValueCodec sets how MarshalJSON, UnmarshalJSON, GobEncode and
+// GobDecode convert values, so that values of a known type come back
+// as that type rather than as whatever the encoding decodes to by
+// default. For JSON, c must produce JSON, as JSONCodec does.
+func WithValueCodec(c ValueCodec) Option {
+	return func(t *Tree) {
+		t.valueCodec = c
+	}
+}
+
+// WithListingDelimiter declares that keys are paths of segments
+// separated by sep, as object storage keys are by '/', for
+// WalkChildren to list a level at a time. It only affects listing:
+// nodes still split wherever keys diverge, including in the middle of
+// a segment. For a tree whose nodes only split at segment boundaries,
+// see PathTree.
+func WithListingDelimiter(sep byte) Option {
+	return func(t *Tree) {
+		t.delim, t.hasDelim = sep, true
+	}
+}
+
+// WithBorrowedKeys makes InsertBytes store a reference to the
+// caller's byte slice as the key instead of a copy, saving an
+// allocation per insert. The caller must then never modify a slice
+// once it has been inserted, since that would change a stored key
+// behind the tree's back and corrupt its order. By default keys are
+// copied, and buffers may be reused freely.
+func WithBorrowedKeys() Option {
+	return func(t *Tree) {
+		t.borrowKeys = true
+	}
+}
+
+// WithMemoryBudget caps the approximate memory, as reported by
+// BytesUsed, that the tree's entries may hold. TryInsert returns
+// ErrBudgetExceeded for an entry that does not fit, and Insert
+// silently drops it.
+func WithMemoryBudget(bytes int) Option {
+	return func(t *Tree) {
+		t.budget = bytes
+	}
+}
+
+// WithValueSizer sets a function used to estimate the size of
+// values, so that BytesUsed and the memory budget account for them
+// as well as for the keys.
+func WithValueSizer(fn func(v interface{}) int) Option {
+	return func(t *Tree) {
+		t.sizer = fn
+	}
+}
+
+// WithInsertionOrder threads the entries on a list in the order they
+// were inserted, so they can also be visited in arrival order with
+// WalkInsertOrder. It costs two pointers per entry.
+func WithInsertionOrder() Option {
+	return func(t *Tree) {
+		t.insertionOrder = true
+	}
+}
+
+// WithAggregate maintains a summary (count, sum, minimum and maximum)
+// of the numbers fn derives from each entry, for every subtree. It is
+// updated along the modified path on every mutation, so Aggregate can
+// answer for any prefix in time proportional to its depth.
+func WithAggregate(fn func(key string, v interface{}) float64) Option {
+	return func(t *Tree) {
+		t.aggFn = fn
+	}
+}
+
+// WithLeafCounts maintains the number of entries under every node,
+// kept up to date along the path of each mutation, so ChildCounts and
+// GroupCount count the entries below a separator without visiting them.
+// WithAggregate maintains the counts too.
+func WithLeafCounts() Option {
+	return func(t *Tree) {
+		t.leafCounts = true
+	}
+}
+
+// WithLoader turns the tree into a read-through cache: when Get misses,
+// fn is asked for the value, which is inserted and returned if found.
+// Since Get may then modify the tree, concurrent Gets need the same
+// exclusive locking as any other mutation. A SafeTree instead calls fn
+// outside its lock, once for all the Gets that miss the same key at
+// the same time.
+func WithLoader(fn LoaderFunc) Option {
+	return func(t *Tree) {
+		t.loader = fn
+	}
+}
+
+// WithNegativeCache remembers keys the loader reported as missing for
+// the given duration, so repeated lookups of nonexistent keys don't
+// reach the loader. Inserting such a key clears it from the cache.
+func WithNegativeCache(ttl time.Duration) Option {
+	return func(t *Tree) {
+		t.negativeTTL = ttl
+	}
+}
+
+// WithStore writes every mutation through to s synchronously, as it
+// is applied to the tree. Entries moved between trees by Split or
+// Partition are not written. Failed writes are passed to onErr as a
+// *StoreError, and do not undo the change to the tree.
+func WithStore(s Store, onErr func(error)) Option {
+	return func(t *Tree) {
+		t.store = newStoreWriter(s, onErr, false, 0)
+	}
+}
+
+// WithAsyncStore is like WithStore, but writes are queued, in order,
+// to a background goroutine. Mutations block only while the queue of
+// queueSize writes is full. The tree must be closed with Close to
+// flush the queue and stop the goroutine.
+func WithAsyncStore(s Store, onErr func(error), queueSize int) Option {
+	return func(t *Tree) {
+		t.store = newStoreWriter(s, onErr, true, queueSize)
+	}
+}
+
+// WithJanitor evicts expired entries from a background goroutine every
+// interval. Each sweep holds lock, which must be the lock guarding
+// every other use of the tree, and stops after evicting batch entries
+// or after running for maxTime, whichever comes first; zero means no
+// limit. Entries left over are picked up by later sweeps. The tree
+// must be closed with Close to stop the goroutine. Trees derived by
+// Split or Partition run a janitor of their own, with the same
+// settings and holding the same lock, and must be closed as well.
+func WithJanitor(interval time.Duration, batch int, maxTime time.Duration, lock sync.Locker) Option {
+	if interval <= 0 {
+		panic("radix: janitor interval must be positive")
+	}
+	if lock == nil {
+		panic("radix: janitor needs a lock")
+	}
+	return func(t *Tree) {
+		t.janitor = &janitor{
+			interval: interval,
+			batch:    batch,
+			maxTime:  maxTime,
+			lock:     lock,
+		}
+	}
+}
+
+// WithClock makes the tree tell the time with c instead of the system
+// clock, for TTLs and the negative cache. Tests can use it to advance
+// time deterministically. The janitor's interval is still measured in
+// real time.
+func WithClock(c Clock) Option {
+	return func(t *Tree) {
+		t.clock = c
+	}
+}
+
+// WithCollation orders keys by c rather than by their bytes. It
+// applies to iteration, Minimum and Maximum, Successor and
+// Predecessor, Split and AggregateRange alike. It panics if c is not
+// a permutation.
+func WithCollation(c *Collation) Option {
+	c.validate()
+	return func(t *Tree) {
+		t.collation = c
+	}
+}
+
+// WithNaturalOrder makes Walk and WalkPrefix visit keys in natural
+// order, as defined by NaturalCompare, so listings of keys such as
+// "item2" and "item10" come out in human order. Since numeric order
+// can't be read off the structure, each walk sorts the entries it
+// visits, taking memory proportional to their number. Other ordered
+// operations, such as Minimum, Successor and Split, keep the byte or
+// collation order.
+func WithNaturalOrder() Option {
+	return func(t *Tree) {
+		t.naturalOrder = true
+	}
+}
+
+// WithBackend logs every mutation to b, with values encoded by c, so
+// the tree can be rebuilt with Recover after a restart. Checkpoint
+// writes a snapshot to b and empties the log. Entries moved between
+// trees by Split or Partition are not logged. Failed appends are
+// passed to onErr as a *StoreError, and do not undo the change to the
+// tree.
+func WithBackend(b Backend, c ValueCodec, onErr func(error)) Option {
+	return func(t *Tree) {
+		w := &wal{backend: b, codec: c, onErr: onErr}
+		w.writer = newStoreWriter(walStore{w}, onErr, false, 0)
+		t.wal = w
+	}
+}
+
+// WithGroupCommit makes a tree created with WithBackend append its log
+// in batches, each made durable with a single sync of the backend. A
+// mutation waits at most maxLatency for its batch to fill up to
+// maxBatch records, or without limit if maxBatch is zero. Writers that
+// call Sync after releasing the tree's lock then share the cost of
+// syncing rather than being capped at the disk's sync rate. The tree
+// must be closed with Close to commit the last batch and stop the
+// goroutine.
+func WithGroupCommit(maxLatency time.Duration, maxBatch int) Option {
+	return func(t *Tree) {
+		t.commitLatency = maxLatency
+		t.commitBatch = maxBatch
+	}
+}
+
+// WithDurability sets when a tree created with WithBackend syncs its
+// log: never on its own, asynchronously, every n mutations, or on
+// every mutation. The default is DurabilityNone, or DurabilityAsync if
+// WithGroupCommit is used. With group commit, a mutation that has to
+// be synced waits for its batch to be committed. Failed syncs are
+// passed to the backend's onErr.
+func WithDurability(d Durability) Option {
+	return func(t *Tree) {
+		t.durability = d
+	}
+}
+
+// WithSlowOpWarning calls fn whenever Walk, WalkPrefix or DeletePrefix
+// takes threshold or longer, to surface accidental scans of the whole
+// tree. Time is measured with the tree's clock, see WithClock.
+func WithSlowOpWarning(threshold time.Duration, fn SlowOpFunc) Option {
+	return func(t *Tree) {
+		t.slowThreshold = threshold
+		t.slowFn = fn
+	}
+}
+
+// WithCapacity sizes the tree for about n entries up front, allocating
+// their leaves and nodes in a few large blocks instead of one by one,
+// which cuts allocations and fragmentation during a large initial
+// load. A block is only freed once every entry in it has been
+// deleted, so deleting most entries doesn't return their memory, and
+// n should not be much larger than the number of entries the tree
+// will hold. After the first delete, new entries are allocated one by
+// one again rather than from what is left of the blocks. Clone copies
+// the entries into blocks of their own, which lets the original's be
+// freed. It panics if n is negative.
+func WithCapacity(n int) Option {
+	if n < 0 {
+		panic("radix: capacity must not be negative")
+	}
+	return func(t *Tree) {
+		t.capacity = n
+	}
+}
+
+// WithSmallTree keeps the entries of a tree holding at most max of
+// them in a sorted slice instead of nodes, which saves the node
+// overhead when there are many tiny trees. The tree is promoted to
+// nodes once it grows past max, and stays that way. Operations that
+// need nodes, such as Aggregate and Split, promote it regardless of
+// its size.
+func WithSmallTree(max int) Option {
+	if max < 0 {
+		panic("radix: small tree max must not be negative")
+	}
+	return func(t *Tree) {
+		t.smallMax = max
+	}
+}
@@ -0,0 +1,67 @@
+package radix
+
+// orderList threads leaves in the order they were inserted
+type orderList struct {
+	oldest, newest *leafNode
+}
+
+// push appends l as the newest leaf
+func (o *orderList) push(l *leafNode) {
+	x := l.x()
+	x.older, x.newer = o.newest, nil
+	if o.newest != nil {
+		o.newest.ext.newer = l
+	} else {
+		o.oldest = l
+	}
+	o.newest = l
+}
+
+// remove unlinks l from the list
+func (o *orderList) remove(l *leafNode) {
+	x := l.ext
+	if x.older != nil {
+		x.older.ext.newer = x.newer
+	} else {
+		o.oldest = x.newer
+	}
+	if x.newer != nil {
+		x.newer.ext.older = x.older
+	} else {
+		o.newest = x.older
+	}
+	x.older, x.newer = nil, nil
+}
+
+// WalkInsertOrder walks the tree in the order keys were first
+// inserted, oldest first. Updating the value of an existing key
+// does not move it. The tree must have been created with
+// WithInsertionOrder.
+func (t *Tree) WalkInsertOrder(fn WalkFn) {
+	if !t.insertionOrder {
+		panic("radix: WalkInsertOrder requires WithInsertionOrder")
+	}
+	for l := t.order.oldest; l != nil; {
+		// Grab the next leaf first, fn may delete this one
+		next := l.ext.newer
+		if fn(l.key, l.val) {
+			return
+		}
+		l = next
+	}
+}
+
+// redistributeOrder moves every leaf on t's insertion order list
+// onto the list of the tree chosen by dest, preserving order.
+func (t *Tree) redistributeOrder(dest func(key string) *Tree) {
+	if !t.insertionOrder {
+		return
+	}
+	l := t.order.oldest
+	t.order = orderList{}
+	for l != nil {
+		next := l.ext.newer
+		dest(l.key).order.push(l)
+		l = next
+	}
+}
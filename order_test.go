@@ -0,0 +1,67 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWalkInsertOrder(t *testing.T) {
+	r := New(WithInsertionOrder())
+	for _, k := range []string{"zip", "foo", "", "foobar", "bar", "foob"} {
+		r.Insert(k, k)
+	}
+	r.Insert("foo", "updated")
+	r.Delete("bar")
+	r.DeletePrefix("foob")
+	r.Insert("bar", "again")
+
+	var out []string
+	r.WalkInsertOrder(func(k string, v interface{}) bool {
+		out = append(out, k)
+		return false
+	})
+	if want := []string{"zip", "foo", "", "bar"}; !reflect.DeepEqual(out, want) {
+		t.Fatalf("mis-match: %q %q", out, want)
+	}
+
+	// Early termination, and deleting while walking
+	out = nil
+	r.WalkInsertOrder(func(k string, v interface{}) bool {
+		out = append(out, k)
+		r.Delete(k)
+		return len(out) == 2
+	})
+	if want := []string{"zip", "foo"}; !reflect.DeepEqual(out, want) {
+		t.Fatalf("mis-match: %q %q", out, want)
+	}
+	if r.Len() != 2 {
+		t.Fatalf("bad len: %d", r.Len())
+	}
+}
+
+func TestWalkInsertOrder_Split(t *testing.T) {
+	r := New(WithInsertionOrder())
+	for _, k := range []string{"d", "a", "e", "b", "f", "c", "ab"} {
+		r.Insert(k, nil)
+	}
+	left, right := r.Split("c")
+	checkInsertOrder(t, left, []string{"a", "b", "ab"})
+	checkInsertOrder(t, right, []string{"d", "e", "f", "c"})
+
+	parts := right.Partition([]string{"e", "f"})
+	checkInsertOrder(t, parts["e"], []string{"e"})
+	checkInsertOrder(t, parts["f"], []string{"f"})
+	checkInsertOrder(t, right, []string{"d", "c"})
+}
+
+func checkInsertOrder(t *testing.T, r *Tree, want []string) {
+	t.Helper()
+	var out []string
+	r.WalkInsertOrder(func(k string, v interface{}) bool {
+		out = append(out, k)
+		return false
+	})
+	if !reflect.DeepEqual(out, want) {
+		t.Fatalf("mis-match: %q %q", out, want)
+	}
+}
@@ -0,0 +1,74 @@
+package radix
+
+import "strings"
+
+// Pack rebuilds the tree's nodes, edges and prefixes into a few large
+// contiguous arrays laid out in walk order, improving locality after
+// a long build phase of scattered allocations. Entries are untouched.
+// The tree stays fully mutable: nodes added later are allocated as
+// usual, and a node whose edges grow gets a fresh edge array, leaving
+// the packed one behind. Small trees have no nodes to pack.
+func (t *Tree) Pack() {
+	if t.smallMode {
+		return
+	}
+	var numNodes, numEdges, prefixBytes int
+	var count func(n *node)
+	count = func(n *node) {
+		numNodes++
+		numEdges += len(n.edges)
+		prefixBytes += len(n.prefix)
+		for _, e := range n.edges {
+			count(e.node)
+		}
+	}
+	count(t.root)
+
+	p := &packer{
+		nodes:   make([]node, 0, numNodes),
+		edges:   make([]edge, 0, numEdges),
+		offsets: make([]int, 0, numNodes),
+	}
+	p.prefixes.Grow(prefixBytes)
+	t.root = p.pack(t.root)
+
+	// Point the prefixes into the packed string
+	data := p.prefixes.String()
+	for i := range p.nodes {
+		off := p.offsets[i]
+		p.nodes[i].prefix = data[off : off+len(p.nodes[i].prefix)]
+	}
+}
+
+// packer accumulates the arrays built by Pack
+type packer struct {
+	nodes    []node
+	edges    []edge
+	prefixes strings.Builder
+
+	// offsets holds the offset of each node's prefix in prefixes
+	offsets []int
+}
+
+// pack copies n into the packed arrays, followed by its children,
+// returning the copy
+func (p *packer) pack(n *node) *node {
+	p.nodes = append(p.nodes, *n)
+	c := &p.nodes[len(p.nodes)-1]
+	p.offsets = append(p.offsets, p.prefixes.Len())
+	p.prefixes.WriteString(n.prefix)
+
+	// Reserve the edges before recursing, capping their capacity so
+	// that adding an edge later copies them out of the shared array
+	start := len(p.edges)
+	p.edges = append(p.edges, n.edges...)
+	c.edges = p.edges[start:len(p.edges):len(p.edges)]
+	for i := range c.edges {
+		child := p.pack(c.edges[i].node)
+		c.edges[i].node = child
+		if d := c.dense(); d != nil {
+			d[c.edges[i].label] = child
+		}
+	}
+	return c
+}
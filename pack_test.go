@@ -0,0 +1,66 @@
+package radix
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPack(t *testing.T) {
+	r := New(WithDenseDispatch(2))
+	inp := make(map[string]interface{})
+	for i := 0; i < 1000; i++ {
+		inp[generateUUID()] = i
+	}
+	inp[""] = -1
+	for k, v := range inp {
+		r.Insert(k, v)
+	}
+	r.Pack()
+	validateTree(t, r.root)
+	if r.Len() != len(inp) {
+		t.Fatalf("bad len: %d", r.Len())
+	}
+	for k, v := range inp {
+		if out, ok := r.Get(k); !ok || out != v {
+			t.Fatalf("bad: %v %v %v", k, out, v)
+		}
+	}
+
+	// The packed tree stays mutable
+	i := 0
+	for k := range inp {
+		if i%2 == 0 {
+			r.Delete(k)
+			delete(inp, k)
+		}
+		i++
+	}
+	for i := 0; i < 500; i++ {
+		k := fmt.Sprintf("%s-%d", generateUUID(), i)
+		r.Insert(k, i)
+		inp[k] = i
+	}
+	validateTree(t, r.root)
+	if r.Len() != len(inp) {
+		t.Fatalf("bad len: %d", r.Len())
+	}
+	for k, v := range inp {
+		if out, ok := r.Get(k); !ok || out != v {
+			t.Fatalf("bad: %v %v %v", k, out, v)
+		}
+	}
+}
+
+func TestPack_SharedEdges(t *testing.T) {
+	r := New()
+	for _, k := range []string{"a", "b", "ca", "cb"} {
+		r.Insert(k, k)
+	}
+	r.Pack()
+
+	// Growing the root's edges must not clobber its children's
+	r.Insert("d", "d")
+	r.Insert("cc", "cc")
+	validateTree(t, r.root)
+	checkKeys(t, r, []string{"a", "b", "ca", "cb", "cc", "d"})
+}
@@ -0,0 +1,363 @@
+package radix
+
+import "strings"
+
+// Children lists what lies immediately below prefix when keys are
+// paths of segments separated by sep, like a directory listing. Each
+// result is relative to prefix: a key with no further separator is
+// listed in full, and keys that continue past one are summarised by
+// the segment up to and including it, once. For the keys "a/b",
+// "a/c/d" and "a/c/e", the children of "a/" are "b" and "c/". Subtrees
+// below a separator are not walked. The results are in key order,
+// and the prefix itself is not listed. ChildCounts lists the children
+// along with the number of keys under each.
+func (t *Tree) Children(prefix string, sep byte) []string {
+	return t.AppendChildren(nil, prefix, sep)
+}
+
+// AppendChildren appends what Children would list to dst and returns
+// the extended slice
+func (t *Tree) AppendChildren(dst []string, prefix string, sep byte) []string {
+	prefix = t.norm(prefix)
+	out := dst
+	if t.smallMode {
+		i, j := t.prefixRangeSmall(prefix)
+		for _, l := range t.small[i:j] {
+			rel := l.key[len(prefix):]
+			if k := strings.IndexByte(rel, sep); k >= 0 {
+				rel = rel[:k+1]
+			}
+			if rel != "" && (len(out) == len(dst) || out[len(out)-1] != rel) {
+				out = append(out, rel)
+			}
+		}
+		return out
+	}
+
+	n := t.root
+	search := prefix
+	for len(search) > 0 {
+		// Look for an edge
+		n = n.getEdge(search[0])
+		if n == nil {
+			return out
+		}
+
+		// Consume the search prefix
+		if strings.HasPrefix(search, n.prefix) {
+			search = search[len(n.prefix):]
+			continue
+		}
+		if !strings.HasPrefix(n.prefix, search) {
+			return out
+		}
+		return appendChildren(out, n, n.prefix[len(search):], sep)
+	}
+	return appendChildren(out, n, "", sep)
+}
+
+// appendChildren appends the children under n, whose path relative to
+// the listed prefix is rel
+func appendChildren(out []string, n *node, rel string, sep byte) []string {
+	// Everything under n is in the same directory
+	if k := strings.IndexByte(rel, sep); k >= 0 {
+		return append(out, rel[:k+1])
+	}
+	if n.leaf != nil && rel != "" {
+		out = append(out, rel)
+	}
+	for _, e := range n.edges {
+		out = appendChildren(out, e.node, rel+e.node.prefix, sep)
+	}
+	return out
+}
+
+// GroupCount counts the keys below prefix by the children Children
+// would list for them: each key with no separator after prefix counts
+// towards itself, and each key that continues past one towards its
+// segment up to and including the separator. For the keys "a/b",
+// "a/c/d" and "a/c/e", the counts under "a/" are "b": 1 and "c/": 2.
+// In a tree created with WithLeafCounts or WithAggregate, a subtree
+// below a separator is counted without being walked.
+func (t *Tree) GroupCount(prefix string, sep byte) map[string]int {
+	prefix = t.norm(prefix)
+	out := make(map[string]int)
+	if t.smallMode {
+		i, j := t.prefixRangeSmall(prefix)
+		for _, l := range t.small[i:j] {
+			rel := l.key[len(prefix):]
+			if k := strings.IndexByte(rel, sep); k >= 0 {
+				rel = rel[:k+1]
+			}
+			if rel != "" {
+				out[rel]++
+			}
+		}
+		return out
+	}
+
+	n := t.root
+	search := prefix
+	for len(search) > 0 {
+		// Look for an edge
+		n = n.getEdge(search[0])
+		if n == nil {
+			return out
+		}
+
+		// Consume the search prefix
+		if strings.HasPrefix(search, n.prefix) {
+			search = search[len(n.prefix):]
+			continue
+		}
+		if strings.HasPrefix(n.prefix, search) {
+			countChildren(out, n, n.prefix[len(search):], sep)
+		}
+		return out
+	}
+	countChildren(out, n, "", sep)
+	return out
+}
+
+// countChildren adds the keys under n, whose path relative to the
+// counted prefix is rel, to their groups
+func countChildren(out map[string]int, n *node, rel string, sep byte) {
+	// Everything under n is in the same group
+	if k := strings.IndexByte(rel, sep); k >= 0 {
+		out[rel[:k+1]] += countLeaves(n)
+		return
+	}
+	if n.leaf != nil && rel != "" {
+		out[rel]++
+	}
+	for _, e := range n.edges {
+		countChildren(out, e.node, rel+e.node.prefix, sep)
+	}
+}
+
+// ChildCount is a line of the listing returned by ChildCounts
+type ChildCount struct {
+	Child string
+	Keys  int
+}
+
+// ChildCounts lists the children of prefix as Children does, in key
+// order, with the number of keys under each, as GroupCount counts
+// them. In a tree created with WithLeafCounts or WithAggregate, the
+// keys below a separator are counted from the count maintained by
+// their subtree, so a listing takes time proportional to the number of
+// children rather than the number of keys below them.
+func (t *Tree) ChildCounts(prefix string, sep byte) []ChildCount {
+	prefix = t.norm(prefix)
+	var out []ChildCount
+	if t.smallMode {
+		i, j := t.prefixRangeSmall(prefix)
+		for _, l := range t.small[i:j] {
+			rel := l.key[len(prefix):]
+			if k := strings.IndexByte(rel, sep); k >= 0 {
+				rel = rel[:k+1]
+			}
+			switch {
+			case rel == "":
+			case len(out) > 0 && out[len(out)-1].Child == rel:
+				out[len(out)-1].Keys++
+			default:
+				out = append(out, ChildCount{Child: rel, Keys: 1})
+			}
+		}
+		return out
+	}
+
+	n := t.root
+	search := prefix
+	for len(search) > 0 {
+		// Look for an edge
+		n = n.getEdge(search[0])
+		if n == nil {
+			return out
+		}
+
+		// Consume the search prefix
+		if strings.HasPrefix(search, n.prefix) {
+			search = search[len(n.prefix):]
+			continue
+		}
+		if !strings.HasPrefix(n.prefix, search) {
+			return out
+		}
+		return appendChildCounts(out, n, n.prefix[len(search):], sep)
+	}
+	return appendChildCounts(out, n, "", sep)
+}
+
+// appendChildCounts appends the children under n, whose path relative
+// to the listed prefix is rel, with their counts
+func appendChildCounts(out []ChildCount, n *node, rel string, sep byte) []ChildCount {
+	// Everything under n is in the same directory
+	if k := strings.IndexByte(rel, sep); k >= 0 {
+		return append(out, ChildCount{Child: rel[:k+1], Keys: countLeaves(n)})
+	}
+	if n.leaf != nil && rel != "" {
+		out = append(out, ChildCount{Child: rel, Keys: 1})
+	}
+	for _, e := range n.edges {
+		out = appendChildCounts(out, e.node, rel+e.node.prefix, sep)
+	}
+	return out
+}
+
+// countLeaves returns the number of leaves under n, from its aggregate
+// if it has one
+func countLeaves(n *node) int {
+	if a := n.agg(); a != nil {
+		return a.Count
+	}
+	var c int
+	last := maxLeaf(n)
+	for l := minLeaf(n); l != nil; l = l.next {
+		c++
+		if l == last {
+			break
+		}
+	}
+	return c
+}
+
+// ChildFn is used by WalkChildren. key is the full key of an entry
+// or, when common is set, a common prefix ending in the delimiter,
+// whose value is nil. Returning true stops the walk.
+type ChildFn func(key string, v interface{}, common bool) bool
+
+// WalkChildren lists what lies immediately below prefix, like a
+// delimited object storage listing, using the delimiter set by
+// WithListingDelimiter. Entries with no delimiter past prefix are visited
+// with their values, and the keys continuing past one are visited
+// once, as their common prefix up to and including the delimiter,
+// which hides an entry with that very key. Unlike with Children, an
+// entry whose key is prefix itself is visited too, as object storage
+// lists an object named by the prefix. Visits are in key order.
+// Each common prefix is skipped over in time proportional to its
+// depth, so listings take time proportional to the number of
+// children rather than the number of keys below them. fn must not
+// modify the tree. It panics if the tree has no delimiter.
+func (t *Tree) WalkChildren(prefix string, fn ChildFn) {
+	if !t.hasDelim {
+		panic("radix: WalkChildren requires WithListingDelimiter")
+	}
+	prefix = t.norm(prefix)
+	l, end := t.prefixLeaves(prefix)
+	for l != end {
+		rel := l.key[len(prefix):]
+		i := strings.IndexByte(rel, t.delim)
+		if i < 0 {
+			if fn(l.key, l.val, false) {
+				return
+			}
+			l = l.next
+			continue
+		}
+		common := l.key[:len(prefix)+i+1]
+		if fn(common, nil, true) {
+			return
+		}
+		l = t.prefixEnd(common)
+	}
+}
+
+// Parent returns the nearest stored ancestor of key when keys are
+// paths of segments separated by sep: the longest stored key that is
+// a proper prefix of key ending at a segment boundary, either just
+// before a separator in key or with a separator of its own. For
+// "a/b/c", the candidates are "a/b/", "a/b", "a/", "a" and the empty
+// key. key need not be stored itself.
+func (t *Tree) Parent(key string, sep byte) (string, interface{}, bool) {
+	key = t.norm(key)
+	var parent string
+	var val interface{}
+	var found bool
+	t.WalkPath(key, func(k string, v interface{}) bool {
+		if len(k) == len(key) {
+			return true
+		}
+		if k == "" || k[len(k)-1] == sep || key[len(k)] == sep {
+			parent, val, found = k, v, true
+		}
+		return false
+	})
+	return parent, val, found
+}
+
+// NextSibling returns the path of the child that follows key in the
+// listing of key's parent, as returned by Children with the parent's
+// path prepended, and whether there is one. The parent of key is
+// everything up to its last separator, ignoring a trailing one; a key
+// ending with sep stands for a directory, whose contents are skipped.
+// key need not be stored itself.
+func (t *Tree) NextSibling(key string, sep byte) (string, bool) {
+	key = t.norm(key)
+	var l *leafNode
+	if strings.HasSuffix(key, string(sep)) {
+		l = t.prefixEnd(key)
+	} else {
+		_, _, l = t.seek(key)
+	}
+	return siblingOf(l, parentDir(key, sep), sep)
+}
+
+// PrevSibling is like NextSibling, but returns the child preceding key
+func (t *Tree) PrevSibling(key string, sep byte) (string, bool) {
+	key = t.norm(key)
+	dir := parentDir(key, sep)
+	_, l, _ := t.seek(key)
+	if l != nil && l.key == dir {
+		return "", false
+	}
+	return siblingOf(l, dir, sep)
+}
+
+// parentDir returns the directory holding key, including its trailing
+// separator
+func parentDir(key string, sep byte) string {
+	return key[:strings.LastIndexByte(strings.TrimSuffix(key, string(sep)), sep)+1]
+}
+
+// siblingOf returns the path of the child of dir that holds l
+func siblingOf(l *leafNode, dir string, sep byte) (string, bool) {
+	if l == nil || !strings.HasPrefix(l.key, dir) {
+		return "", false
+	}
+	if k := strings.IndexByte(l.key[len(dir):], sep); k >= 0 {
+		return l.key[:len(dir)+k+1], true
+	}
+	return l.key, true
+}
+
+// prefixEnd returns the first leaf after every key starting with
+// prefix, or nil if there is none
+func (t *Tree) prefixEnd(prefix string) *leafNode {
+	if t.smallMode {
+		if _, j := t.prefixRangeSmall(prefix); j < len(t.small) {
+			return t.small[j]
+		}
+		return nil
+	}
+	n := t.root
+	search := prefix
+	for len(search) > 0 {
+		child := n.getEdge(search[0])
+		if child == nil || !(strings.HasPrefix(search, child.prefix) || strings.HasPrefix(child.prefix, search)) {
+			// Nothing starts with prefix
+			_, _, succ := t.seek(prefix)
+			return succ
+		}
+		n = child
+		if len(search) < len(n.prefix) {
+			break
+		}
+		search = search[len(n.prefix):]
+	}
+	if l := maxLeaf(n); l != nil {
+		return l.next
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package radix
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestChildren(t *testing.T) {
+	keys := []string{"a", "a/", "a/b", "a/c/d", "a/c/e", "a/cat", "a/d/x/y", "ab/c", "b"}
+	for _, opts := range [][]Option{nil, {WithSmallTree(100)}} {
+		r := New(opts...)
+		for _, k := range keys {
+			r.Insert(k, nil)
+		}
+		for prefix, want := range map[string][]string{
+			"":      {"a", "a/", "ab/", "b"},
+			"a/":    {"b", "c/", "cat", "d/"},
+			"a/c":   {"/", "at"},
+			"a/c/":  {"d", "e"},
+			"a/d/":  {"x/"},
+			"a/ca":  {"t"},
+			"a/cat": nil,
+			"c":     nil,
+		} {
+			if got := r.Children(prefix, '/'); !reflect.DeepEqual(got, want) {
+				t.Fatalf("%q: got %q, want %q", prefix, got, want)
+			}
+		}
+	}
+}
+
+func TestGroupCount(t *testing.T) {
+	keys := []string{"a", "a/", "a/b", "a/c/d", "a/c/e", "a/cat", "a/d/x/y", "ab/c", "b"}
+	agg := WithAggregate(func(string, interface{}) float64 { return 0 })
+	for _, opts := range [][]Option{nil, {WithSmallTree(100)}, {agg}} {
+		r := New(opts...)
+		for _, k := range keys {
+			r.Insert(k, nil)
+		}
+		for prefix, want := range map[string]map[string]int{
+			"":      {"a": 1, "a/": 6, "ab/": 1, "b": 1},
+			"a/":    {"b": 1, "c/": 2, "cat": 1, "d/": 1},
+			"a/c":   {"/": 2, "at": 1},
+			"a/d/":  {"x/": 1},
+			"a/cat": {},
+			"c":     {},
+		} {
+			if got := r.GroupCount(prefix, '/'); !reflect.DeepEqual(got, want) {
+				t.Fatalf("%q: got %v, want %v", prefix, got, want)
+			}
+		}
+	}
+}
+
+func TestChildCounts(t *testing.T) {
+	keys := []string{"a", "a/", "a/b", "a/c/d", "a/c/e", "a/cat", "a/d/x/y", "ab/c", "b"}
+	for _, opts := range [][]Option{nil, {WithSmallTree(100)}, {WithLeafCounts()}} {
+		r := New(opts...)
+		for _, k := range keys {
+			r.Insert(k, nil)
+		}
+		for prefix, want := range map[string][]ChildCount{
+			"":      {{"a", 1}, {"a/", 6}, {"ab/", 1}, {"b", 1}},
+			"a/":    {{"b", 1}, {"c/", 2}, {"cat", 1}, {"d/", 1}},
+			"a/c":   {{"/", 2}, {"at", 1}},
+			"a/d/":  {{"x/", 1}},
+			"a/cat": nil,
+			"c":     nil,
+		} {
+			if got := r.ChildCounts(prefix, '/'); !reflect.DeepEqual(got, want) {
+				t.Fatalf("%q: got %v, want %v", prefix, got, want)
+			}
+		}
+	}
+}
+
+func TestLeafCounts(t *testing.T) {
+	r := New(WithLeafCounts())
+	want := New()
+	insert := func(k string) {
+		r.Insert(k, nil)
+		want.Insert(k, nil)
+	}
+	check := func(op string) {
+		t.Helper()
+		if c := r.root.agg().Count; c != r.Len() {
+			t.Fatalf("%s: root counts %d of %d keys", op, c, r.Len())
+		}
+		validateTree(t, r.root)
+		for _, prefix := range []string{"", "d1/", "d2/f1"} {
+			if got, exp := r.ChildCounts(prefix, '/'), want.ChildCounts(prefix, '/'); !reflect.DeepEqual(got, exp) {
+				t.Fatalf("%s %q: got %v, want %v", op, prefix, got, exp)
+			}
+		}
+	}
+
+	for i := 0; i < 500; i++ {
+		insert(fmt.Sprintf("d%d/f%d/%d", i%5, i%11, i))
+	}
+	check("insert")
+	for i := 0; i < 500; i += 3 {
+		k := fmt.Sprintf("d%d/f%d/%d", i%5, i%11, i)
+		r.Delete(k)
+		want.Delete(k)
+	}
+	check("delete")
+	r.DeletePrefix("d3/")
+	want.DeletePrefix("d3/")
+	check("delete prefix")
+
+	other := New()
+	for i := 0; i < 100; i++ {
+		k := fmt.Sprintf("d%d/x/%d", i%7, i)
+		other.Insert(k, nil)
+		want.Insert(k, nil)
+	}
+	if err := r.Merge(other, nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	check("merge")
+}
+
+func TestParent(t *testing.T) {
+	r := New()
+	for _, k := range []string{"a", "a/b", "a/bc", "a/b/c/", "x/"} {
+		r.Insert(k, k)
+	}
+	for key, want := range map[string]string{
+		"a/b/c/d": "a/b/c/",
+		"a/b/c/":  "a/b",
+		"a/b/c":   "a/b",
+		"a/bcd":   "a",
+		"a/b":     "a",
+		"a":       "",
+		"ab":      "",
+		"x/y":     "x/",
+		"x/":      "",
+	} {
+		k, v, ok := r.Parent(key, '/')
+		if k != want || ok != (want != "") || (ok && v != want) {
+			t.Fatalf("%q: got %q %v, want %q", key, k, ok, want)
+		}
+	}
+
+	// The empty key is the root of everything
+	r.Insert("", "root")
+	if k, v, ok := r.Parent("ab", '/'); !ok || k != "" || v != "root" {
+		t.Fatalf("bad: %q %v %v", k, v, ok)
+	}
+	if _, _, ok := r.Parent("", '/'); ok {
+		t.Fatalf("the root has no parent")
+	}
+}
+
+func TestSiblings(t *testing.T) {
+	keys := []string{"a/", "a/b", "a/c", "a/c/d", "a/c/e/f", "a/cat", "a/d/x", "b", "c/y"}
+	for _, opts := range [][]Option{nil, {WithSmallTree(100)}} {
+		r := New(opts...)
+		for _, k := range keys {
+			r.Insert(k, nil)
+		}
+		for _, tc := range []struct {
+			key, next, prev string
+		}{
+			{"a/b", "a/c", ""},
+			{"a/c", "a/c/", "a/b"},
+			{"a/c/", "a/cat", "a/c"},
+			{"a/cat", "a/d/", "a/c/"},
+			{"a/d/", "", "a/cat"},
+			{"a/c/d", "a/c/e/", ""},
+			{"a/bb", "a/c", "a/b"},
+			{"a/", "b", ""},
+			{"b", "c/", "a/"},
+			{"c/", "", "b"},
+		} {
+			next, ok := r.NextSibling(tc.key, '/')
+			if next != tc.next || ok != (tc.next != "") {
+				t.Fatalf("next of %q: got %q, want %q", tc.key, next, tc.next)
+			}
+			prev, ok := r.PrevSibling(tc.key, '/')
+			if prev != tc.prev || ok != (tc.prev != "") {
+				t.Fatalf("prev of %q: got %q, want %q", tc.key, prev, tc.prev)
+			}
+		}
+	}
+}
+
+func TestWalkChildren(t *testing.T) {
+	keys := []string{"a", "a/", "a/b", "a/c/d", "a/c/e", "a/cat", "a/d/x/y", "ab/c", "b"}
+	for _, opts := range [][]Option{{WithListingDelimiter('/')}, {WithListingDelimiter('/'), WithSmallTree(100)}} {
+		r := New(opts...)
+		for _, k := range keys {
+			r.Insert(k, k)
+		}
+		for prefix, want := range map[string][]string{
+			"":      {"a", "a/*", "ab/*", "b"},
+			"a/":    {"a/", "a/b", "a/c/*", "a/cat", "a/d/*"},
+			"a/c":   {"a/c/*", "a/cat"},
+			"a/cat": {"a/cat"},
+			"c":     nil,
+		} {
+			var got []string
+			r.WalkChildren(prefix, func(k string, v interface{}, common bool) bool {
+				switch {
+				case common && v != nil:
+					t.Fatalf("common prefix %q has a value", k)
+				case common:
+					k += "*"
+				case v != k:
+					t.Fatalf("bad value of %q: %v", k, v)
+				}
+				got = append(got, k)
+				return false
+			})
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("%q: got %q, want %q", prefix, got, want)
+			}
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected a panic")
+		}
+	}()
+	New().WalkChildren("", nil)
+}
+
+func TestChildrenGroupCount(t *testing.T) {
+	r := New()
+	for i := 0; i < 1000; i++ {
+		r.Insert(fmt.Sprintf("d%d/f%d", i%7, i), nil)
+	}
+	children := r.Children("", '/')
+	counts := r.GroupCount("", '/')
+	if len(children) != 7 || len(counts) != 7 {
+		t.Fatalf("bad: %v %v", children, counts)
+	}
+	total := 0
+	for _, c := range children {
+		if counts[c] == 0 {
+			t.Fatalf("no count for %q", c)
+		}
+		total += counts[c]
+	}
+	if total != r.Len() {
+		t.Fatalf("bad total: %d", total)
+	}
+}
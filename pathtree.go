@@ -0,0 +1,272 @@
+package radix
+
+import (
+	"sort"
+	"strings"
+)
+
+// PathTree is a radix tree for keys made of segments separated by a
+// delimiter, such as object storage paths separated by '/', whose
+// nodes only split at segment boundaries. A segment runs up to and
+// including the next delimiter, or to the end of the key. Every node
+// holds one or more whole segments and tells its children apart by
+// their first segment rather than their first byte, so "a/foo/x" and
+// "a/far/y" sit in children "foo/x" and "far/y" of "a/" instead of
+// sharing an "f" node. A directory listing with WalkChildren then
+// reads the children of a single node.
+//
+// Unlike Tree, it takes no options, and keys are not normalized.
+type PathTree struct {
+	sep  byte
+	root *pathNode
+	size int
+}
+
+// pathNode is a node of a PathTree. Below the root, its prefix is one
+// or more whole segments, and a node without a leaf has at least two
+// children. Children are sorted by prefix, which for distinct first
+// segments is their key order too. A node whose prefix ends in a
+// segment without a delimiter can't have children.
+type pathNode struct {
+	prefix   string
+	leaf     *pathLeaf
+	children []*pathNode
+}
+
+// pathLeaf is an entry of a PathTree
+type pathLeaf struct {
+	key string
+	val interface{}
+}
+
+// NewPathTree returns an empty tree whose keys are separated into
+// segments by sep
+func NewPathTree(sep byte) *PathTree {
+	return &PathTree{sep: sep, root: &pathNode{}}
+}
+
+// segment returns the first segment of s
+func (t *PathTree) segment(s string) string {
+	if i := strings.IndexByte(s, t.sep); i >= 0 {
+		return s[:i+1]
+	}
+	return s
+}
+
+// commonSegments returns the length of the longest run of whole
+// segments a and b start with
+func (t *PathTree) commonSegments(a, b string) int {
+	l := longestPrefix(a, b)
+	if (l == len(a) && l == len(b)) || (l > 0 && a[l-1] == t.sep) {
+		return l
+	}
+	return strings.LastIndexByte(a[:l], t.sep) + 1
+}
+
+// child returns the index of the child of n whose first segment is
+// seg, and the child, or nil and the index it would have
+func (t *PathTree) child(n *pathNode, seg string) (int, *pathNode) {
+	i := n.search(seg)
+	if i < len(n.children) && t.segment(n.children[i].prefix) == seg {
+		return i, n.children[i]
+	}
+	return i, nil
+}
+
+// search returns the index of the first child of n whose prefix is
+// not less than s
+func (n *pathNode) search(s string) int {
+	return sort.Search(len(n.children), func(i int) bool {
+		return n.children[i].prefix >= s
+	})
+}
+
+// Len returns the number of entries
+func (t *PathTree) Len() int {
+	return t.size
+}
+
+// Insert adds or updates an entry, returning the previous value and
+// whether there was one
+func (t *PathTree) Insert(key string, v interface{}) (interface{}, bool) {
+	n := t.root
+	search := key
+	for len(search) > 0 {
+		i, c := t.child(n, t.segment(search))
+		if c == nil {
+			n.children = append(n.children, nil)
+			copy(n.children[i+1:], n.children[i:])
+			n.children[i] = &pathNode{prefix: search, leaf: &pathLeaf{key: key, val: v}}
+			t.size++
+			return nil, false
+		}
+		common := t.commonSegments(search, c.prefix)
+		if common < len(c.prefix) {
+			// Split the child at the last segment boundary key shares
+			split := &pathNode{prefix: c.prefix[:common], children: []*pathNode{c}}
+			c.prefix = c.prefix[common:]
+			n.children[i] = split
+			c = split
+		}
+		n = c
+		search = search[common:]
+	}
+	if n.leaf != nil {
+		old := n.leaf.val
+		n.leaf.val = v
+		return old, true
+	}
+	n.leaf = &pathLeaf{key: key, val: v}
+	t.size++
+	return nil, false
+}
+
+// Get looks up an entry, returning its value and whether it was found
+func (t *PathTree) Get(key string) (interface{}, bool) {
+	n := t.root
+	search := key
+	for len(search) > 0 {
+		_, c := t.child(n, t.segment(search))
+		if c == nil || t.commonSegments(search, c.prefix) != len(c.prefix) {
+			return nil, false
+		}
+		n = c
+		search = search[len(c.prefix):]
+	}
+	if n.leaf == nil {
+		return nil, false
+	}
+	return n.leaf.val, true
+}
+
+// Delete removes an entry, returning its value and whether it was
+// found
+func (t *PathTree) Delete(key string) (interface{}, bool) {
+	var parent *pathNode
+	var idx int
+	n := t.root
+	search := key
+	for len(search) > 0 {
+		i, c := t.child(n, t.segment(search))
+		if c == nil || t.commonSegments(search, c.prefix) != len(c.prefix) {
+			return nil, false
+		}
+		parent, idx, n = n, i, c
+		search = search[len(c.prefix):]
+	}
+	if n.leaf == nil {
+		return nil, false
+	}
+	old := n.leaf.val
+	n.leaf = nil
+	t.size--
+
+	switch {
+	case parent == nil:
+	case len(n.children) == 0:
+		copy(parent.children[idx:], parent.children[idx+1:])
+		parent.children[len(parent.children)-1] = nil
+		parent.children = parent.children[:len(parent.children)-1]
+		if parent != t.root && parent.leaf == nil && len(parent.children) == 1 {
+			parent.mergeChild()
+		}
+	case len(n.children) == 1:
+		n.mergeChild()
+	}
+	return old, true
+}
+
+// mergeChild absorbs n's only child into n, which joins whole
+// segments
+func (n *pathNode) mergeChild() {
+	c := n.children[0]
+	n.prefix = n.prefix + c.prefix
+	n.leaf = c.leaf
+	n.children = c.children
+}
+
+// Walk visits every entry in key order until fn returns true
+func (t *PathTree) Walk(fn WalkFn) {
+	t.root.walk(fn)
+}
+
+// walk visits the entries under n in key order, and reports whether
+// fn asked to stop
+func (n *pathNode) walk(fn WalkFn) bool {
+	if n.leaf != nil && fn(n.leaf.key, n.leaf.val) {
+		return true
+	}
+	for _, c := range n.children {
+		if c.walk(fn) {
+			return true
+		}
+	}
+	return false
+}
+
+// WalkPrefix visits the entries whose keys start with prefix in key
+// order until fn returns true. prefix need not end at a segment
+// boundary.
+func (t *PathTree) WalkPrefix(prefix string, fn WalkFn) {
+	n, rest := t.seek(prefix)
+	if rest == "" && n.leaf != nil && fn(n.leaf.key, n.leaf.val) {
+		return
+	}
+	for _, c := range t.under(n, rest) {
+		if c.walk(fn) {
+			return
+		}
+	}
+}
+
+// WalkChildren lists what lies immediately below prefix, like a
+// delimited object storage listing, with the same results as
+// Tree.WalkChildren with the tree's delimiter. Once prefix is found,
+// the listing reads the children of a single node, taking time
+// proportional to their number whatever lies below them. fn must not
+// modify the tree.
+func (t *PathTree) WalkChildren(prefix string, fn ChildFn) {
+	n, rest := t.seek(prefix)
+	if rest == "" && n.leaf != nil && fn(n.leaf.key, n.leaf.val, false) {
+		return
+	}
+	for _, c := range t.under(n, rest) {
+		rel := c.prefix[len(rest):]
+		if i := strings.IndexByte(rel, t.sep); i >= 0 {
+			if fn(prefix+rel[:i+1], nil, true) {
+				return
+			}
+		} else if fn(c.leaf.key, c.leaf.val, false) {
+			return
+		}
+	}
+}
+
+// seek descends towards prefix through nodes ending at a delimiter,
+// returning the deepest such node whose path prefix starts with, and
+// the rest of prefix past it. Keys starting with prefix are then
+// either n's own, if rest is empty, or under the children returned by
+// under.
+func (t *PathTree) seek(prefix string) (*pathNode, string) {
+	n := t.root
+	rest := prefix
+	for len(rest) > 0 {
+		_, c := t.child(n, t.segment(rest))
+		if c == nil || !strings.HasPrefix(rest, c.prefix) || c.prefix[len(c.prefix)-1] != t.sep {
+			break
+		}
+		n = c
+		rest = rest[len(c.prefix):]
+	}
+	return n, rest
+}
+
+// under returns the children of n whose prefix starts with rest
+func (t *PathTree) under(n *pathNode, rest string) []*pathNode {
+	i := n.search(rest)
+	j := i
+	for j < len(n.children) && strings.HasPrefix(n.children[j].prefix, rest) {
+		j++
+	}
+	return n.children[i:j]
+}
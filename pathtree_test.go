@@ -0,0 +1,154 @@
+package radix
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPathTree(t *testing.T) {
+	r := NewPathTree('/')
+	want := New(WithListingDelimiter('/'))
+	rnd := rand.New(rand.NewSource(1))
+	key := func() string {
+		b := make([]byte, rnd.Intn(7))
+		for i := range b {
+			b[i] = "ab/"[rnd.Intn(3)]
+		}
+		return string(b)
+	}
+	for i := 0; i < 5000; i++ {
+		k := key()
+		if rnd.Intn(3) == 0 {
+			old, ok := r.Delete(k)
+			wantOld, wantOK := want.Delete(k)
+			if old != wantOld || ok != wantOK {
+				t.Fatalf("Delete(%q): %v %v", k, old, ok)
+			}
+		} else {
+			old, ok := r.Insert(k, i)
+			wantOld, wantOK := want.Insert(k, i)
+			if old != wantOld || ok != wantOK {
+				t.Fatalf("Insert(%q): %v %v", k, old, ok)
+			}
+		}
+		validatePathTree(t, r, r.root, true)
+		if r.Len() != want.Len() {
+			t.Fatalf("bad len: %d %d", r.Len(), want.Len())
+		}
+	}
+
+	var got, exp []string
+	r.Walk(func(k string, v interface{}) bool {
+		got = append(got, k)
+		return false
+	})
+	want.Walk(func(k string, v interface{}) bool {
+		exp = append(exp, k)
+		return false
+	})
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("bad walk: %q %q", got, exp)
+	}
+	for i := 0; i < 200; i++ {
+		k := key()
+		v, ok := r.Get(k)
+		wantV, wantOK := want.Get(k)
+		if v != wantV || ok != wantOK {
+			t.Fatalf("Get(%q): %v %v", k, v, ok)
+		}
+		got, exp = got[:0], exp[:0]
+		r.WalkPrefix(k, func(k string, v interface{}) bool {
+			got = append(got, k)
+			return false
+		})
+		want.WalkPrefix(k, func(k string, v interface{}) bool {
+			exp = append(exp, k)
+			return false
+		})
+		if !reflect.DeepEqual(got, exp) {
+			t.Fatalf("WalkPrefix(%q): %q %q", k, got, exp)
+		}
+
+		got, exp = got[:0], exp[:0]
+		r.WalkChildren(k, func(k string, v interface{}, common bool) bool {
+			got = append(got, k)
+			return false
+		})
+		want.WalkChildren(k, func(k string, v interface{}, common bool) bool {
+			exp = append(exp, k)
+			return false
+		})
+		if !reflect.DeepEqual(got, exp) {
+			t.Fatalf("WalkChildren(%q): %q %q", k, got, exp)
+		}
+	}
+}
+
+// validatePathTree checks that every node below the root holds whole
+// segments, in order, and either has an entry or branches
+func validatePathTree(t *testing.T, r *PathTree, n *pathNode, root bool) {
+	t.Helper()
+	if !root {
+		switch {
+		case n.prefix == "":
+			t.Fatalf("empty prefix")
+		case n.leaf == nil && len(n.children) < 2:
+			t.Fatalf("node %q has no entry and %d children", n.prefix, len(n.children))
+		case n.prefix[len(n.prefix)-1] != r.sep && len(n.children) > 0:
+			t.Fatalf("node %q splits inside a segment", n.prefix)
+		}
+	}
+	for i, c := range n.children {
+		if i > 0 && r.segment(n.children[i-1].prefix) >= r.segment(c.prefix) {
+			t.Fatalf("children of %q out of order", n.prefix)
+		}
+		validatePathTree(t, r, c, false)
+	}
+}
+
+func TestPathTree_Segments(t *testing.T) {
+	r := NewPathTree('/')
+	r.Insert("a/foo/x", 1)
+	r.Insert("a/far/y", 2)
+	r.Insert("a/fo", 3)
+	a := r.root.children[0]
+	var prefixes []string
+	for _, c := range a.children {
+		prefixes = append(prefixes, c.prefix)
+	}
+	if a.prefix != "a/" || !reflect.DeepEqual(prefixes, []string{"far/y", "fo", "foo/x"}) {
+		t.Fatalf("bad: %q %q", a.prefix, prefixes)
+	}
+}
+
+func TestPathTree_WalkChildren(t *testing.T) {
+	keys := []string{"a", "a/", "a/b", "a/c/d", "a/c/e", "a/cat", "a/d/x/y", "ab/c", "b"}
+	r := NewPathTree('/')
+	want := New(WithListingDelimiter('/'))
+	for _, k := range keys {
+		r.Insert(k, k)
+		want.Insert(k, k)
+	}
+	list := func(walk func(string, ChildFn), prefix string) []string {
+		var out []string
+		walk(prefix, func(k string, v interface{}, common bool) bool {
+			if common {
+				k += "*"
+			}
+			out = append(out, k)
+			return false
+		})
+		return out
+	}
+	for _, prefix := range []string{"", "a", "a/", "a/c", "a/c/", "a/cat", "a/d", "a/d/x/", "ab", "c"} {
+		got, exp := list(r.WalkChildren, prefix), list(want.WalkChildren, prefix)
+		if !reflect.DeepEqual(got, exp) {
+			t.Fatalf("%q: got %q, want %q", prefix, got, exp)
+		}
+	}
+	if got := list(r.WalkChildren, "a/"); strings.Join(got, " ") != "a/ a/b a/c/* a/cat a/d/*" {
+		t.Fatalf("bad: %q", got)
+	}
+}
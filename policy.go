@@ -0,0 +1,123 @@
+package radix
+
+import (
+	"sort"
+	"strings"
+)
+
+// Deny is the capability that, granted by a policy rule, revokes
+// every other capability on the paths the rule covers
+const Deny = "deny"
+
+// Policy grants capabilities on paths, in the style of Vault's ACL
+// policies. A rule's path is either exact, or a prefix followed by a
+// '*', which covers every path starting with the prefix. A path is
+// governed by its exact rule if there is one, and otherwise by the
+// glob rule with the longest prefix; rules are not merged. Policy is
+// not safe for concurrent modification.
+type Policy struct {
+	t *Tree
+}
+
+// policyRule holds the capabilities granted at a path exactly and to
+// the glob on it
+type policyRule struct {
+	exact, glob map[string]bool
+}
+
+// NewPolicy returns a policy with no rules, which allows nothing
+func NewPolicy() *Policy {
+	return &Policy{t: New()}
+}
+
+// SetRule grants caps on path, replacing any rule for the same path.
+// A path ending in '*' is a glob covering every path that starts with
+// the rest of it.
+func (p *Policy) SetRule(path string, caps ...string) {
+	set := make(map[string]bool, len(caps))
+	for _, c := range caps {
+		set[c] = true
+	}
+	prefix, glob := globPrefix(path)
+	r := &policyRule{}
+	if v, ok := p.t.Get(prefix); ok {
+		*r = *v.(*policyRule)
+	}
+	if glob {
+		r.glob = set
+	} else {
+		r.exact = set
+	}
+	p.t.Insert(prefix, r)
+}
+
+// RemoveRule removes the rule for path, written as for SetRule, and
+// reports whether there was one
+func (p *Policy) RemoveRule(path string) bool {
+	prefix, glob := globPrefix(path)
+	v, ok := p.t.Get(prefix)
+	if !ok {
+		return false
+	}
+	r := *v.(*policyRule)
+	removed := r.exact != nil
+	if glob {
+		removed = r.glob != nil
+		r.glob = nil
+	} else {
+		r.exact = nil
+	}
+	if r.exact == nil && r.glob == nil {
+		p.t.Delete(prefix)
+	} else {
+		p.t.Insert(prefix, &r)
+	}
+	return removed
+}
+
+// Capabilities returns the capabilities granted on path by the rule
+// governing it, sorted, or nil if no rule covers it
+func (p *Policy) Capabilities(path string) []string {
+	caps := p.governing(path)
+	if caps == nil {
+		return nil
+	}
+	out := make([]string, 0, len(caps))
+	for c := range caps {
+		out = append(out, c)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// governing returns the capabilities of the rule governing path
+func (p *Policy) governing(path string) map[string]bool {
+	if v, ok := p.t.Get(path); ok {
+		if r := v.(*policyRule); r.exact != nil {
+			return r.exact
+		}
+	}
+	var caps map[string]bool
+	p.t.WalkPath(path, func(_ string, v interface{}) bool {
+		if r := v.(*policyRule); r.glob != nil {
+			caps = r.glob
+		}
+		return false
+	})
+	return caps
+}
+
+// Allowed reports whether capability is granted on path. Nothing is
+// allowed on a path whose governing rule grants Deny.
+func (p *Policy) Allowed(path, capability string) bool {
+	caps := p.governing(path)
+	return caps[capability] && !caps[Deny]
+}
+
+// globPrefix strips the '*' that marks a glob from path
+func globPrefix(path string) (string, bool) {
+	if strings.HasSuffix(path, "*") {
+		return path[:len(path)-1], true
+	}
+	return path, false
+}
@@ -0,0 +1,56 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPolicy(t *testing.T) {
+	p := NewPolicy()
+	p.SetRule("secret/*", "read", "list")
+	p.SetRule("secret/team/*", "read", "create", "update")
+	p.SetRule("secret/team/root", Deny)
+	p.SetRule("secret/team", "list")
+
+	cases := []struct {
+		path, capability string
+		allowed          bool
+	}{
+		{"secret/foo", "read", true},
+		{"secret/foo", "create", false},
+		{"secret/team/app", "create", true},
+		{"secret/team/app", "list", false}, // rules aren't merged
+		{"secret/team/root", "read", false},
+		{"secret/team/rootx", "read", true},
+		{"secret/team", "list", true},
+		{"secret/team", "read", false}, // the exact rule wins
+		{"secret", "read", false},
+		{"other", "read", false},
+	}
+	for _, c := range cases {
+		if got := p.Allowed(c.path, c.capability); got != c.allowed {
+			t.Fatalf("%s %s: got %v", c.path, c.capability, got)
+		}
+	}
+	if caps := p.Capabilities("secret/team/x"); !reflect.DeepEqual(caps, []string{"create", "read", "update"}) {
+		t.Fatalf("bad: %v", caps)
+	}
+	if caps := p.Capabilities("other"); caps != nil {
+		t.Fatalf("bad: %v", caps)
+	}
+
+	// Removing the glob on secret/team/ keeps the exact rule there
+	p.SetRule("secret/team/", "sudo")
+	if !p.RemoveRule("secret/team/*") || p.RemoveRule("secret/team/*") {
+		t.Fatalf("bad remove")
+	}
+	if !p.Allowed("secret/team/", "sudo") || !p.Allowed("secret/team/app", "list") {
+		t.Fatalf("bad: %v", p.Capabilities("secret/team/app"))
+	}
+	if !p.RemoveRule("secret/team/") || p.RemoveRule("nope") {
+		t.Fatalf("bad remove")
+	}
+	if !p.Allowed("secret/team/", "read") {
+		t.Fatalf("bad")
+	}
+}
@@ -0,0 +1,29 @@
+package radix
+
+// PathPrefixes returns every entry whose key is a prefix of s,
+// shortest first, as found in one descent by WalkPath. It suits
+// resolving settings inherited down a hierarchy, where every
+// ancestor counts and not just the nearest.
+func (t *Tree) PathPrefixes(s string) []KV {
+	var out []KV
+	t.WalkPath(s, func(k string, v interface{}) bool {
+		out = append(out, KV{Key: k, Value: v})
+		return false
+	})
+	return out
+}
+
+// ShortestPrefix is like LongestPrefix, but returns the shortest
+// stored key that is a prefix of s, for first match from the root
+// semantics as used by some access lists. It stops at the first entry
+// found on the way down.
+func (t *Tree) ShortestPrefix(s string) (string, interface{}, bool) {
+	var key string
+	var val interface{}
+	var found bool
+	t.WalkPath(s, func(k string, v interface{}) bool {
+		key, val, found = k, v, true
+		return true
+	})
+	return key, val, found
+}
@@ -0,0 +1,44 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPathPrefixes(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithSmallTree(100)}} {
+		r := New(opts...)
+		for i, k := range []string{"", "a", "a/b", "a/bc", "a/b/c", "b"} {
+			r.Insert(k, i)
+		}
+		want := []KV{{"", 0}, {"a", 1}, {"a/b", 2}, {"a/b/c", 4}}
+		if got := r.PathPrefixes("a/b/cd"); !reflect.DeepEqual(got, want) {
+			t.Fatalf("bad: %v", got)
+		}
+		r.Delete("")
+		if got := r.PathPrefixes("x"); got != nil {
+			t.Fatalf("bad: %v", got)
+		}
+	}
+}
+
+func TestShortestPrefix(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithSmallTree(100)}} {
+		r := New(opts...)
+		for _, k := range []string{"10.", "10.1.", "10.1.2.", "192."} {
+			r.Insert(k, k)
+		}
+		for s, want := range map[string]string{
+			"10.1.2.3": "10.",
+			"10.9":     "10.",
+			"192.168":  "192.",
+			"172.16":   "",
+			"10":       "",
+		} {
+			k, v, ok := r.ShortestPrefix(s)
+			if ok != (want != "") || k != want || (ok && v != want) {
+				t.Fatalf("%q: got %q %v, want %q", s, k, ok, want)
+			}
+		}
+	}
+}
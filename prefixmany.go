@@ -0,0 +1,88 @@
+package radix
+
+import (
+	"sort"
+	"strings"
+)
+
+// PrefixMatch is the result of one lookup by LongestPrefixMany
+type PrefixMatch struct {
+	Key   string
+	Value interface{}
+	Found bool
+}
+
+// LongestPrefixMany does a LongestPrefix lookup for each of keys,
+// returning the results in the same order. The keys are looked up in
+// sorted order so that each descent resumes from the deepest node
+// shared with the previous one, which makes classifying a large batch
+// of related keys, such as URLs or IP addresses, much cheaper than
+// looking them up one at a time.
+func (t *Tree) LongestPrefixMany(keys []string) []PrefixMatch {
+	out := make([]PrefixMatch, len(keys))
+	if t.smallMode {
+		for i, k := range keys {
+			out[i].Key, out[i].Value, out[i].Found = t.LongestPrefix(k)
+		}
+		return out
+	}
+
+	if t.normalize != nil {
+		normed := make([]string, len(keys))
+		for i, k := range keys {
+			normed[i] = t.norm(k)
+		}
+		keys = normed
+	}
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return keys[order[i]] < keys[order[j]]
+	})
+
+	// stack holds the path of the previous descent, along with the
+	// longest match at each step
+	type step struct {
+		n    *node
+		end  int
+		last *leafNode
+	}
+	stack := []step{{n: t.root, last: t.root.leaf}}
+	prev := ""
+	for _, i := range order {
+		s := keys[i]
+
+		// Resume from the deepest node whose path s shares
+		common := longestPrefix(prev, s)
+		for len(stack) > 1 && stack[len(stack)-1].end > common {
+			stack = stack[:len(stack)-1]
+		}
+		prev = s
+
+		top := stack[len(stack)-1]
+		n, search, last := top.n, s[top.end:], top.last
+		for len(search) > 0 {
+			// Look for an edge
+			n = n.getEdge(search[0])
+			if n == nil {
+				break
+			}
+
+			// Consume the search prefix
+			if !strings.HasPrefix(search, n.prefix) {
+				break
+			}
+			search = search[len(n.prefix):]
+			if n.leaf != nil {
+				last = n.leaf
+			}
+			stack = append(stack, step{n: n, end: len(s) - len(search), last: last})
+		}
+		if last != nil {
+			out[i] = PrefixMatch{Key: last.key, Value: last.val, Found: true}
+		}
+	}
+	return out
+}
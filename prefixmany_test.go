@@ -0,0 +1,58 @@
+package radix
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestLongestPrefixMany(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithSmallTree(100)}} {
+		r := New(opts...)
+		for _, k := range []string{"", "foo", "foobar", "foobarbaz", "foozip", "bar"} {
+			r.Insert(k, k)
+		}
+		keys := []string{"foobarba", "zzz", "foo", "foozi", "foobarbazz", "ba", "bar/x", "", "foobar", "foozipper"}
+		got := r.LongestPrefixMany(keys)
+		if len(got) != len(keys) {
+			t.Fatalf("bad: %d", len(got))
+		}
+		for i, k := range keys {
+			key, val, ok := r.LongestPrefix(k)
+			if got[i] != (PrefixMatch{Key: key, Value: val, Found: ok}) {
+				t.Fatalf("bad %q: %+v, want %q", k, got[i], key)
+			}
+		}
+	}
+
+	// Without a root entry, misses are reported
+	r := New()
+	r.Insert("a", 1)
+	if got := r.LongestPrefixMany([]string{"b", "ab"}); got[0].Found || !got[1].Found {
+		t.Fatalf("bad: %+v", got)
+	}
+}
+
+func TestLongestPrefixMany_Random(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	randKey := func() string {
+		b := make([]byte, rnd.Intn(8))
+		for i := range b {
+			b[i] = "abc"[rnd.Intn(3)]
+		}
+		return string(b)
+	}
+	r := New()
+	for i := 0; i < 200; i++ {
+		r.Insert(randKey(), i)
+	}
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = randKey() + randKey()
+	}
+	for i, m := range r.LongestPrefixMany(keys) {
+		key, val, ok := r.LongestPrefix(keys[i])
+		if m != (PrefixMatch{Key: key, Value: val, Found: ok}) {
+			t.Fatalf("bad %q: %+v, want %q", keys[i], m, key)
+		}
+	}
+}
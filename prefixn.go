@@ -0,0 +1,21 @@
+package radix
+
+// KV is a key and its value
+type KV struct {
+	Key   string
+	Value interface{}
+}
+
+// WalkPrefixN returns the first n entries whose keys start with
+// prefix, in key order, as when completing the first few matches of
+// what was typed so far. It stops as soon as it has n, so it takes
+// time proportional to the depth of prefix and n, however many keys
+// share the prefix. Unlike WalkPrefix, it ignores WithNaturalOrder.
+func (t *Tree) WalkPrefixN(prefix string, n int) []KV {
+	var out []KV
+	first, end := t.prefixLeaves(t.norm(prefix))
+	for l := first; l != end && len(out) < n; l = l.next {
+		out = append(out, KV{Key: l.key, Value: l.val})
+	}
+	return out
+}
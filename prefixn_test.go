@@ -0,0 +1,27 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWalkPrefixN(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithSmallTree(100)}} {
+		r := New(opts...)
+		for i, k := range []string{"a", "ab", "abc", "abd", "b", "ba"} {
+			r.Insert(k, i)
+		}
+		if got := r.WalkPrefixN("ab", 2); !reflect.DeepEqual(got, []KV{{"ab", 1}, {"abc", 2}}) {
+			t.Fatalf("bad: %v", got)
+		}
+		if got := r.WalkPrefixN("b", 10); !reflect.DeepEqual(got, []KV{{"b", 4}, {"ba", 5}}) {
+			t.Fatalf("bad: %v", got)
+		}
+		if got := r.WalkPrefixN("", 0); got != nil {
+			t.Fatalf("bad: %v", got)
+		}
+		if got := r.WalkPrefixN("c", 5); got != nil {
+			t.Fatalf("bad: %v", got)
+		}
+	}
+}
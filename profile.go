@@ -0,0 +1,128 @@
+package radix
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// profileCapacity is the most prefixes a profiler counts at once, see
+// WithPrefixProfiler
+const profileCapacity = 1024
+
+// PrefixCount is a line of the report returned by HotPrefixes
+type PrefixCount struct {
+	Prefix  string
+	Samples uint64
+}
+
+// prefixProfile samples the keys looked up in a tree, see
+// WithPrefixProfiler. It counts prefixes with the Space-Saving
+// algorithm: once profileCapacity prefixes are counted, a new one
+// replaces the least sampled and inherits its count, so memory stays
+// fixed and any prefix sampled more often than that count is kept.
+type prefixProfile struct {
+	every, depth int
+	n            int
+	counts       profileHeap
+}
+
+func newPrefixProfile(every, depth int) *prefixProfile {
+	return &prefixProfile{every: every, depth: depth, counts: profileHeap{index: make(map[string]int)}}
+}
+
+// sample counts the lookup of key if it is due to be sampled
+func (p *prefixProfile) sample(key string) {
+	p.n++
+	if p.n < p.every {
+		return
+	}
+	p.n = 0
+	if len(key) > p.depth {
+		key = key[:p.depth]
+	}
+	h := &p.counts
+	if i, ok := h.index[key]; ok {
+		h.items[i].Samples++
+		heap.Fix(h, i)
+		return
+	}
+
+	// Don't keep the caller's whole key alive
+	key = string([]byte(key))
+	if len(h.items) < profileCapacity {
+		heap.Push(h, PrefixCount{Prefix: key, Samples: 1})
+		return
+	}
+	min := &h.items[0]
+	delete(h.index, min.Prefix)
+	min.Prefix = key
+	min.Samples++
+	h.index[key] = 0
+	heap.Fix(h, 0)
+}
+
+// profileHeap is a min-heap of the counted prefixes by samples, so
+// the one to replace is at the top. index locates each prefix.
+type profileHeap struct {
+	items []PrefixCount
+	index map[string]int
+}
+
+func (h *profileHeap) Len() int {
+	return len(h.items)
+}
+
+func (h *profileHeap) Less(i, j int) bool {
+	return h.items[i].Samples < h.items[j].Samples
+}
+
+func (h *profileHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.index[h.items[i].Prefix] = i
+	h.index[h.items[j].Prefix] = j
+}
+
+func (h *profileHeap) Push(x interface{}) {
+	c := x.(PrefixCount)
+	h.index[c.Prefix] = len(h.items)
+	h.items = append(h.items, c)
+}
+
+func (h *profileHeap) Pop() interface{} {
+	c := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	delete(h.index, c.Prefix)
+	return c
+}
+
+// HotPrefixes returns the n prefixes sampled most often by the
+// profiler set up with WithPrefixProfiler, most sampled first, or
+// all of them if n is zero or less. The counts are exact until more
+// than 1024 distinct prefixes have been sampled; after that they may
+// overcount, by at most the count of the least sampled prefix
+// reported.
+func (t *Tree) HotPrefixes(n int) []PrefixCount {
+	if t.profile == nil {
+		panic("radix: HotPrefixes requires WithPrefixProfiler")
+	}
+	out := append([]PrefixCount(nil), t.profile.counts.items...)
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Samples != out[j].Samples {
+			return out[i].Samples > out[j].Samples
+		}
+		return out[i].Prefix < out[j].Prefix
+	})
+	if n > 0 && n < len(out) {
+		out = out[:n]
+	}
+	return out
+}
+
+// ResetProfile discards the samples taken so far by the profiler
+func (t *Tree) ResetProfile() {
+	if t.profile == nil {
+		panic("radix: ResetProfile requires WithPrefixProfiler")
+	}
+	t.profile.n = 0
+	t.profile.counts = profileHeap{index: make(map[string]int)}
+}
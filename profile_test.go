@@ -0,0 +1,50 @@
+package radix
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestHotPrefixes(t *testing.T) {
+	r := New(WithPrefixProfiler(2, 3))
+	r.Insert("users/1", nil)
+	for i := 0; i < 20; i++ {
+		r.Get("users/1")
+	}
+	for i := 0; i < 10; i++ {
+		r.LongestPrefix("orders/9")
+	}
+	for i := 0; i < 4; i++ {
+		r.Get("x")
+	}
+	want := []PrefixCount{{"use", 10}, {"ord", 5}, {"x", 2}}
+	if got := r.HotPrefixes(0); !reflect.DeepEqual(got, want) {
+		t.Fatalf("bad: %v", got)
+	}
+	if got := r.HotPrefixes(1); !reflect.DeepEqual(got, want[:1]) {
+		t.Fatalf("bad: %v", got)
+	}
+
+	r.ResetProfile()
+	if got := r.HotPrefixes(0); len(got) != 0 {
+		t.Fatalf("bad: %v", got)
+	}
+}
+
+func TestHotPrefixes_Bounded(t *testing.T) {
+	r := New(WithPrefixProfiler(1, 8))
+	for i := 0; i < 10*profileCapacity; i++ {
+		r.Get(fmt.Sprintf("cold%04d", i))
+		if i%4 == 0 {
+			r.Get("hot")
+		}
+	}
+	got := r.HotPrefixes(0)
+	if len(got) != profileCapacity {
+		t.Fatalf("bad: %d", len(got))
+	}
+	if got[0].Prefix != "hot" || got[0].Samples < uint64(10*profileCapacity/4) {
+		t.Fatalf("bad: %v", got[0])
+	}
+}
@@ -1,8 +1,11 @@
 package radix
 
 import (
+	"container/heap"
 	"sort"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 // WalkFn is used when walking the tree. Takes a
@@ -14,6 +17,50 @@ type WalkFn func(s string, v interface{}) bool
 type leafNode struct {
 	key string
 	val interface{}
+
+	// prev and next link all leaves in key order
+	prev, next *leafNode
+
+	// ext holds the state of optional features, allocated the
+	// first time one of them needs it
+	ext *leafExt
+}
+
+// leafExt is the part of a leaf only used by optional features,
+// kept out of line so plain leaves stay small
+type leafExt struct {
+	// older and newer link leaves in insertion order when the
+	// tree tracks it, see WithInsertionOrder
+	older, newer *leafNode
+
+	// expires is when the leaf expires in Unix nanoseconds, or
+	// zero if it never does, and ttl the lifetime it was given.
+	// expIdx is its position in the tree's expiry heap.
+	expires int64
+	ttl     time.Duration
+	expIdx  int
+
+	// hits counts the Gets that found the leaf, see WithLFU, and
+	// lfuIdx is its position in the tree's LFU heap
+	hits   uint64
+	lfuIdx int
+}
+
+// x returns the extension of l, allocating it if needed
+func (l *leafNode) x() *leafExt {
+	if l.ext == nil {
+		l.ext = &leafExt{}
+	}
+	return l.ext
+}
+
+// expires returns when l expires in Unix nanoseconds, or zero if it
+// never does
+func (l *leafNode) expires() int64 {
+	if l.ext == nil {
+		return 0
+	}
+	return l.ext.expires
 }
 
 // edge is used to represent an edge node
@@ -33,58 +80,169 @@ type node struct {
 	// We avoid a fully materialized slice to save memory,
 	// since in most cases we expect to be sparse
 	edges edges
+
+	// ext holds the state of optional features, or is nil if the
+	// node needs none
+	ext *nodeExt
+}
+
+// nodeExt is the part of a node only used by optional features,
+// kept out of line so plain nodes stay small
+type nodeExt struct {
+	// dense optionally indexes the edges by label so lookups on
+	// hot, high-fanout levels are a single array access. It is
+	// kept in sync with edges, which remains the source of order.
+	dense *[256]*node
+
+	// agg summarises the subtree when the tree maintains
+	// aggregates, see WithAggregate
+	agg *Aggregate
+
+	// collation orders the edges if the tree has a custom key
+	// order, see WithCollation
+	collation *Collation
+
+	// bitmap locates the edges of a node with many of them, see
+	// WithAdaptiveNodes
+	bitmap *edgeBitmap
+
+	// adaptive lets the node pick its edge lookup by fanout
+	adaptive bool
 }
 
 func (n *node) isLeaf() bool {
 	return n.leaf != nil
 }
 
-func (n *node) addEdge(e edge) {
+// linearSearchThreshold is the fanout below which edges are
+// located with a linear scan rather than a binary search. Most
+// nodes are sparse, and for a handful of edges the scan is cheaper.
+// The binary search is written out by hand rather than using
+// sort.Search to avoid the closure call per probe.
+const linearSearchThreshold = 8
+
+// edgeIndex returns the index of the first edge whose label is
+// not less than the given label.
+func (n *node) edgeIndex(label byte) int {
+	if x := n.ext; x != nil {
+		if x.bitmap != nil {
+			return x.bitmap.rank(n.pos(label))
+		}
+		if x.collation != nil {
+			return n.collatedEdgeIndex(label)
+		}
+	}
 	num := len(n.edges)
-	idx := sort.Search(num, func(i int) bool {
-		return n.edges[i].label >= e.label
-	})
+	if num < linearSearchThreshold {
+		for i := 0; i < num; i++ {
+			if n.edges[i].label >= label {
+				return i
+			}
+		}
+		return num
+	}
+	lo, hi := 0, num
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if n.edges[mid].label < label {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
 
+func (n *node) addEdge(e edge) {
+	x := n.ext
+	if x != nil && x.dense != nil {
+		x.dense[e.label] = e.node
+	}
+	idx := n.edgeIndex(e.label)
 	n.edges = append(n.edges, edge{})
 	copy(n.edges[idx+1:], n.edges[idx:])
 	n.edges[idx] = e
+	if x == nil {
+		return
+	}
+	if x.bitmap != nil {
+		x.bitmap.set(n.pos(e.label))
+	}
+	if x.adaptive {
+		n.tune()
+	}
 }
 
 func (n *node) updateEdge(label byte, node *node) {
-	num := len(n.edges)
-	idx := sort.Search(num, func(i int) bool {
-		return n.edges[i].label >= label
-	})
-	if idx < num && n.edges[idx].label == label {
+	idx := n.edgeIndex(label)
+	if idx < len(n.edges) && n.edges[idx].label == label {
 		n.edges[idx].node = node
+		if d := n.dense(); d != nil {
+			d[label] = node
+		}
 		return
 	}
 	panic("replacing missing edge")
 }
 
 func (n *node) getEdge(label byte) *node {
-	num := len(n.edges)
-	idx := sort.Search(num, func(i int) bool {
-		return n.edges[i].label >= label
-	})
-	if idx < num && n.edges[idx].label == label {
+	if x := n.ext; x != nil {
+		if x.dense != nil {
+			return x.dense[label]
+		}
+		if x.bitmap != nil {
+			pos := n.pos(label)
+			if !x.bitmap.has(pos) {
+				return nil
+			}
+			return n.edges[x.bitmap.rank(pos)].node
+		}
+	}
+	idx := n.edgeIndex(label)
+	if idx < len(n.edges) && n.edges[idx].label == label {
 		return n.edges[idx].node
 	}
 	return nil
 }
 
 func (n *node) delEdge(label byte) {
-	num := len(n.edges)
-	idx := sort.Search(num, func(i int) bool {
-		return n.edges[i].label >= label
-	})
-	if idx < num && n.edges[idx].label == label {
+	x := n.ext
+	if x != nil && x.dense != nil {
+		x.dense[label] = nil
+	}
+	idx := n.edgeIndex(label)
+	if idx < len(n.edges) && n.edges[idx].label == label {
 		copy(n.edges[idx:], n.edges[idx+1:])
 		n.edges[len(n.edges)-1] = edge{}
 		n.edges = n.edges[:len(n.edges)-1]
+		if x == nil {
+			return
+		}
+		if x.bitmap != nil {
+			x.bitmap.clear(n.pos(label))
+		}
+		if x.adaptive {
+			n.tune()
+		}
 	}
 }
 
+// dense returns the dense dispatch table of n, if it has one
+func (n *node) dense() *[256]*node {
+	if n.ext == nil {
+		return nil
+	}
+	return n.ext.dense
+}
+
+// agg returns the aggregate of n's subtree, if the tree keeps them
+func (n *node) agg() *Aggregate {
+	if n.ext == nil {
+		return nil
+	}
+	return n.ext.agg
+}
+
 type edges []edge
 
 func (e edges) Len() int {
@@ -108,30 +266,315 @@ func (e edges) Sort() {
 // a standard hash map is prefix-based lookups and
 // ordered iteration,
 type Tree struct {
+	*treeExt
+
 	root *node
 	size int
+
+	// bytes is the approximate memory held by the entries
+	bytes int
+
+	// leaves threads every leaf in key order
+	leaves leafList
+
+	// mods counts the entries added and removed, so walks can
+	// detect changes to the structure under them
+	mods uint64
+
+	// generation counts every change to the entries, see Generation
+	generation uint64
+}
+
+// treeExt holds the configuration of a tree and the state of its
+// optional features, kept out of line so a Tree stays small
+type treeExt struct {
+	config
+
+	// order threads the leaves in insertion order if enabled
+	order orderList
+
+	// absent holds keys the loader recently reported missing
+	absent negativeCache
+
+	// expiry orders the leaves that have a TTL by expiry time
+	expiry expiryHeap
+
+	// lfu orders the leaves by hits if the tree has a capacity
+	lfu lfuHeap
+
+	// janitor evicts expired entries in the background, see
+	// WithJanitor
+	janitor *janitor
+
+	// small holds the entries in key order while smallMode is set,
+	// in which case root stays empty, see WithSmallTree
+	small     []*leafNode
+	smallMode bool
+
+	// leafSlab and nodeSlab hold leaves and nodes preallocated for
+	// new entries, see WithCapacity
+	leafSlab []leafNode
+	nodeSlab []node
+
+	// history maps each key to its versions, oldest first, see
+	// WithHistory
+	history *Tree
+
+	// matcher holds the cachedAutomaton behind ContainsAny
+	matcher atomic.Value
+}
+
+// config holds the settings applied by Options. Trees derived from
+// another tree, such as the halves of a Split, inherit its config.
+type config struct {
+	// denseDepth is the key offset below which nodes carry a
+	// dense dispatch table, see WithDenseDispatch
+	denseDepth int
+
+	// budget optionally caps bytes, see WithMemoryBudget
+	budget int
+	sizer  func(v interface{}) int
+
+	// keyPolicy optionally restricts keys, see WithKeyPolicy
+	keyPolicy *keyPolicy
+
+	// insertionOrder enables the order list
+	insertionOrder bool
+
+	// aggFn derives the number aggregated per node, if any
+	aggFn func(key string, v interface{}) float64
+
+	// leafCounts maintains the number of leaves under every node,
+	// see WithLeafCounts
+	leafCounts bool
+
+	// loader fills in missing keys on Get, see WithLoader
+	loader LoaderFunc
+
+	// negativeTTL is how long the loader's misses are remembered,
+	// see WithNegativeCache
+	negativeTTL time.Duration
+
+	// store receives every mutation, see WithStore
+	store *storeWriter
+
+	// wal logs every mutation to a backend, see WithBackend
+	wal *wal
+
+	// commitLatency and commitBatch configure group commit of the
+	// log, see WithGroupCommit
+	commitLatency time.Duration
+	commitBatch   int
+
+	// durability decides when the log is synced, see WithDurability
+	durability Durability
+
+	// slowFn reports operations taking slowThreshold or longer, see
+	// WithSlowOpWarning
+	slowThreshold time.Duration
+	slowFn        SlowOpFunc
+
+	// defaultTTL is the lifetime given to inserted entries, see
+	// SetDefaultTTL
+	defaultTTL time.Duration
+
+	// onExpire is called for entries evicted by expiry, see OnExpire
+	onExpire []func(key string, v interface{})
+
+	// clock tells the time for expiry, see WithClock
+	clock Clock
+
+	// collation is the key order, or nil for byte order, see
+	// WithCollation
+	collation *Collation
+
+	// naturalOrder makes walks compare numbers by value, see
+	// WithNaturalOrder
+	naturalOrder bool
+
+	// adaptive lets nodes pick their edge lookup, see
+	// WithAdaptiveNodes
+	adaptive bool
+
+	// countHits enables counting hits, and lfuCap caps the number
+	// of entries, see WithLFU
+	countHits bool
+	lfuCap    int
+
+	// profile samples the keys looked up, see WithPrefixProfiler
+	profile *prefixProfile
+
+	// normalize maps keys to their stored form, see
+	// WithKeyNormalizer
+	normalize func(key string) string
+
+	// smallMax is the most entries a tree holds before it is
+	// promoted to nodes, see WithSmallTree
+	smallMax int
+
+	// capacity is the expected number of entries, see WithCapacity
+	capacity int
+
+	// keepHistory records every version of every key, see
+	// WithHistory, within the limits of historyVersions and
+	// historyAge, see WithHistoryRetention
+	keepHistory     bool
+	historyVersions int
+	historyAge      time.Duration
+
+	// valueCodec encodes values for JSON and gob, see WithValueCodec
+	valueCodec ValueCodec
+
+	// delim separates the segments of keys, if hasDelim is set, see
+	// WithListingDelimiter
+	delim    byte
+	hasDelim bool
+
+	// borrowKeys stores the byte slices given to InsertBytes rather
+	// than copies of them, see WithBorrowedKeys
+	borrowKeys bool
 }
 
 // New returns an empty Tree
-func New() *Tree {
-	return NewFromMap(nil)
+func New(opts ...Option) *Tree {
+	return NewFromMap(nil, opts...)
 }
 
 // NewFromMap returns a new tree containing the keys
 // from an existing map
-func NewFromMap(m map[string]interface{}) *Tree {
-	t := &Tree{root: &node{}}
+func NewFromMap(m map[string]interface{}, opts ...Option) *Tree {
+	t := &Tree{treeExt: &treeExt{}}
+	for _, opt := range opts {
+		opt(t)
+	}
+	t.root = t.newNode("", 0)
+	t.smallMode = t.smallMax > 0
+	t.reserve(t.capacity)
 	for k, v := range m {
 		t.Insert(k, v)
 	}
+	if t.wal != nil {
+		t.wal.durability = t.durability
+		latency := t.commitLatency
+		if latency == 0 && t.durability.async {
+			latency = defaultAsyncLatency
+		}
+		if latency > 0 {
+			t.wal.committer = newCommitter(t.wal.backend, t.wal.onErr, latency, t.commitBatch)
+		}
+	}
+	if t.janitor != nil {
+		t.janitor.start(t)
+	}
 	return t
 }
 
+// derive returns an empty tree with the same configuration as t
+func (t *Tree) derive() *Tree {
+	d := &Tree{treeExt: &treeExt{config: t.config}}
+	d.root = d.newNode("", 0)
+	d.smallMode = d.smallMax > 0
+	return d
+}
+
 // Len is used to return the number of elements in the tree
 func (t *Tree) Len() int {
 	return t.size
 }
 
+// Generation returns a number that changes whenever an entry is
+// added, removed or given a new value, so structures derived from
+// the tree can cheaply tell whether they are out of date. It never
+// repeats for the lifetime of the tree.
+func (t *Tree) Generation() uint64 {
+	return t.generation
+}
+
+// leafAdded is called whenever a new leaf is linked into the tree
+func (t *Tree) leafAdded(l *leafNode) {
+	t.mods++
+	t.generation++
+	t.absent.forget(l.key)
+	t.bytes += t.entryBytes(l.key, l.val)
+	t.persist(storeOp{key: l.key, val: l.val})
+	if t.insertionOrder {
+		t.order.push(l)
+	}
+	if t.lfuCap > 0 {
+		heap.Push(&t.lfu, l)
+	}
+	if t.keepHistory {
+		t.record(l.key, l.val, false)
+	}
+}
+
+// leafUpdated is called when an existing leaf's value is replaced
+func (t *Tree) leafUpdated(l *leafNode, old interface{}) {
+	t.generation++
+	if t.sizer != nil {
+		t.bytes += t.sizer(l.val) - t.sizer(old)
+	}
+	t.persist(storeOp{key: l.key, val: l.val})
+	if t.keepHistory {
+		t.record(l.key, l.val, false)
+	}
+}
+
+// leafRemoved is called whenever a leaf is unlinked from the tree
+func (t *Tree) leafRemoved(l *leafNode) {
+	t.mods++
+	t.generation++
+	t.leaves.remove(l)
+	t.bytes -= t.entryBytes(l.key, l.val)
+	t.persist(storeOp{del: true, key: l.key})
+	if t.insertionOrder {
+		t.order.remove(l)
+	}
+
+	// Once entries are deleted, new ones are allocated one by one, so
+	// they don't pin blocks that are otherwise emptying out
+	t.leafSlab, t.nodeSlab = nil, nil
+	if l.expires() != 0 {
+		t.expiry.remove(l)
+	}
+	if t.lfuCap > 0 {
+		heap.Remove(&t.lfu, l.ext.lfuIdx)
+	}
+	if t.keepHistory {
+		t.record(l.key, nil, true)
+	}
+}
+
+// persist passes a mutation on to the store and the log, if any
+func (t *Tree) persist(op storeOp) {
+	if t.store != nil {
+		t.store.write(op)
+	}
+	if t.wal != nil {
+		t.wal.writer.write(op)
+	}
+}
+
+// newNode allocates a node with the given prefix. end is the
+// length of the full key path through the node, which decides
+// whether its children are dispatched through a dense table.
+func (t *Tree) newNode(prefix string, end int) *node {
+	n := t.allocNode()
+	n.prefix = prefix
+	dense := end < t.denseDepth && !t.adaptive
+	if !dense && !t.counted() && t.collation == nil && !t.adaptive {
+		return n
+	}
+	n.ext = &nodeExt{collation: t.collation, adaptive: t.adaptive}
+	if dense {
+		n.ext.dense = new([256]*node)
+	}
+	if t.counted() {
+		n.ext.agg = &Aggregate{}
+	}
+	return n
+}
+
 // longestPrefix finds the length of the shared prefix
 // of two strings
 func longestPrefix(k1, k2 string) int {
@@ -150,7 +593,68 @@ func longestPrefix(k1, k2 string) int {
 
 // Insert is used to add a newentry or update
 // an existing entry. Returns true if an existing record is updated.
+//
+// Insert silently drops an entry the tree refuses: one that doesn't
+// fit its memory budget, or whose key breaks its key policy. It then
+// returns nil and false, exactly as for a new key that was stored,
+// and an existing entry keeps its old value. Trees with either limit
+// should be filled with TryInsert, which returns the reason instead.
+//
+// Keys are strings, so the tree never shares memory with a caller's
+// []byte buffer: converting one with string(b) copies it, and reusing
+// the buffer afterwards can't corrupt stored keys. InsertBytes copies
+// too, unless the tree was created with WithBorrowedKeys.
 func (t *Tree) Insert(s string, v interface{}) (interface{}, bool) {
+	old, updated, _ := t.TryInsert(s, v)
+	return old, updated
+}
+
+// TryInsert is like Insert, but returns an error instead of storing
+// the entry when the tree has been configured with limits it would
+// violate, such as a memory budget or a key policy.
+func (t *Tree) TryInsert(s string, v interface{}) (interface{}, bool, error) {
+	s = t.norm(s)
+	if err := t.checkInsert(s, v); err != nil {
+		return nil, false, err
+	}
+	old, updated := t.insert(s, v, t.defaultTTL)
+	return old, updated, nil
+}
+
+// checkInsert returns an error if storing the given entry would
+// violate the tree's limits
+func (t *Tree) checkInsert(s string, v interface{}) error {
+	if t.keyPolicy != nil {
+		if err := t.keyPolicy.check(s); err != nil {
+			return err
+		}
+	}
+	if t.budget > 0 {
+		return t.checkBudget(s, v)
+	}
+	return nil
+}
+
+// insert does the actual insertion, bypassing any limits. The entry
+// expires after ttl, or never if ttl is not positive.
+func (t *Tree) insert(s string, v interface{}, ttl time.Duration) (interface{}, bool) {
+	if t.lfuCap > 0 {
+		t.makeRoom(s)
+	}
+	leaf, old, updated := t.insertLeaf(s, v)
+	if ttl > 0 || leaf.expires() != 0 {
+		t.setExpiry(leaf, ttl)
+	}
+	t.refreshPath(s)
+	return old, updated
+}
+
+// insertLeaf adds or updates the leaf for s in the structure,
+// returning the leaf along with the previous value if any
+func (t *Tree) insertLeaf(s string, v interface{}) (*leafNode, interface{}, bool) {
+	if t.smallMode {
+		return t.insertSmall(s, v)
+	}
 	var parent *node
 	n := t.root
 	search := s
@@ -160,15 +664,17 @@ func (t *Tree) Insert(s string, v interface{}) (interface{}, bool) {
 			if n.isLeaf() {
 				old := n.leaf.val
 				n.leaf.val = v
-				return old, true
+				t.leafUpdated(n.leaf, old)
+				return n.leaf, old, true
 			}
 
-			n.leaf = &leafNode{
-				key: s,
-				val: v,
-			}
+			// Everything below n sorts after the new key
+			succ := minLeaf(n)
+			n.leaf = t.newLeaf(s, v)
+			t.leaves.insert(n.leaf, nil, succ)
 			t.size++
-			return nil, false
+			t.leafAdded(n.leaf)
+			return n.leaf, nil, false
 		}
 
 		// Look for the edge
@@ -179,17 +685,17 @@ func (t *Tree) Insert(s string, v interface{}) (interface{}, bool) {
 		if n == nil {
 			e := edge{
 				label: search[0],
-				node: &node{
-					leaf: &leafNode{
-						key: s,
-						val: v,
-					},
-					prefix: search,
-				},
+				node:  t.newNode(search, len(s)),
 			}
+			e.node.leaf = t.newLeaf(s, v)
 			parent.addEdge(e)
+			t.linkNewEdge(parent, search[0], e.node.leaf)
 			t.size++
-			return nil, false
+			t.leafAdded(e.node.leaf)
+			if debug {
+				assertValid("Insert", s, parent)
+			}
+			return e.node.leaf, nil, false
 		}
 
 		// Determine longest prefix of the search key on match
@@ -201,9 +707,7 @@ func (t *Tree) Insert(s string, v interface{}) (interface{}, bool) {
 
 		// Split the node
 		t.size++
-		child := &node{
-			prefix: search[:commonPrefix],
-		}
+		child := t.newNode(search[:commonPrefix], len(s)-len(search)+commonPrefix)
 		parent.updateEdge(search[0], child)
 
 		// Restore the existing node
@@ -214,33 +718,43 @@ func (t *Tree) Insert(s string, v interface{}) (interface{}, bool) {
 		n.prefix = n.prefix[commonPrefix:]
 
 		// Create a new leaf node
-		leaf := &leafNode{
-			key: s,
-			val: v,
-		}
+		leaf := t.newLeaf(s, v)
 
 		// If the new key is a subset, add to this node
 		search = search[commonPrefix:]
 		if len(search) == 0 {
 			child.leaf = leaf
-			return nil, false
+			t.leaves.insert(leaf, nil, minLeaf(n))
+			t.leafAdded(leaf)
+			if debug {
+				assertValid("Insert", s, parent, child)
+			}
+			return leaf, nil, false
 		}
 
 		// Create a new edge for the node
-		child.addEdge(edge{
+		e := edge{
 			label: search[0],
-			node: &node{
-				leaf:   leaf,
-				prefix: search,
-			},
-		})
-		return nil, false
+			node:  t.newNode(search, len(s)),
+		}
+		e.node.leaf = leaf
+		child.addEdge(e)
+		t.linkNewEdge(child, search[0], leaf)
+		t.leafAdded(leaf)
+		if debug {
+			assertValid("Insert", s, parent, child)
+		}
+		return leaf, nil, false
 	}
 }
 
 // Delete is used to delete a key, returning the previous
 // value and if it was deleted
 func (t *Tree) Delete(s string) (interface{}, bool) {
+	s = t.norm(s)
+	if t.smallMode {
+		return t.deleteSmall(s)
+	}
 	var parent *node
 	var label byte
 	n := t.root
@@ -276,6 +790,7 @@ DELETE:
 	leaf := n.leaf
 	n.leaf = nil
 	t.size--
+	t.leafRemoved(leaf)
 
 	// Check if we should delete this node from the parent
 	if parent != nil && len(n.edges) == 0 {
@@ -292,6 +807,10 @@ DELETE:
 		parent.mergeChild()
 	}
 
+	if debug {
+		assertValid("Delete", s, parent, n)
+	}
+	t.refreshPath(s)
 	return leaf.val, true
 }
 
@@ -299,29 +818,56 @@ DELETE:
 // Returns how many nodes were deleted
 // Use this to delete large subtrees efficiently
 func (t *Tree) DeletePrefix(s string) int {
-	return t.deletePrefix(nil, t.root, s)
+	s = t.norm(s)
+	w := t.watch("DeletePrefix")
+	var n int
+	if t.smallMode {
+		i, j := t.prefixRangeSmall(s)
+		t.removeSmall(i, j)
+		n = j - i
+	} else {
+		n = t.deletePrefix(nil, t.root, s, s)
+	}
+	if n > 0 {
+		t.refreshPath(s)
+	}
+	if w != nil {
+		w.visited = n
+		w.done()
+	}
+	return n
 }
 
-// delete does a recursive deletion
-func (t *Tree) deletePrefix(parent, n *node, prefix string) int {
+// delete does a recursive deletion. orig is the prefix passed to
+// DeletePrefix and is only used for diagnostics.
+func (t *Tree) deletePrefix(parent, n *node, prefix, orig string) int {
 	// Check for key exhaustion
 	if len(prefix) == 0 {
 		// Remove the leaf node
 		subTreeSize := 0
 		//recursively walk from all edges of the node to be deleted
-		recursiveWalk(n, func(s string, v interface{}) bool {
+		forEachLeaf(n, func(l *leafNode) {
 			subTreeSize++
-			return false
+			t.leafRemoved(l)
 		})
 		if n.isLeaf() {
 			n.leaf = nil
 		}
-		n.edges = nil // deletes the entire subtree
+		n.clearEdges() // deletes the entire subtree
+
+		// Detach the now empty node so it can't shadow its siblings
+		// in Minimum, Maximum and friends
+		if parent != nil {
+			parent.delEdge(n.prefix[0])
+		}
 
 		// Check if we should merge the parent's other child
 		if parent != nil && parent != t.root && len(parent.edges) == 1 && !parent.isLeaf() {
 			parent.mergeChild()
 		}
+		if debug {
+			assertValid("DeletePrefix", orig, parent, n)
+		}
 		t.size -= subTreeSize
 		return subTreeSize
 	}
@@ -339,29 +885,108 @@ func (t *Tree) deletePrefix(parent, n *node, prefix string) int {
 	} else {
 		prefix = prefix[len(child.prefix):]
 	}
-	return t.deletePrefix(n, child, prefix)
+	return t.deletePrefix(n, child, prefix, orig)
 }
 
 func (n *node) mergeChild() {
 	e := n.edges[0]
 	child := e.node
-	n.prefix = n.prefix + child.prefix
+	n.prefix = concatPrefix(n.prefix, child)
 	n.leaf = child.leaf
 	n.edges = child.edges
+	n.ext = child.ext
+}
+
+// concatPrefix returns prefix + child.prefix. Every leaf under
+// child has a key that already contains those bytes, so where
+// possible the result is sliced out of such a key instead of
+// allocating a new string.
+func concatPrefix(prefix string, child *node) string {
+	total := len(prefix) + len(child.prefix)
+	rest := 0
+	for n := child; ; {
+		if n.leaf != nil {
+			key := n.leaf.key
+			end := len(key) - rest
+			if start := end - total; start >= 0 {
+				return key[start:end]
+			}
+			break
+		}
+		if len(n.edges) == 0 {
+			break
+		}
+		n = n.edges[0].node
+		rest += len(n.prefix)
+	}
+	return prefix + child.prefix
 }
 
 // Get is used to lookup a specific key, returning
-// the value and if it was found
+// the value and if it was found. If the tree has a loader
+// configured, missing keys are loaded and inserted.
+//
+// Entries past their TTL are not returned, and are evicted.
 func (t *Tree) Get(s string) (interface{}, bool) {
+	s = t.norm(s)
+	if v, ok := t.lookup(s); ok || t.loader == nil {
+		return v, ok
+	}
+	return t.load(s)
+}
+
+// lookup is Get for the normalized key s, without the loader
+func (t *Tree) lookup(s string) (interface{}, bool) {
+	if t.profile != nil {
+		t.profile.sample(s)
+	}
+	l := t.getLeaf(s)
+	if l != nil && t.expired(l) {
+		t.expire(l)
+		l = nil
+	}
+	if l == nil {
+		return nil, false
+	}
+	if t.countHits {
+		t.hit(l)
+	}
+	return l.val, true
+}
+
+// peek is a lookup of the normalized key s that changes nothing: an
+// expired entry is reported missing rather than evicted, and no hit
+// is counted and no sample taken
+func (t *Tree) peek(s string) (interface{}, bool) {
+	l := t.getLeaf(s)
+	if l == nil || t.expired(l) {
+		return nil, false
+	}
+	return l.val, true
+}
+
+// get is the plain lookup behind Get
+func (t *Tree) get(s string) (interface{}, bool) {
+	if l := t.getLeaf(s); l != nil {
+		return l.val, true
+	}
+	return nil, false
+}
+
+// getLeaf returns the leaf for s, or nil if there is none
+func (t *Tree) getLeaf(s string) *leafNode {
+	if t.smallMode {
+		if i, found := t.searchSmall(s); found {
+			return t.small[i]
+		}
+		return nil
+	}
 	n := t.root
 	search := s
 	for {
 		// Check for key exhaution
 		if len(search) == 0 {
-			if n.isLeaf() {
-				return n.leaf.val, true
-			}
-			break
+			return n.leaf
 		}
 
 		// Look for an edge
@@ -377,12 +1002,29 @@ func (t *Tree) Get(s string) (interface{}, bool) {
 			break
 		}
 	}
-	return nil, false
+	return nil
 }
 
 // LongestPrefix is like Get, but instead of an
 // exact match, it will return the longest prefix match.
 func (t *Tree) LongestPrefix(s string) (string, interface{}, bool) {
+	s = t.norm(s)
+	if t.profile != nil {
+		t.profile.sample(s)
+	}
+	return t.longestMatch(s)
+}
+
+// longestMatch is LongestPrefix for the normalized key s, without
+// sampling it
+func (t *Tree) longestMatch(s string) (string, interface{}, bool) {
+	if t.smallMode {
+		if path := t.pathSmall(s); len(path) > 0 {
+			l := path[len(path)-1]
+			return l.key, l.val, true
+		}
+		return "", nil, false
+	}
 	var last *leafNode
 	n := t.root
 	search := s
@@ -418,49 +1060,53 @@ func (t *Tree) LongestPrefix(s string) (string, interface{}, bool) {
 
 // Minimum is used to return the minimum value in the tree
 func (t *Tree) Minimum() (string, interface{}, bool) {
-	n := t.root
-	for {
-		if n.isLeaf() {
-			return n.leaf.key, n.leaf.val, true
-		}
-		if len(n.edges) > 0 {
-			n = n.edges[0].node
-		} else {
-			break
-		}
+	if l := t.leaves.head; l != nil {
+		return l.key, l.val, true
 	}
 	return "", nil, false
 }
 
 // Maximum is used to return the maximum value in the tree
 func (t *Tree) Maximum() (string, interface{}, bool) {
-	n := t.root
-	for {
-		if num := len(n.edges); num > 0 {
-			n = n.edges[num-1].node
-			continue
-		}
-		if n.isLeaf() {
-			return n.leaf.key, n.leaf.val, true
-		}
-		break
+	if l := t.leaves.tail; l != nil {
+		return l.key, l.val, true
 	}
 	return "", nil, false
 }
 
-// Walk is used to walk the tree
+// Walk is used to walk the tree. fn may modify the tree, and the walk
+// carries on over the result; use WalkChecked to stop with an error
+// instead, or WalkDelete to delete entries as they are visited.
 func (t *Tree) Walk(fn WalkFn) {
-	recursiveWalk(t.root, fn)
+	if w := t.watch("Walk"); w != nil {
+		defer w.done()
+		fn = w.count(fn)
+	}
+	if t.smallMode {
+		t.walkSmall(0, len(t.small), fn)
+		return
+	}
+	t.walk(t.root, fn)
 }
 
 // WalkPrefix is used to walk the tree under a prefix
 func (t *Tree) WalkPrefix(prefix string, fn WalkFn) {
+	prefix = t.norm(prefix)
+	if w := t.watch("WalkPrefix"); w != nil {
+		defer w.done()
+		fn = w.count(fn)
+	}
+	if t.smallMode {
+		i, j := t.prefixRangeSmall(prefix)
+		t.walkSmall(i, j, fn)
+		return
+	}
 	n := t.root
 	search := prefix
 	for {
 		// Check for key exhaustion
 		if len(search) == 0 {
-			recursiveWalk(n, fn)
+			t.walk(n, fn)
 			return
 		}
 
@@ -477,17 +1123,40 @@ func (t *Tree) WalkPrefix(prefix string, fn WalkFn) {
 		}
 		if strings.HasPrefix(n.prefix, search) {
 			// Child may be under our search prefix
-			recursiveWalk(n, fn)
+			t.walk(n, fn)
 		}
 		return
 	}
 }
 
+// WalkPrefixRelative is like WalkPrefix, but passes fn each key with
+// prefix stripped off. The relative key is a substring of the stored
+// key, so this costs no allocation per entry.
+func (t *Tree) WalkPrefixRelative(prefix string, fn WalkFn) {
+	prefix = t.norm(prefix)
+	t.WalkPrefix(prefix, func(k string, v interface{}) bool {
+		return fn(k[len(prefix):], v)
+	})
+}
+
 // WalkPath is used to walk the tree, but only visiting nodes
 // from the root down to a given leaf. Where WalkPrefix walks
 // all the entries *under* the given prefix, this walks the
 // entries *above* the given prefix.
 func (t *Tree) WalkPath(path string, fn WalkFn) {
+	t.walkPath(t.norm(path), fn)
+}
+
+// walkPath is WalkPath for the normalized path
+func (t *Tree) walkPath(path string, fn WalkFn) {
+	if t.smallMode {
+		for _, l := range t.pathSmall(path) {
+			if fn(l.key, l.val) {
+				return
+			}
+		}
+		return
+	}
 	n := t.root
 	search := path
 	for {
@@ -516,6 +1185,17 @@ func (t *Tree) WalkPath(path string, fn WalkFn) {
 	}
 }
 
+// forEachLeaf calls fn for every leaf under n in order. Unlike
+// recursiveWalk, fn must not modify the tree.
+func forEachLeaf(n *node, fn func(*leafNode)) {
+	if n.leaf != nil {
+		fn(n.leaf)
+	}
+	for _, e := range n.edges {
+		forEachLeaf(e.node, fn)
+	}
+}
+
 // recursiveWalk is used to do a pre-order walk of a node
 // recursively. Returns true if the walk should be aborted
 func recursiveWalk(n *node, fn WalkFn) bool {
@@ -87,6 +87,104 @@ func TestRoot(t *testing.T) {
 	}
 }
 
+func TestEmptyKey(t *testing.T) {
+	r := New()
+	r.Insert("", "root")
+	r.Insert("A", "a")
+	r.Insert("AB", "ab")
+
+	if val, ok := r.Get(""); !ok || val != "root" {
+		t.Fatalf("bad: %v %v", val, ok)
+	}
+	if k, _, ok := r.Minimum(); !ok || k != "" {
+		t.Fatalf("bad minimum: %q %v", k, ok)
+	}
+	if k, _, ok := r.LongestPrefix("zzz"); !ok || k != "" {
+		t.Fatalf("bad longest prefix: %q %v", k, ok)
+	}
+
+	out := []string{}
+	r.WalkPath("", func(s string, v interface{}) bool {
+		out = append(out, s)
+		return false
+	})
+	if !reflect.DeepEqual(out, []string{""}) {
+		t.Fatalf("bad walk path: %v", out)
+	}
+
+	// Removing everything under "A" must leave the root leaf as
+	// both the minimum and the maximum
+	if n := r.DeletePrefix("A"); n != 2 {
+		t.Fatalf("bad delete count: %d", n)
+	}
+	if k, _, ok := r.Minimum(); !ok || k != "" {
+		t.Fatalf("bad minimum: %q %v", k, ok)
+	}
+	if k, _, ok := r.Maximum(); !ok || k != "" {
+		t.Fatalf("bad maximum: %q %v", k, ok)
+	}
+
+	// Deleting the empty prefix removes every key, including ""
+	r.Insert("B", "b")
+	if n := r.DeletePrefix(""); n != 2 {
+		t.Fatalf("bad delete count: %d", n)
+	}
+	if _, ok := r.Get(""); ok {
+		t.Fatalf("root leaf should be gone")
+	}
+	if _, _, ok := r.Minimum(); ok {
+		t.Fatalf("tree should be empty")
+	}
+	if _, ok := r.Delete(""); ok {
+		t.Fatalf("bad")
+	}
+	if r.Len() != 0 {
+		t.Fatalf("bad len: %d", r.Len())
+	}
+}
+
+func TestDeletePrefix_Siblings(t *testing.T) {
+	r := New()
+	for _, k := range []string{"A", "AB", "ABC", "R"} {
+		r.Insert(k, nil)
+	}
+	r.DeletePrefix("A")
+	if k, _, ok := r.Minimum(); !ok || k != "R" {
+		t.Fatalf("bad minimum: %q %v", k, ok)
+	}
+	if err := r.root.validate(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(r.root.edges) != 1 {
+		t.Fatalf("empty node left behind: %v", r.root.edges)
+	}
+}
+
+func TestDelete_Merge(t *testing.T) {
+	r := New()
+	keys := []string{"foo", "foobar", "foobaz", "foobazzip", "foozip"}
+	for _, k := range keys {
+		r.Insert(k, k)
+	}
+	for _, k := range []string{"foobar", "foozip", "foo"} {
+		if _, ok := r.Delete(k); !ok {
+			t.Fatalf("missing key: %v", k)
+		}
+		validateTree(t, r.root)
+	}
+	if len(r.root.edges) != 1 {
+		t.Fatalf("bad edges: %v", r.root.edges)
+	}
+	if n := r.root.edges[0].node; n.prefix != "foobaz" {
+		t.Fatalf("bad merged prefix: %q", n.prefix)
+	}
+	for _, k := range []string{"foobaz", "foobazzip"} {
+		if v, ok := r.Get(k); !ok || v != k {
+			t.Fatalf("bad: %v %v", k, v)
+		}
+	}
+}
+
 func TestDelete(t *testing.T) {
 
 	r := New()
@@ -272,6 +370,34 @@ func TestWalkPrefix(t *testing.T) {
 	}
 }
 
+func TestWalkPrefixRelative(t *testing.T) {
+	r := New()
+	for _, k := range []string{"foo", "foo/bar", "foo/baz", "food", "zip"} {
+		r.Insert(k, k)
+	}
+
+	var out []string
+	r.WalkPrefixRelative("foo/", func(s string, v interface{}) bool {
+		if "foo/"+s != v {
+			t.Fatalf("bad: %q %v", s, v)
+		}
+		out = append(out, s)
+		return false
+	})
+	if !reflect.DeepEqual(out, []string{"bar", "baz"}) {
+		t.Fatalf("bad: %q", out)
+	}
+
+	out = out[:0]
+	r.WalkPrefixRelative("foo", func(s string, v interface{}) bool {
+		out = append(out, s)
+		return len(out) == 3
+	})
+	if !reflect.DeepEqual(out, []string{"", "/bar", "/baz"}) {
+		t.Fatalf("bad: %q", out)
+	}
+}
+
 func TestWalkPath(t *testing.T) {
 	r := New()
 
@@ -378,6 +504,118 @@ func TestWalkDelete(t *testing.T) {
 	}
 }
 
+func TestValidate(t *testing.T) {
+	r := New()
+	for _, k := range []string{"foo", "foobar", "fizz", "bar", "baz"} {
+		r.Insert(k, nil)
+	}
+	if err := r.root.validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Swap two edges to break ordering
+	r.root.edges[0], r.root.edges[1] = r.root.edges[1], r.root.edges[0]
+	if err := r.root.validate(); err == nil {
+		t.Fatalf("expected out of order edges to be detected")
+	}
+	r.root.edges[0], r.root.edges[1] = r.root.edges[1], r.root.edges[0]
+
+	// Corrupt a label
+	r.root.edges[0].label = 'x'
+	if err := r.root.validate(); err == nil {
+		t.Fatalf("expected label mismatch to be detected")
+	}
+}
+
+func TestEdgeIndex(t *testing.T) {
+	// Exercise both the linear scan and the binary search
+	for _, fanout := range []int{1, linearSearchThreshold - 1, linearSearchThreshold, 64} {
+		n := &node{}
+		for i := 0; i < fanout; i++ {
+			label := byte(2 * i)
+			n.addEdge(edge{label: label, node: &node{prefix: string([]byte{label})}})
+		}
+		if err := n.validate(); err != nil {
+			t.Fatalf("fanout %d: %v", fanout, err)
+		}
+		for i := 0; i < 2*fanout; i++ {
+			got := n.getEdge(byte(i))
+			if i%2 == 0 && (got == nil || got.prefix[0] != byte(i)) {
+				t.Fatalf("fanout %d: missing edge %d", fanout, i)
+			}
+			if i%2 == 1 && got != nil {
+				t.Fatalf("fanout %d: unexpected edge %d", fanout, i)
+			}
+			if idx := n.edgeIndex(byte(i)); idx != (i+1)/2 {
+				t.Fatalf("fanout %d: bad index for %d: %d", fanout, i, idx)
+			}
+		}
+	}
+}
+
+func TestDenseDispatch(t *testing.T) {
+	r := New(WithDenseDispatch(3))
+	if r.root.dense() == nil {
+		t.Fatalf("root should be dense")
+	}
+
+	inp := make(map[string]interface{})
+	for i := 0; i < 1000; i++ {
+		inp[generateUUID()] = i
+	}
+	inp[""] = -1
+	inp["a"] = -2
+	for k, v := range inp {
+		r.Insert(k, v)
+	}
+	validateTree(t, r.root)
+
+	for k, v := range inp {
+		out, ok := r.Get(k)
+		if !ok || out != v {
+			t.Fatalf("bad: %v %v %v", k, out, v)
+		}
+	}
+
+	i := 0
+	for k, v := range inp {
+		if i%2 == 0 {
+			out, ok := r.Delete(k)
+			if !ok || out != v {
+				t.Fatalf("bad delete: %v %v %v", k, out, v)
+			}
+			delete(inp, k)
+		}
+		i++
+	}
+	validateTree(t, r.root)
+	for k := range inp {
+		if _, ok := r.Get(k); !ok {
+			t.Fatalf("missing key: %v", k)
+		}
+	}
+
+	r.DeletePrefix("")
+	validateTree(t, r.root)
+	if r.Len() != 0 {
+		t.Fatalf("bad len: %d", r.Len())
+	}
+	if _, ok := r.Get("a"); ok {
+		t.Fatalf("should be gone")
+	}
+}
+
+// validateTree checks the invariants of every node under n.
+func validateTree(t *testing.T, n *node) {
+	t.Helper()
+	if err := n.validate(); err != nil {
+		t.Fatalf("node %q: %v", n.prefix, err)
+	}
+	for _, e := range n.edges {
+		validateTree(t, e.node)
+	}
+}
+
 // generateUUID is used to generate a random UUID
 func generateUUID() string {
 	buf := make([]byte, 16)
@@ -406,3 +644,18 @@ func BenchmarkInsert(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkGet(b *testing.B) {
+	r := New()
+	keys := make([]string, 10000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("init%d", i)
+		r.Insert(keys[i], true)
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, ok := r.Get(keys[n%len(keys)]); !ok {
+			b.Fatal("bad")
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package radix
+
+// ReadOnly gives access to a tree without any way of modifying it, so
+// a tree can be handed to code that must only read it with the
+// compiler enforcing that. See Tree.ReadOnly.
+type ReadOnly struct {
+	t *Tree
+}
+
+// ReadOnly returns a read-only handle on t. It reflects later changes
+// made to t through other references.
+func (t *Tree) ReadOnly() ReadOnly {
+	return ReadOnly{t: t}
+}
+
+// Get looks up a key, like Tree.Get, but leaves the tree untouched:
+// it doesn't run the tree's loader or count a hit, and it reports an
+// expired entry as missing without evicting it or calling the
+// OnExpire callbacks.
+func (r ReadOnly) Get(k string) (interface{}, bool) {
+	return r.t.peek(r.t.norm(k))
+}
+
+// Len returns the number of entries, like Tree.Len
+func (r ReadOnly) Len() int {
+	return r.t.Len()
+}
+
+// LongestPrefix finds the longest key that is a prefix of s, like
+// Tree.LongestPrefix, without sampling s for the prefix profiler
+func (r ReadOnly) LongestPrefix(s string) (string, interface{}, bool) {
+	return r.t.longestMatch(r.t.norm(s))
+}
+
+// Minimum returns the smallest entry, like Tree.Minimum
+func (r ReadOnly) Minimum() (string, interface{}, bool) {
+	return r.t.Minimum()
+}
+
+// Maximum returns the largest entry, like Tree.Maximum
+func (r ReadOnly) Maximum() (string, interface{}, bool) {
+	return r.t.Maximum()
+}
+
+// Successor returns the next entry after s, like Tree.Successor
+func (r ReadOnly) Successor(s string) (string, interface{}, bool) {
+	return r.t.Successor(s)
+}
+
+// Predecessor returns the entry before s, like Tree.Predecessor
+func (r ReadOnly) Predecessor(s string) (string, interface{}, bool) {
+	return r.t.Predecessor(s)
+}
+
+// Walk visits every entry, like Tree.Walk
+func (r ReadOnly) Walk(fn WalkFn) {
+	r.t.Walk(fn)
+}
+
+// WalkPrefix visits the entries under prefix, like Tree.WalkPrefix
+func (r ReadOnly) WalkPrefix(prefix string, fn WalkFn) {
+	r.t.WalkPrefix(prefix, fn)
+}
+
+// WalkPath visits the entries whose keys are prefixes of path, like
+// Tree.WalkPath
+func (r ReadOnly) WalkPath(path string, fn WalkFn) {
+	r.t.WalkPath(path, fn)
+}
+
+// ToMap returns the entries as a map, like Tree.ToMap
+func (r ReadOnly) ToMap() map[string]interface{} {
+	return r.t.ToMap()
+}
@@ -0,0 +1,86 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReadOnly(t *testing.T) {
+	r := New()
+	r.Insert("a", 1)
+	r.Insert("ab", 2)
+	r.Insert("b", 3)
+	ro := r.ReadOnly()
+	var _ View = ro
+
+	if v, ok := ro.Get("ab"); !ok || v != 2 || ro.Len() != 3 {
+		t.Fatalf("bad: %v %v", v, ok)
+	}
+	if k, _, _ := ro.LongestPrefix("abc"); k != "ab" {
+		t.Fatalf("bad: %q", k)
+	}
+	if k, _, _ := ro.Minimum(); k != "a" {
+		t.Fatalf("bad: %q", k)
+	}
+	if k, _, _ := ro.Maximum(); k != "b" {
+		t.Fatalf("bad: %q", k)
+	}
+	if k, _, _ := ro.Successor("a"); k != "ab" {
+		t.Fatalf("bad: %q", k)
+	}
+	if k, _, _ := ro.Predecessor("ab"); k != "a" {
+		t.Fatalf("bad: %q", k)
+	}
+	var keys []string
+	collect := func(k string, v interface{}) bool {
+		keys = append(keys, k)
+		return false
+	}
+	ro.Walk(collect)
+	ro.WalkPrefix("a", collect)
+	ro.WalkPath("ab", collect)
+	if !reflect.DeepEqual(keys, []string{"a", "ab", "b", "a", "ab", "a", "ab"}) {
+		t.Fatalf("bad: %q", keys)
+	}
+
+	// Changes made through the tree show through
+	r.Delete("a")
+	if !reflect.DeepEqual(ro.ToMap(), map[string]interface{}{"ab": 2, "b": 3}) {
+		t.Fatalf("bad: %v", ro.ToMap())
+	}
+}
+
+func TestReadOnly_NoSideEffects(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	var loads, expired int
+	r := New(WithClock(clock), WithLFU(0), WithPrefixProfiler(1, 1), WithLoader(func(string) (interface{}, bool) {
+		loads++
+		return "loaded", true
+	}))
+	r.OnExpire(func(string, interface{}) { expired++ })
+	r.Insert("foo", 1)
+	r.InsertWithTTL("bar", 2, time.Second)
+	clock.advance(time.Minute)
+	ro := r.ReadOnly()
+
+	if v, ok := ro.Get("foo"); !ok || v != 1 {
+		t.Fatalf("bad: %v %v", v, ok)
+	}
+	if _, ok := ro.Get("bar"); ok {
+		t.Fatalf("expected bar to have expired")
+	}
+	if _, ok := ro.Get("baz"); ok {
+		t.Fatalf("expected baz to be missing")
+	}
+	ro.LongestPrefix("foobar")
+	if hits, _ := r.Hits("foo"); hits != 0 {
+		t.Fatalf("bad: %d", hits)
+	}
+	if loads != 0 || expired != 0 || r.Len() != 2 {
+		t.Fatalf("bad: %d %d %d", loads, expired, r.Len())
+	}
+	if p := r.HotPrefixes(10); len(p) != 0 {
+		t.Fatalf("bad: %v", p)
+	}
+}
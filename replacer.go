@@ -0,0 +1,83 @@
+package radix
+
+import (
+	"io"
+	"strings"
+)
+
+// Replacer replaces the keys of a tree wherever they occur in a text,
+// like a strings.Replacer. It is built by Tree.Replacer, and is
+// immutable and safe for concurrent use.
+type Replacer struct {
+	ac   *AhoCorasick
+	repl []string
+}
+
+// Replacer returns a Replacer that substitutes every occurrence of a
+// key currently in the tree with fn of the key and its value. fn is
+// called once per key, up front. Where occurrences overlap, the one
+// starting first wins, and of those starting at the same place the
+// longest. The empty key is never replaced.
+func (t *Tree) Replacer(fn func(key string, v interface{}) string) *Replacer {
+	ac := t.ToAhoCorasick()
+	r := &Replacer{ac: ac, repl: make([]string, len(ac.keys))}
+	for i, k := range ac.keys {
+		r.repl[i] = fn(k, ac.vals[i])
+	}
+	return r
+}
+
+// Replace returns a copy of s with all replacements performed
+func (r *Replacer) Replace(s string) string {
+	var b strings.Builder
+	r.WriteString(&b, s)
+	return b.String()
+}
+
+// WriteString writes s to w with all replacements performed
+func (r *Replacer) WriteString(w io.Writer, s string) (int, error) {
+	// The longest match starting at each offset, plus one so zero
+	// means none. One pass over the matches finds them all.
+	best := make([]int32, len(s))
+	r.ac.scan(s, func(k int32, start int) bool {
+		if b := best[start]; b == 0 || len(r.ac.keys[b-1]) < len(r.ac.keys[k]) {
+			best[start] = k + 1
+		}
+		return false
+	})
+
+	sw, ok := w.(io.StringWriter)
+	if !ok {
+		sw = stringWriter{w}
+	}
+	var n int
+	last := 0
+	for i := 0; i < len(s); {
+		k := best[i]
+		if k == 0 {
+			i++
+			continue
+		}
+		k--
+		for _, part := range []string{s[last:i], r.repl[k]} {
+			m, err := sw.WriteString(part)
+			n += m
+			if err != nil {
+				return n, err
+			}
+		}
+		i += len(r.ac.keys[k])
+		last = i
+	}
+	m, err := sw.WriteString(s[last:])
+	return n + m, err
+}
+
+// stringWriter adapts an io.Writer to io.StringWriter
+type stringWriter struct {
+	w io.Writer
+}
+
+func (w stringWriter) WriteString(s string) (int, error) {
+	return w.w.Write([]byte(s))
+}
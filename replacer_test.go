@@ -0,0 +1,38 @@
+package radix
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReplacer(t *testing.T) {
+	r := New()
+	for _, k := range []string{"", "a", "ab", "abc", "bcd", "cat"} {
+		r.Insert(k, strings.ToUpper(k))
+	}
+	rep := r.Replacer(func(k string, v interface{}) string {
+		return "<" + v.(string) + ">"
+	})
+	for in, want := range map[string]string{
+		"":          "",
+		"xyz":       "xyz",
+		"abcd":      "<ABC>d",
+		"xbcdabx":   "x<BCD><AB>x",
+		"concat":    "con<CAT>",
+		"aaa":       "<A><A><A>",
+		"bcat abcd": "b<CAT> <ABC>d",
+	} {
+		if got := rep.Replace(in); got != want {
+			t.Fatalf("%q: got %q, want %q", in, got, want)
+		}
+	}
+
+	// Through a plain io.Writer
+	var buf bytes.Buffer
+	n, err := rep.WriteString(struct{ io.Writer }{&buf}, "xabx")
+	if err != nil || buf.String() != "x<AB>x" || n != buf.Len() {
+		t.Fatalf("bad: %q %d %v", buf.String(), n, err)
+	}
+}
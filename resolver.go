@@ -0,0 +1,78 @@
+package radix
+
+// Resolver resolves feature flags set at hierarchical scopes, such as
+// "org/", "org/team/" and "org/team/user". A flag's setting at a scope
+// applies to every scope key the scope is a prefix of, and the most
+// specific, longest, scope wins. Scopes are plain prefixes, so they
+// should end with a separator to keep "org/team/" from covering
+// "org/teammate".
+type Resolver struct {
+	t *Tree
+}
+
+// NewResolver returns a resolver with no flags set
+func NewResolver() *Resolver {
+	return &Resolver{t: New()}
+}
+
+// Set sets flag to v at scope
+func (r *Resolver) Set(scope, flag string, v interface{}) {
+	flags := map[string]interface{}{flag: v}
+	if old, ok := r.t.Get(scope); ok {
+		for f, v := range old.(map[string]interface{}) {
+			if f != flag {
+				flags[f] = v
+			}
+		}
+	}
+	r.t.Insert(scope, flags)
+}
+
+// Unset removes the setting of flag at scope, so it is inherited
+// again, and reports whether there was one
+func (r *Resolver) Unset(scope, flag string) bool {
+	old, ok := r.t.Get(scope)
+	if !ok {
+		return false
+	}
+	if _, ok := old.(map[string]interface{})[flag]; !ok {
+		return false
+	}
+	flags := make(map[string]interface{})
+	for f, v := range old.(map[string]interface{}) {
+		if f != flag {
+			flags[f] = v
+		}
+	}
+	if len(flags) == 0 {
+		r.t.Delete(scope)
+	} else {
+		r.t.Insert(scope, flags)
+	}
+	return true
+}
+
+// Resolve returns the setting of flag at the most specific scope
+// covering scopeKey, and the scope it was set at
+func (r *Resolver) Resolve(scopeKey, flag string) (v interface{}, scope string, ok bool) {
+	r.t.WalkPath(scopeKey, func(s string, flags interface{}) bool {
+		if fv, found := flags.(map[string]interface{})[flag]; found {
+			v, scope, ok = fv, s, true
+		}
+		return false
+	})
+	return v, scope, ok
+}
+
+// ResolveAll returns the setting of every flag that applies to
+// scopeKey, each from the most specific scope setting it
+func (r *Resolver) ResolveAll(scopeKey string) map[string]interface{} {
+	out := make(map[string]interface{})
+	r.t.WalkPath(scopeKey, func(_ string, flags interface{}) bool {
+		for f, v := range flags.(map[string]interface{}) {
+			out[f] = v
+		}
+		return false
+	})
+	return out
+}
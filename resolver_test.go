@@ -0,0 +1,50 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolver(t *testing.T) {
+	r := NewResolver()
+	r.Set("", "beta", false)
+	r.Set("org/", "beta", true)
+	r.Set("org/", "theme", "dark")
+	r.Set("org/team/", "beta", false)
+	r.Set("org/team/alice", "theme", "light")
+
+	cases := []struct {
+		key, flag string
+		v         interface{}
+		scope     string
+	}{
+		{"org/team/alice", "beta", false, "org/team/"},
+		{"org/team/alice", "theme", "light", "org/team/alice"},
+		{"org/team/bob", "theme", "dark", "org/"},
+		{"org/other/carol", "beta", true, "org/"},
+		{"elsewhere", "beta", false, ""},
+	}
+	for _, c := range cases {
+		v, scope, ok := r.Resolve(c.key, c.flag)
+		if !ok || v != c.v || scope != c.scope {
+			t.Fatalf("%s %s: got %v %q %v", c.key, c.flag, v, scope, ok)
+		}
+	}
+	if _, _, ok := r.Resolve("elsewhere", "theme"); ok {
+		t.Fatalf("expected no theme")
+	}
+	want := map[string]interface{}{"beta": false, "theme": "light"}
+	if got := r.ResolveAll("org/team/alice"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("bad: %v", got)
+	}
+
+	if !r.Unset("org/team/", "beta") || r.Unset("org/team/", "beta") {
+		t.Fatalf("bad unset")
+	}
+	if v, _, _ := r.Resolve("org/team/alice", "beta"); v != true {
+		t.Fatalf("bad: %v", v)
+	}
+	if r.t.Len() != 3 {
+		t.Fatalf("expected the empty scope to be removed: %v", r.t.ToMap())
+	}
+}
@@ -0,0 +1,103 @@
+package radix
+
+import "strings"
+
+// ReverseWalk visits every entry in descending key order until fn
+// returns true. fn may modify the tree; the walk then carries on from
+// the last key before the one just visited. It ignores
+// WithNaturalOrder.
+func (t *Tree) ReverseWalk(fn WalkFn) {
+	t.ReverseWalkPrefix("", fn)
+}
+
+// ReverseWalkPrefix is like ReverseWalk, but only visits the entries
+// whose keys start with prefix
+func (t *Tree) ReverseWalkPrefix(prefix string, fn WalkFn) {
+	it := t.ReverseIterator()
+	it.SeekPrefix(prefix)
+	for {
+		k, v, ok := it.Previous()
+		if !ok || fn(k, v) {
+			return
+		}
+	}
+}
+
+// ReverseIterator pulls the entries of a tree one at a time in
+// descending key order, as Iterator does in ascending order
+type ReverseIterator struct {
+	t *Tree
+
+	// prev is the leaf Previous returns, valid while mods matches
+	// the tree's
+	prev *leafNode
+	mods uint64
+
+	// pos is where the iterator stands: prev is the last key before
+	// pos, or at or before it if inclusive. end is set while the
+	// iterator stands at the end of the keys starting with prefix.
+	pos       string
+	inclusive bool
+	end       bool
+
+	// prefix bounds the keys returned
+	prefix string
+}
+
+// ReverseIterator returns an iterator positioned at the end of the
+// tree
+func (t *Tree) ReverseIterator() *ReverseIterator {
+	it := &ReverseIterator{t: t}
+	it.SeekPrefix("")
+	return it
+}
+
+// SeekPrefix positions the iterator at the last key starting with
+// prefix, and limits it to those keys
+func (it *ReverseIterator) SeekPrefix(prefix string) {
+	it.prefix = it.t.norm(prefix)
+	it.end = true
+	it.seek()
+}
+
+// SeekReverseLowerBound positions the iterator at the last key at or
+// before key, with no limit
+func (it *ReverseIterator) SeekReverseLowerBound(key string) {
+	it.prefix, it.end = "", false
+	it.pos, it.inclusive = it.t.norm(key), true
+	it.seek()
+}
+
+// seek finds prev from the iterator's position
+func (it *ReverseIterator) seek() {
+	it.mods = it.t.mods
+	if it.end {
+		if l := it.t.prefixEnd(it.prefix); l != nil {
+			it.prev = l.prev
+		} else {
+			it.prev = it.t.leaves.tail
+		}
+		return
+	}
+	exact, pred, _ := it.t.seek(it.pos)
+	if it.inclusive && exact != nil {
+		pred = exact
+	}
+	it.prev = pred
+}
+
+// Previous returns the previous entry, or false once there are none
+// left
+func (it *ReverseIterator) Previous() (string, interface{}, bool) {
+	if it.mods != it.t.mods {
+		// The tree changed, so prev may be gone
+		it.seek()
+	}
+	l := it.prev
+	if l == nil || !strings.HasPrefix(l.key, it.prefix) {
+		return "", nil, false
+	}
+	it.prev = l.prev
+	it.pos, it.inclusive, it.end = l.key, false, false
+	return l.key, l.val, true
+}
@@ -0,0 +1,58 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReverseWalk(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithSmallTree(100)}} {
+		r := New(opts...)
+		for _, k := range []string{"a", "ab", "abc", "b", "ba", "c"} {
+			r.Insert(k, nil)
+		}
+		collect := func(prefix string) []string {
+			var out []string
+			r.ReverseWalkPrefix(prefix, func(k string, v interface{}) bool {
+				out = append(out, k)
+				return false
+			})
+			return out
+		}
+		if got := collect(""); !reflect.DeepEqual(got, []string{"c", "ba", "b", "abc", "ab", "a"}) {
+			t.Fatalf("bad: %v", got)
+		}
+		if got := collect("ab"); !reflect.DeepEqual(got, []string{"abc", "ab"}) {
+			t.Fatalf("bad: %v", got)
+		}
+		if got := collect("bb"); got != nil {
+			t.Fatalf("bad: %v", got)
+		}
+
+		// Stopping early
+		var first []string
+		r.ReverseWalk(func(k string, v interface{}) bool {
+			first = append(first, k)
+			return len(first) == 2
+		})
+		if !reflect.DeepEqual(first, []string{"c", "ba"}) {
+			t.Fatalf("bad: %v", first)
+		}
+
+		it := r.ReverseIterator()
+		it.SeekReverseLowerBound("abd")
+		if k, _, _ := it.Previous(); k != "abc" {
+			t.Fatalf("bad: %q", k)
+		}
+
+		// Deleting during the walk
+		r.ReverseWalk(func(k string, v interface{}) bool {
+			r.Delete(k)
+			r.Delete("ab")
+			return false
+		})
+		if r.Len() != 0 {
+			t.Fatalf("bad: %v", r.ToMap())
+		}
+	}
+}
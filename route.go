@@ -0,0 +1,92 @@
+package radix
+
+import "strings"
+
+// MatchPath looks up path among keys written as routes, in which a
+// segment ":name" matches any one non-empty segment of path, and a
+// final segment "*name" matches all the rest of it, slashes included,
+// as in "/users/:id/orders" and "/static/*file". It returns the
+// matching key and its value, with the segments matched by each name.
+// Segments are separated by '/'. Where several keys match, literal
+// segments are preferred to parameters, and parameters to catch-alls,
+// segment by segment from the left, backtracking when a preference
+// leads nowhere.
+func (t *Tree) MatchPath(path string) (key string, v interface{}, params map[string]string, ok bool) {
+	path = t.norm(path)
+	var names, values []string
+	l := t.route("", path, &names, &values)
+	if l == nil {
+		return "", nil, nil, false
+	}
+	params = make(map[string]string, len(names))
+	for i, name := range names {
+		params[name] = values[i]
+	}
+	return l.key, l.val, params, true
+}
+
+// route matches rest, what is left of the path, against the keys
+// starting with pat, the route matched so far, which ends at a segment
+// boundary. It returns the leaf matched, and appends to names and
+// values the parameters bound on the way to it.
+func (t *Tree) route(pat, rest string, names, values *[]string) *leafNode {
+	if rest == "" {
+		return t.getLeaf(pat)
+	}
+	seg := rest
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		seg = rest[:i]
+	}
+	after := rest[len(seg):]
+
+	// next carries on after the segment, matched by p
+	next := func(p string) *leafNode {
+		if after == "" {
+			return t.getLeaf(p)
+		}
+		if !t.hasPrefix(p + "/") {
+			return nil
+		}
+		return t.route(p+"/", after[1:], names, values)
+	}
+
+	// A literal segment
+	if t.hasPrefix(pat + seg) {
+		if l := next(pat + seg); l != nil {
+			return l
+		}
+	}
+
+	// A parameter
+	if seg != "" && t.hasPrefix(pat+":") {
+		for _, name := range t.Children(pat+":", '/') {
+			name = strings.TrimSuffix(name, "/")
+			n := len(*names)
+			*names, *values = append(*names, name), append(*values, seg)
+			if l := next(pat + ":" + name); l != nil {
+				return l
+			}
+			*names, *values = (*names)[:n], (*values)[:n]
+		}
+	}
+
+	// A catch-all
+	if t.hasPrefix(pat + "*") {
+		for _, name := range t.Children(pat+"*", '/') {
+			if strings.HasSuffix(name, "/") {
+				continue
+			}
+			if l := t.getLeaf(pat + "*" + name); l != nil {
+				*names, *values = append(*names, name), append(*values, rest)
+				return l
+			}
+		}
+	}
+	return nil
+}
+
+// hasPrefix reports whether any key starts with prefix
+func (t *Tree) hasPrefix(prefix string) bool {
+	first, end := t.prefixLeaves(prefix)
+	return first != end
+}
@@ -0,0 +1,58 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchPath(t *testing.T) {
+	routes := []string{
+		"/",
+		"/users",
+		"/users/:id",
+		"/users/:id/orders",
+		"/users/:id/orders/:order",
+		"/users/me",
+		"/users/me/settings",
+		"/static/*file",
+		"/files/:dir/*rest",
+		"/a/:x/b",
+		"/a/:y/c",
+	}
+	for _, opts := range [][]Option{nil, {WithSmallTree(100)}} {
+		r := New(opts...)
+		for _, k := range routes {
+			r.Insert(k, k)
+		}
+		for _, tc := range []struct {
+			path, key string
+			params    map[string]string
+		}{
+			{"/", "/", map[string]string{}},
+			{"/users", "/users", map[string]string{}},
+			{"/users/123", "/users/:id", map[string]string{"id": "123"}},
+			{"/users/me", "/users/me", map[string]string{}},
+			{"/users/mel", "/users/:id", map[string]string{"id": "mel"}},
+			{"/users/me/orders", "/users/:id/orders", map[string]string{"id": "me"}},
+			{"/users/me/settings", "/users/me/settings", map[string]string{}},
+			{"/users/7/orders/9", "/users/:id/orders/:order", map[string]string{"id": "7", "order": "9"}},
+			{"/static/css/site.css", "/static/*file", map[string]string{"file": "css/site.css"}},
+			{"/files/x/y/z", "/files/:dir/*rest", map[string]string{"dir": "x", "rest": "y/z"}},
+			{"/a/1/b", "/a/:x/b", map[string]string{"x": "1"}},
+			{"/a/1/c", "/a/:y/c", map[string]string{"y": "1"}},
+			{"/users/", "", nil},
+			{"/users/7/carts", "", nil},
+			{"/static/", "", nil},
+			{"/a/1/d", "", nil},
+			{"/nope", "", nil},
+		} {
+			k, v, params, ok := r.MatchPath(tc.path)
+			if ok != (tc.key != "") || k != tc.key || (ok && v != tc.key) {
+				t.Fatalf("%q: got %q %v, want %q", tc.path, k, ok, tc.key)
+			}
+			if !reflect.DeepEqual(params, tc.params) {
+				t.Fatalf("%q: got %v, want %v", tc.path, params, tc.params)
+			}
+		}
+	}
+}
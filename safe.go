@@ -0,0 +1,237 @@
+package radix
+
+import "sync"
+
+// SafeTree is a Tree guarded by a read-write mutex, so it can be
+// shared between goroutines without further locking. Lookups and
+// walks run concurrently with each other, and mutations one at a
+// time. Lookups that would modify the tree, as when hit counting, the
+// prefix profiler or entries with a TTL are involved, take the write
+// lock instead. With a loader, Gets that miss the same key at the
+// same time share a single call to it, made outside the lock, so a
+// slow load holds up neither readers nor writers. Callbacks run with
+// the lock held and must not call back into the SafeTree.
+type SafeTree struct {
+	mu sync.RWMutex
+	t  *Tree
+
+	// calls holds the loads in flight, by key, guarded by callMu
+	callMu sync.Mutex
+	calls  map[string]*call
+}
+
+// call is a load in flight, which waiters block on
+type call struct {
+	done chan struct{}
+	val  interface{}
+	ok   bool
+}
+
+// NewSafe returns an empty SafeTree configured by opts. A janitor
+// given by WithJanitor must not use the tree's own lock; call
+// EvictExpired periodically instead.
+func NewSafe(opts ...Option) *SafeTree {
+	return &SafeTree{t: New(opts...)}
+}
+
+// pureReads reports whether lookups leave the tree alone, and so can
+// share the read lock. It must be called with a lock held.
+func (s *SafeTree) pureReads() bool {
+	t := s.t
+	return !t.countHits && t.profile == nil && len(t.expiry) == 0
+}
+
+// rlock takes the read lock if lookups are pure, and the write lock
+// otherwise, returning the matching unlock
+func (s *SafeTree) rlock() func() {
+	s.mu.RLock()
+	if s.pureReads() {
+		return s.mu.RUnlock
+	}
+	s.mu.RUnlock()
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// View calls fn with the tree under the read lock, for operations
+// without a SafeTree method. fn must only read the tree, and must not
+// keep it.
+func (s *SafeTree) View(fn func(t *Tree)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn(s.t)
+}
+
+// Update calls fn with the tree under the write lock, so several
+// operations can be applied atomically. fn must not keep the tree.
+func (s *SafeTree) Update(fn func(t *Tree)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.t)
+}
+
+// Insert adds or updates an entry, like Tree.Insert
+func (s *SafeTree) Insert(k string, v interface{}) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.t.Insert(k, v)
+}
+
+// TryInsert is like Tree.TryInsert
+func (s *SafeTree) TryInsert(k string, v interface{}) (interface{}, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.t.TryInsert(k, v)
+}
+
+// Add adds delta to an int64 counter, like Tree.Add, atomically
+func (s *SafeTree) Add(k string, delta int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.t.Add(k, delta)
+}
+
+// Delete removes an entry, like Tree.Delete
+func (s *SafeTree) Delete(k string) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.t.Delete(k)
+}
+
+// DeletePrefix removes the entries under a prefix, like
+// Tree.DeletePrefix
+func (s *SafeTree) DeletePrefix(prefix string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.t.DeletePrefix(prefix)
+}
+
+// EvictExpired removes expired entries, like Tree.EvictExpired
+func (s *SafeTree) EvictExpired() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.t.EvictExpired()
+}
+
+// Get looks up a key, like Tree.Get
+func (s *SafeTree) Get(k string) (interface{}, bool) {
+	unlock := s.rlock()
+	k = s.t.norm(k)
+	v, ok := s.t.lookup(k)
+	loader := s.t.loader
+	unlock()
+	if ok || loader == nil {
+		return v, ok
+	}
+	return s.load(k, loader)
+}
+
+// load fetches the missing key k with loader, or waits for the load
+// of k already in flight
+func (s *SafeTree) load(k string, loader LoaderFunc) (interface{}, bool) {
+	s.callMu.Lock()
+	if c, ok := s.calls[k]; ok {
+		s.callMu.Unlock()
+		<-c.done
+		return c.val, c.ok
+	}
+	c := &call{done: make(chan struct{})}
+	if s.calls == nil {
+		s.calls = make(map[string]*call)
+	}
+	s.calls[k] = c
+	s.callMu.Unlock()
+
+	defer func() {
+		s.callMu.Lock()
+		delete(s.calls, k)
+		s.callMu.Unlock()
+		close(c.done)
+	}()
+
+	// The key may have turned up, or be known to be missing
+	s.mu.Lock()
+	c.val, c.ok = s.t.get(k)
+	absent := !c.ok && s.t.knownAbsent(k)
+	s.mu.Unlock()
+	if c.ok || absent {
+		return c.val, c.ok
+	}
+
+	v, ok := loader(k)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cur, found := s.t.get(k); found {
+		// Stored while loading, which is more recent
+		v, ok = cur, true
+	} else {
+		s.t.loaded(k, v, ok)
+	}
+	c.val, c.ok = v, ok
+	return v, ok
+}
+
+// LongestPrefix finds the longest key that is a prefix of k, like
+// Tree.LongestPrefix
+func (s *SafeTree) LongestPrefix(k string) (string, interface{}, bool) {
+	defer s.rlock()()
+	return s.t.LongestPrefix(k)
+}
+
+// Len returns the number of entries
+func (s *SafeTree) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.t.Len()
+}
+
+// Minimum returns the smallest entry, like Tree.Minimum
+func (s *SafeTree) Minimum() (string, interface{}, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.t.Minimum()
+}
+
+// Maximum returns the largest entry, like Tree.Maximum
+func (s *SafeTree) Maximum() (string, interface{}, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.t.Maximum()
+}
+
+// Walk visits every entry, like Tree.Walk, under the read lock
+func (s *SafeTree) Walk(fn WalkFn) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.t.Walk(fn)
+}
+
+// WalkPrefix visits the entries under prefix, like Tree.WalkPrefix,
+// under the read lock
+func (s *SafeTree) WalkPrefix(prefix string, fn WalkFn) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.t.WalkPrefix(prefix, fn)
+}
+
+// WalkPath visits the entries whose keys are prefixes of path, like
+// Tree.WalkPath, under the read lock
+func (s *SafeTree) WalkPath(path string, fn WalkFn) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.t.WalkPath(path, fn)
+}
+
+// ToMap returns the entries as a map
+func (s *SafeTree) ToMap() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.t.ToMap()
+}
+
+// Close releases background resources, like Tree.Close
+func (s *SafeTree) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.t.Close()
+}
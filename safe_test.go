@@ -0,0 +1,140 @@
+package radix
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSafeTree(t *testing.T) {
+	s := NewSafe()
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				k := fmt.Sprintf("%d/%d", w, i)
+				s.Insert(k, i)
+				if v, ok := s.Get(k); !ok || v != i {
+					t.Errorf("bad: %v %v", v, ok)
+				}
+				s.Add("count", 1)
+				s.WalkPrefix(fmt.Sprintf("%d/", w), func(string, interface{}) bool { return false })
+				s.LongestPrefix(k + "/x")
+			}
+		}(w)
+	}
+	wg.Wait()
+	if v, _ := s.Get("count"); v != int64(800) {
+		t.Fatalf("bad: %v", v)
+	}
+	if n := s.Len(); n != 801 {
+		t.Fatalf("bad: %d", n)
+	}
+	s.Update(func(r *Tree) {
+		r.DeletePrefix("0/")
+		r.Delete("count")
+	})
+	s.View(func(r *Tree) {
+		if r.Len() != 600 {
+			t.Errorf("bad: %d", r.Len())
+		}
+	})
+}
+
+func TestSafeTree_ImpureReads(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	s := NewSafe(WithClock(clock), WithLFU(0))
+	if s.pureReads() {
+		t.Fatalf("expected hit counting to need the write lock")
+	}
+	s = NewSafe(WithClock(clock))
+	if !s.pureReads() {
+		t.Fatalf("expected pure reads")
+	}
+	s.Update(func(r *Tree) {
+		r.InsertWithTTL("foo", 1, time.Second)
+	})
+	if s.pureReads() {
+		t.Fatalf("expected expiring entries to need the write lock")
+	}
+
+	// Get expires the entry under the write lock
+	clock.advance(time.Minute)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok := s.Get("foo"); ok {
+				t.Errorf("expected foo to have expired")
+			}
+		}()
+	}
+	wg.Wait()
+	if s.Len() != 0 {
+		t.Fatalf("bad: %d", s.Len())
+	}
+}
+
+func TestSafeTree_Singleflight(t *testing.T) {
+	var calls int
+	var mu sync.Mutex
+	release := make(chan struct{})
+	s := NewSafe(WithLoader(func(k string) (interface{}, bool) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		<-release
+		return "loaded " + k, k != "missing"
+	}), WithNegativeCache(time.Hour))
+	s.Insert("present", 1)
+
+	// Concurrent misses of one key share a load
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, ok := s.Get("cold"); !ok || v != "loaded cold" {
+				t.Errorf("bad: %v %v", v, ok)
+			}
+		}()
+	}
+
+	// The load doesn't hold the tree's lock
+	for {
+		mu.Lock()
+		n := calls
+		mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if v, ok := s.Get("present"); !ok || v != 1 {
+		t.Fatalf("bad: %v %v", v, ok)
+	}
+	s.Insert("other", 2)
+
+	close(release)
+	wg.Wait()
+	if calls != 1 {
+		t.Fatalf("expected one load, got %d", calls)
+	}
+	if v, ok := s.Get("cold"); !ok || v != "loaded cold" {
+		t.Fatalf("bad: %v %v", v, ok)
+	}
+
+	// Misses are remembered
+	for i := 0; i < 2; i++ {
+		if _, ok := s.Get("missing"); ok {
+			t.Fatalf("expected missing to be missing")
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected the miss to be cached, got %d loads", calls)
+	}
+}
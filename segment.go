@@ -0,0 +1,45 @@
+package radix
+
+import "unicode/utf8"
+
+// Token is a piece of text split off by Segment
+type Token struct {
+	Text  string
+	Value interface{}
+
+	// Start is the byte offset of the token in the text
+	Start int
+
+	// Known reports whether Text is a key of the tree. Unknown
+	// tokens have a nil Value.
+	Known bool
+}
+
+// Segment splits text into tokens by greedy longest match: starting at
+// the front, it repeatedly takes the longest key that prefixes the
+// rest of the text. Runs of text that no key matches are returned as
+// single unknown tokens, so the tokens always add up to text. Unknown
+// runs are split between UTF-8 characters, and the empty key is never
+// matched.
+func (t *Tree) Segment(text string) []Token {
+	var out []Token
+	unknown := 0
+	for i := 0; i < len(text); {
+		key, val, ok := t.longestMatch(text[i:])
+		if !ok || key == "" {
+			_, size := utf8.DecodeRuneInString(text[i:])
+			i += size
+			continue
+		}
+		if unknown < i {
+			out = append(out, Token{Text: text[unknown:i], Start: unknown})
+		}
+		out = append(out, Token{Text: text[i : i+len(key)], Value: val, Start: i, Known: true})
+		i += len(key)
+		unknown = i
+	}
+	if unknown < len(text) {
+		out = append(out, Token{Text: text[unknown:], Start: unknown})
+	}
+	return out
+}
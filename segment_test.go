@@ -0,0 +1,31 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSegment(t *testing.T) {
+	r := New()
+	for _, k := range []string{"", "new", "newyork", "york", "city", "ci"} {
+		r.Insert(k, len(k))
+	}
+	got := r.Segment("newyorkcityxx€cinew")
+	want := []Token{
+		{Text: "newyork", Value: 7, Start: 0, Known: true},
+		{Text: "city", Value: 4, Start: 7, Known: true},
+		{Text: "xx€", Start: 11},
+		{Text: "ci", Value: 2, Start: 16, Known: true},
+		{Text: "new", Value: 3, Start: 18, Known: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("bad: %+v", got)
+	}
+
+	if got := r.Segment("zz"); !reflect.DeepEqual(got, []Token{{Text: "zz"}}) {
+		t.Fatalf("bad: %+v", got)
+	}
+	if got := r.Segment(""); len(got) != 0 {
+		t.Fatalf("bad: %+v", got)
+	}
+}
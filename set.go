@@ -0,0 +1,300 @@
+package radix
+
+import (
+	"sort"
+	"strings"
+)
+
+// Set is a set of strings kept in a radix tree of its own, for when
+// the tree is only used for membership and prefix queries. It stores
+// no values and no leaves: each node only marks whether the path
+// through it is a member, and a member's key is rebuilt from the
+// prefixes on the way down rather than kept whole. A member so takes
+// a fraction of the memory of an entry of a Tree. The zero value is
+// an empty set, and it takes none of Tree's options.
+type Set struct {
+	root setNode
+	size int
+}
+
+// setNode is a node of a Set. Its edges are sorted by the first byte
+// of their prefix, which is never empty below the root.
+type setNode struct {
+	prefix string
+	edges  []*setNode
+	member bool
+}
+
+// NewSet returns an empty set
+func NewSet() *Set {
+	return &Set{}
+}
+
+// edge returns the index of the child whose prefix starts with c,
+// and the child, or nil and the index it would have
+func (n *setNode) edge(c byte) (int, *setNode) {
+	i := sort.Search(len(n.edges), func(i int) bool {
+		return n.edges[i].prefix[0] >= c
+	})
+	if i < len(n.edges) && n.edges[i].prefix[0] == c {
+		return i, n.edges[i]
+	}
+	return i, nil
+}
+
+// addEdge adds child to n, keeping the edges sorted
+func (n *setNode) addEdge(child *setNode) {
+	i, _ := n.edge(child.prefix[0])
+	n.edges = append(n.edges, nil)
+	copy(n.edges[i+1:], n.edges[i:])
+	n.edges[i] = child
+}
+
+// removeEdge removes the child at index i from n, merging n with its
+// only remaining child if n is not a member and not the root
+func (n *setNode) removeEdge(i int, root bool) {
+	copy(n.edges[i:], n.edges[i+1:])
+	n.edges[len(n.edges)-1] = nil
+	n.edges = n.edges[:len(n.edges)-1]
+	if !root && !n.member && len(n.edges) == 1 {
+		n.mergeChild()
+	}
+}
+
+// mergeChild absorbs n's only child into n
+func (n *setNode) mergeChild() {
+	child := n.edges[0]
+	n.prefix = n.prefix + child.prefix
+	n.edges = child.edges
+	n.member = child.member
+}
+
+// Add adds key to the set, reporting whether it was new
+func (s *Set) Add(key string) bool {
+	n := &s.root
+	search := key
+	for len(search) > 0 {
+		i, child := n.edge(search[0])
+		if child == nil {
+			// Copy the rest of the key, so the set doesn't keep the
+			// caller's whole key alive
+			n.addEdge(&setNode{prefix: string([]byte(search)), member: true})
+			s.size++
+			return true
+		}
+		common := longestPrefix(search, child.prefix)
+		if common < len(child.prefix) {
+			// Split the child where key leaves its prefix
+			split := &setNode{prefix: child.prefix[:common], edges: []*setNode{child}}
+			child.prefix = child.prefix[common:]
+			n.edges[i] = split
+			child = split
+		}
+		n = child
+		search = search[common:]
+	}
+	if n.member {
+		return false
+	}
+	n.member = true
+	s.size++
+	return true
+}
+
+// Remove removes key from the set, reporting whether it was there
+func (s *Set) Remove(key string) bool {
+	var parent *setNode
+	var idx int
+	n := &s.root
+	search := key
+	for len(search) > 0 {
+		i, child := n.edge(search[0])
+		if child == nil || !strings.HasPrefix(search, child.prefix) {
+			return false
+		}
+		parent, idx, n = n, i, child
+		search = search[len(child.prefix):]
+	}
+	if !n.member {
+		return false
+	}
+	n.member = false
+	s.size--
+	switch {
+	case parent == nil:
+	case len(n.edges) == 0:
+		parent.removeEdge(idx, parent == &s.root)
+	case len(n.edges) == 1:
+		n.mergeChild()
+	}
+	return true
+}
+
+// RemovePrefix removes every member starting with prefix and returns
+// how many there were
+func (s *Set) RemovePrefix(prefix string) int {
+	var parent *setNode
+	var idx int
+	n := &s.root
+	search := prefix
+	for len(search) > 0 {
+		i, child := n.edge(search[0])
+		if child == nil {
+			return 0
+		}
+		if !strings.HasPrefix(search, child.prefix) {
+			if !strings.HasPrefix(child.prefix, search) {
+				return 0
+			}
+			search = ""
+		} else {
+			search = search[len(child.prefix):]
+		}
+		parent, idx, n = n, i, child
+	}
+	removed := n.count()
+	if parent == nil {
+		s.root = setNode{}
+	} else {
+		parent.removeEdge(idx, parent == &s.root)
+	}
+	s.size -= removed
+	return removed
+}
+
+// count returns the number of members under n
+func (n *setNode) count() int {
+	c := 0
+	if n.member {
+		c++
+	}
+	for _, e := range n.edges {
+		c += e.count()
+	}
+	return c
+}
+
+// Contains reports whether key is in the set
+func (s *Set) Contains(key string) bool {
+	n := &s.root
+	search := key
+	for len(search) > 0 {
+		_, child := n.edge(search[0])
+		if child == nil || !strings.HasPrefix(search, child.prefix) {
+			return false
+		}
+		n = child
+		search = search[len(child.prefix):]
+	}
+	return n.member
+}
+
+// Len returns the number of members
+func (s *Set) Len() int {
+	return s.size
+}
+
+// HasPrefix reports whether any member starts with prefix
+func (s *Set) HasPrefix(prefix string) bool {
+	n, _ := s.seekPrefix(prefix)
+	return n != nil && (n != &s.root || s.size > 0)
+}
+
+// seekPrefix returns the topmost node whose path starts with prefix,
+// along with its path, or nil if no member starts with prefix. Every
+// node below the root has a member under it.
+func (s *Set) seekPrefix(prefix string) (*setNode, string) {
+	n := &s.root
+	search := prefix
+	for len(search) > 0 {
+		_, child := n.edge(search[0])
+		if child == nil {
+			return nil, ""
+		}
+		if strings.HasPrefix(child.prefix, search) {
+			return child, prefix + child.prefix[len(search):]
+		}
+		if !strings.HasPrefix(search, child.prefix) {
+			return nil, ""
+		}
+		n = child
+		search = search[len(child.prefix):]
+	}
+	return n, prefix
+}
+
+// LongestPrefix returns the longest member that is a prefix of key
+func (s *Set) LongestPrefix(key string) (string, bool) {
+	match, found := 0, false
+	s.walkPath(key, func(end int) bool {
+		match, found = end, true
+		return false
+	})
+	return key[:match], found
+}
+
+// Walk visits the members in order until fn returns true
+func (s *Set) Walk(fn func(key string) bool) {
+	s.root.walk(make([]byte, 0, 64), fn)
+}
+
+// WalkPrefix visits the members starting with prefix in order until
+// fn returns true
+func (s *Set) WalkPrefix(prefix string, fn func(key string) bool) {
+	if n, path := s.seekPrefix(prefix); n != nil {
+		n.walk(append(make([]byte, 0, len(path)+32), path...), fn)
+	}
+}
+
+// walk visits the members under n, whose path is buf, and reports
+// whether fn asked to stop
+func (n *setNode) walk(buf []byte, fn func(key string) bool) bool {
+	if n.member && fn(string(buf)) {
+		return true
+	}
+	for _, e := range n.edges {
+		if e.walk(append(buf, e.prefix...), fn) {
+			return true
+		}
+	}
+	return false
+}
+
+// WalkPath visits the members that are prefixes of path, shortest
+// first, until fn returns true
+func (s *Set) WalkPath(path string, fn func(key string) bool) {
+	s.walkPath(path, func(end int) bool {
+		return fn(path[:end])
+	})
+}
+
+// walkPath calls fn with the length of every member that is a prefix
+// of path, shortest first, until fn returns true
+func (s *Set) walkPath(path string, fn func(end int) bool) {
+	n := &s.root
+	end := 0
+	for {
+		if n.member && fn(end) {
+			return
+		}
+		if end == len(path) {
+			return
+		}
+		_, child := n.edge(path[end])
+		if child == nil || !strings.HasPrefix(path[end:], child.prefix) {
+			return
+		}
+		n = child
+		end += len(child.prefix)
+	}
+}
+
+// Members returns the members in order
+func (s *Set) Members() []string {
+	out := make([]string, 0, s.size)
+	s.Walk(func(k string) bool {
+		out = append(out, k)
+		return false
+	})
+	return out
+}
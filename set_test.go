@@ -0,0 +1,200 @@
+package radix
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	s := NewSet()
+	for _, k := range []string{"foo", "foobar", "bar"} {
+		if !s.Add(k) {
+			t.Fatalf("expected %q to be new", k)
+		}
+	}
+	if s.Add("foo") {
+		t.Fatalf("expected foo to exist")
+	}
+	if s.Len() != 3 || !s.Contains("foobar") || s.Contains("fo") {
+		t.Fatalf("bad: %v", s.Members())
+	}
+	if !s.HasPrefix("fo") || s.HasPrefix("baz") {
+		t.Fatalf("bad prefix check")
+	}
+	if k, ok := s.LongestPrefix("foob"); !ok || k != "foo" {
+		t.Fatalf("bad: %q %v", k, ok)
+	}
+
+	var path []string
+	s.WalkPath("foobarbaz", func(k string) bool {
+		path = append(path, k)
+		return false
+	})
+	if !reflect.DeepEqual(path, []string{"foo", "foobar"}) {
+		t.Fatalf("bad: %v", path)
+	}
+	var under []string
+	s.WalkPrefix("foo", func(k string) bool {
+		under = append(under, k)
+		return false
+	})
+	if !reflect.DeepEqual(under, []string{"foo", "foobar"}) {
+		t.Fatalf("bad: %v", under)
+	}
+
+	if !s.Remove("bar") || s.Remove("bar") {
+		t.Fatalf("bad remove")
+	}
+	if n := s.RemovePrefix("foo"); n != 2 || s.Len() != 0 {
+		t.Fatalf("bad: %d %v", n, s.Members())
+	}
+}
+
+func TestSet_Random(t *testing.T) {
+	s := NewSet()
+	want := make(map[string]bool)
+	rnd := rand.New(rand.NewSource(1))
+	key := func() string {
+		b := make([]byte, rnd.Intn(6))
+		for i := range b {
+			b[i] = "ab/"[rnd.Intn(3)]
+		}
+		return string(b)
+	}
+	for i := 0; i < 20000; i++ {
+		k := key()
+		switch rnd.Intn(10) {
+		case 0:
+			n := s.RemovePrefix(k)
+			for m := range want {
+				if strings.HasPrefix(m, k) {
+					delete(want, m)
+					n--
+				}
+			}
+			if n != 0 {
+				t.Fatalf("RemovePrefix(%q) miscounted by %d", k, n)
+			}
+		case 1, 2, 3:
+			if s.Remove(k) != want[k] {
+				t.Fatalf("Remove(%q)", k)
+			}
+			delete(want, k)
+		default:
+			if s.Add(k) == want[k] {
+				t.Fatalf("Add(%q)", k)
+			}
+			want[k] = true
+		}
+		validateSet(t, &s.root, true)
+		if s.Len() != len(want) {
+			t.Fatalf("bad len: %d %d", s.Len(), len(want))
+		}
+	}
+
+	var keys []string
+	for k := range want {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if got := s.Members(); !reflect.DeepEqual(got, keys) && len(keys) > 0 {
+		t.Fatalf("bad: %q %q", got, keys)
+	}
+	for i := 0; i < 100; i++ {
+		k := key()
+		if s.Contains(k) != want[k] {
+			t.Fatalf("Contains(%q)", k)
+		}
+		has := false
+		for m := range want {
+			has = has || strings.HasPrefix(m, k)
+		}
+		if s.HasPrefix(k) != has {
+			t.Fatalf("HasPrefix(%q)", k)
+		}
+	}
+}
+
+// validateSet checks that the edges of n are sorted and that every
+// node below the root has a prefix and is a member or branches
+func validateSet(t *testing.T, n *setNode, root bool) {
+	t.Helper()
+	if !root && (n.prefix == "" || (!n.member && len(n.edges) < 2)) {
+		t.Fatalf("bad node %q: member %v, %d edges", n.prefix, n.member, len(n.edges))
+	}
+	for i, e := range n.edges {
+		if i > 0 && n.edges[i-1].prefix[0] >= e.prefix[0] {
+			t.Fatalf("edges of %q out of order", n.prefix)
+		}
+		validateSet(t, e, false)
+	}
+}
+
+func TestSet_Memory(t *testing.T) {
+	keys := make([]string, 100000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("member/%08d", i)
+	}
+	set := heapBytes(func() interface{} {
+		s := NewSet()
+		for _, k := range keys {
+			s.Add(k)
+		}
+		return s
+	})
+	tree := heapBytes(func() interface{} {
+		r := New()
+		for _, k := range keys {
+			r.Insert(k, nil)
+		}
+		return r
+	})
+	if set*2 > tree {
+		t.Fatalf("set takes %d bytes, tree %d", set, tree)
+	}
+}
+
+// heapBytes returns the heap memory held by what build returns
+func heapBytes(build func() interface{}) int64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	v := build()
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(v)
+	return int64(after.HeapAlloc) - int64(before.HeapAlloc)
+}
+
+func BenchmarkSetMemory(b *testing.B) {
+	keys := make([]string, 100000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("member/%08d", i)
+	}
+	for i := 0; i < b.N; i++ {
+		set := heapBytes(func() interface{} {
+			s := NewSet()
+			for _, k := range keys {
+				s.Add(k)
+			}
+			return s
+		})
+		tree := heapBytes(func() interface{} {
+			r := New()
+			for _, k := range keys {
+				r.Insert(k, nil)
+			}
+			return r
+		})
+
+		// The tree shares the keys with the caller, while the set
+		// holds copies of the parts it needs
+		b.ReportMetric(float64(set)/float64(len(keys)), "B/member")
+		b.ReportMetric(float64(tree)/float64(len(keys)), "B/entry")
+	}
+}
@@ -0,0 +1,116 @@
+package radix
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ShadowTarget is what a Shadow mirrors mutations into and compares
+// reads against. *Tree implements it, as can an adapter for another
+// implementation being migrated to or from.
+type ShadowTarget interface {
+	Insert(k string, v interface{}) (interface{}, bool)
+	Delete(k string) (interface{}, bool)
+	Get(k string) (interface{}, bool)
+	LongestPrefix(s string) (string, interface{}, bool)
+	Len() int
+}
+
+// ShadowResult is the outcome of one operation on one side of a
+// Shadow. Key is only set for LongestPrefix.
+type ShadowResult struct {
+	Key   string
+	Value interface{}
+	Found bool
+}
+
+// Divergence reports an operation whose outcome differed between the
+// primary and the shadow of a Shadow
+type Divergence struct {
+	Op      string // the method, such as "Get"
+	Key     string // the key it was given
+	Primary ShadowResult
+	Shadow  ShadowResult
+}
+
+func (d *Divergence) String() string {
+	return fmt.Sprintf("%s %q: primary %+v, shadow %+v", d.Op, d.Key, d.Primary, d.Shadow)
+}
+
+// Shadow applies every mutation to both a primary tree and a shadow,
+// and compares the results of every operation, reporting differences
+// while always answering from the primary. Running a new
+// representation in the shadow of the old one this way de-risks
+// migrating a large routing table.
+type Shadow struct {
+	primary   *Tree
+	shadow    ShadowTarget
+	onDiverge func(*Divergence)
+
+	// Equal compares values from the two sides. It defaults to
+	// reflect.DeepEqual.
+	Equal func(a, b interface{}) bool
+}
+
+// NewShadow returns a Shadow answering from primary and mirroring
+// into shadow, which should start out with the same entries. onDiverge
+// is called synchronously for every difference found.
+func NewShadow(primary *Tree, shadow ShadowTarget, onDiverge func(*Divergence)) *Shadow {
+	return &Shadow{primary: primary, shadow: shadow, onDiverge: onDiverge, Equal: reflect.DeepEqual}
+}
+
+// Primary returns the primary tree
+func (s *Shadow) Primary() *Tree {
+	return s.primary
+}
+
+// Insert adds or updates an entry on both sides, like Tree.Insert
+func (s *Shadow) Insert(k string, v interface{}) (interface{}, bool) {
+	old, updated := s.primary.Insert(k, v)
+	sold, supdated := s.shadow.Insert(k, v)
+	s.compare("Insert", k, ShadowResult{Value: old, Found: updated}, ShadowResult{Value: sold, Found: supdated})
+	return old, updated
+}
+
+// Delete removes an entry from both sides, like Tree.Delete
+func (s *Shadow) Delete(k string) (interface{}, bool) {
+	old, ok := s.primary.Delete(k)
+	sold, sok := s.shadow.Delete(k)
+	s.compare("Delete", k, ShadowResult{Value: old, Found: ok}, ShadowResult{Value: sold, Found: sok})
+	return old, ok
+}
+
+// Get looks up a key on both sides, like Tree.Get
+func (s *Shadow) Get(k string) (interface{}, bool) {
+	v, ok := s.primary.Get(k)
+	sv, sok := s.shadow.Get(k)
+	s.compare("Get", k, ShadowResult{Value: v, Found: ok}, ShadowResult{Value: sv, Found: sok})
+	return v, ok
+}
+
+// LongestPrefix finds the longest key that is a prefix of k on both
+// sides, like Tree.LongestPrefix
+func (s *Shadow) LongestPrefix(k string) (string, interface{}, bool) {
+	m, v, ok := s.primary.LongestPrefix(k)
+	sm, sv, sok := s.shadow.LongestPrefix(k)
+	s.compare("LongestPrefix", k, ShadowResult{Key: m, Value: v, Found: ok}, ShadowResult{Key: sm, Value: sv, Found: sok})
+	return m, v, ok
+}
+
+// Len returns the number of entries in the primary, reporting a
+// divergence if the shadow holds a different number
+func (s *Shadow) Len() int {
+	n, sn := s.primary.Len(), s.shadow.Len()
+	s.compare("Len", "", ShadowResult{Value: n}, ShadowResult{Value: sn})
+	return n
+}
+
+// compare reports a divergence if the results differ
+func (s *Shadow) compare(op, key string, p, sh ShadowResult) {
+	if p.Key == sh.Key && p.Found == sh.Found && s.Equal(p.Value, sh.Value) {
+		return
+	}
+	if s.onDiverge != nil {
+		s.onDiverge(&Divergence{Op: op, Key: key, Primary: p, Shadow: sh})
+	}
+}
@@ -0,0 +1,49 @@
+package radix
+
+import "testing"
+
+func TestShadow(t *testing.T) {
+	var diverged []*Divergence
+	shadow := New()
+	s := NewShadow(New(), shadow, func(d *Divergence) {
+		diverged = append(diverged, d)
+	})
+	s.Insert("foo", 1)
+	s.Insert("foobar", 2)
+	s.Delete("foo")
+	s.Get("foobar")
+	s.LongestPrefix("foobarbaz")
+	s.Len()
+	if len(diverged) != 0 {
+		t.Fatalf("bad: %v", diverged)
+	}
+
+	// Changes made behind the shadow's back show up on reads, which
+	// are answered by the primary
+	shadow.Insert("foobar", 3)
+	shadow.Insert("foo", 1)
+	if v, _ := s.Get("foobar"); v != 2 {
+		t.Fatalf("bad: %v", v)
+	}
+	if k, _, _ := s.LongestPrefix("foobaz"); k != "" {
+		t.Fatalf("bad: %q", k)
+	}
+	s.Len()
+	if len(diverged) != 3 {
+		t.Fatalf("bad: %v", diverged)
+	}
+	if d := diverged[0]; d.Op != "Get" || d.Key != "foobar" || d.Primary.Value != 2 || d.Shadow.Value != 3 {
+		t.Fatalf("bad: %v", d)
+	}
+	if d := diverged[1]; d.Op != "LongestPrefix" || d.Shadow.Key != "foo" || d.Primary.Found {
+		t.Fatalf("bad: %v", d)
+	}
+
+	// Equal can relax the comparison
+	s.Equal = func(a, b interface{}) bool { return true }
+	diverged = nil
+	s.Get("foobar")
+	if len(diverged) != 0 {
+		t.Fatalf("bad: %v", diverged)
+	}
+}
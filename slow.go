@@ -0,0 +1,42 @@
+package radix
+
+import "time"
+
+// SlowOpFunc is called for operations that took longer than the
+// threshold set with WithSlowOpWarning. op names the method, elapsed
+// is how long it took and visited is the number of entries it passed
+// to the walk function or deleted.
+type SlowOpFunc func(op string, elapsed time.Duration, visited int)
+
+// slowWatch times a single operation
+type slowWatch struct {
+	t       *Tree
+	op      string
+	start   time.Time
+	visited int
+}
+
+// watch starts timing op, returning nil if slow operations aren't
+// being reported
+func (t *Tree) watch(op string) *slowWatch {
+	if t.slowFn == nil {
+		return nil
+	}
+	return &slowWatch{t: t, op: op, start: t.currentTime()}
+}
+
+// count wraps fn to count the entries it visits
+func (w *slowWatch) count(fn WalkFn) WalkFn {
+	return func(k string, v interface{}) bool {
+		w.visited++
+		return fn(k, v)
+	}
+}
+
+// done reports the operation if it was slow
+func (w *slowWatch) done() {
+	elapsed := w.t.currentTime().Sub(w.start)
+	if elapsed >= w.t.slowThreshold {
+		w.t.slowFn(w.op, elapsed, w.visited)
+	}
+}
@@ -0,0 +1,59 @@
+package radix
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSlowOpWarning(t *testing.T) {
+	type report struct {
+		op      string
+		elapsed time.Duration
+		visited int
+	}
+	var reports []report
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	r := New(WithClock(clock), WithSlowOpWarning(time.Second, func(op string, elapsed time.Duration, visited int) {
+		reports = append(reports, report{op, elapsed, visited})
+	}))
+	for i := 0; i < 10; i++ {
+		r.Insert(fmt.Sprintf("a%d", i), i)
+		r.Insert(fmt.Sprintf("b%d", i), i)
+	}
+
+	// Fast operations aren't reported
+	r.Walk(func(k string, v interface{}) bool { return false })
+	if len(reports) != 0 {
+		t.Fatalf("bad: %v", reports)
+	}
+
+	slow := func(k string, v interface{}) bool {
+		clock.advance(100 * time.Millisecond)
+		return false
+	}
+	r.Walk(slow)
+	r.WalkPrefix("a", slow)
+	r.WalkPrefix("b", func(k string, v interface{}) bool {
+		clock.advance(time.Second)
+		return true
+	})
+	if len(reports) != 3 {
+		t.Fatalf("bad: %v", reports)
+	}
+	if reports[0] != (report{"Walk", 2 * time.Second, 20}) ||
+		reports[1] != (report{"WalkPrefix", time.Second, 10}) ||
+		reports[2] != (report{"WalkPrefix", time.Second, 1}) {
+		t.Fatalf("bad: %v", reports)
+	}
+
+	r = New(WithClock(clock), WithSlowOpWarning(0, func(op string, elapsed time.Duration, visited int) {
+		reports = append(reports, report{op, elapsed, visited})
+	}))
+	r.Insert("a1", 1)
+	r.Insert("a2", 2)
+	r.DeletePrefix("a")
+	if last := reports[len(reports)-1]; last != (report{"DeletePrefix", 0, 2}) {
+		t.Fatalf("bad: %v", last)
+	}
+}
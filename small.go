@@ -0,0 +1,175 @@
+package radix
+
+import (
+	"sort"
+	"strings"
+)
+
+// A small tree keeps its entries in a sorted slice instead of nodes,
+// see WithSmallTree. The leaves are still threaded on the leaf list,
+// so everything built on that works unchanged. Lookups, inserts,
+// deletes and walks have a slice based path; operations that need the
+// node structure, such as aggregates, Split and Freeze, promote the
+// tree first.
+
+// searchSmall returns the index of the first entry not less than s,
+// and whether that entry is s
+func (t *Tree) searchSmall(s string) (int, bool) {
+	i := sort.Search(len(t.small), func(i int) bool {
+		return t.collation.Compare(t.small[i].key, s) >= 0
+	})
+	return i, i < len(t.small) && t.small[i].key == s
+}
+
+// insertSmall is insertLeaf for small trees. It promotes the tree
+// once it outgrows the threshold.
+func (t *Tree) insertSmall(s string, v interface{}) (*leafNode, interface{}, bool) {
+	i, found := t.searchSmall(s)
+	if found {
+		l := t.small[i]
+		old := l.val
+		l.val = v
+		t.leafUpdated(l, old)
+		return l, old, true
+	}
+
+	l := t.newLeaf(s, v)
+	t.small = append(t.small, nil)
+	copy(t.small[i+1:], t.small[i:])
+	t.small[i] = l
+	switch {
+	case i+1 < len(t.small):
+		t.leaves.insert(l, nil, t.small[i+1])
+	case i > 0:
+		t.leaves.insert(l, t.small[i-1], nil)
+	default:
+		t.leaves.insert(l, nil, nil)
+	}
+	t.size++
+	t.leafAdded(l)
+	if len(t.small) > t.smallMax {
+		t.promote()
+	}
+	return l, nil, false
+}
+
+// deleteSmall is Delete for small trees
+func (t *Tree) deleteSmall(s string) (interface{}, bool) {
+	i, found := t.searchSmall(s)
+	if !found {
+		return nil, false
+	}
+	l := t.small[i]
+	t.removeSmall(i, i+1)
+	return l.val, true
+}
+
+// removeSmall removes the entries from i to j, exclusive
+func (t *Tree) removeSmall(i, j int) {
+	for _, l := range t.small[i:j] {
+		t.leafRemoved(l)
+	}
+	n := copy(t.small[i:], t.small[j:])
+	for k := i + n; k < len(t.small); k++ {
+		t.small[k] = nil
+	}
+	t.small = t.small[:i+n]
+	t.size -= j - i
+}
+
+// prefixRangeSmall returns the range of entries that start with
+// prefix. Such keys are adjacent in any collation.
+func (t *Tree) prefixRangeSmall(prefix string) (int, int) {
+	i, _ := t.searchSmall(prefix)
+	j := i
+	for j < len(t.small) && strings.HasPrefix(t.small[j].key, prefix) {
+		j++
+	}
+	return i, j
+}
+
+// walkSmall visits the entries from i to j, exclusive, returning true
+// if aborted. fn may modify the tree, so it walks a snapshot and skips
+// entries removed in the meantime.
+func (t *Tree) walkSmall(i, j int, fn WalkFn) bool {
+	leaves := append([]*leafNode(nil), t.small[i:j]...)
+	if t.naturalOrder {
+		t.sortNatural(leaves)
+	}
+	for _, l := range leaves {
+		if t.getLeaf(l.key) != l {
+			continue
+		}
+		if fn(l.key, l.val) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathSmall returns the entries whose keys are prefixes of s, shortest
+// first
+func (t *Tree) pathSmall(s string) []*leafNode {
+	var path []*leafNode
+	for _, l := range t.small {
+		if strings.HasPrefix(s, l.key) {
+			path = append(path, l)
+		}
+	}
+	return path
+}
+
+// seekSmall is seek for small trees
+func (t *Tree) seekSmall(s string) (exact, pred, succ *leafNode) {
+	i, found := t.searchSmall(s)
+	if i > 0 {
+		pred = t.small[i-1]
+	}
+	if found {
+		exact = t.small[i]
+		i++
+	}
+	if i < len(t.small) {
+		succ = t.small[i]
+	}
+	return exact, pred, succ
+}
+
+// promote moves the entries of a small tree into nodes. It does
+// nothing if the tree already has them.
+func (t *Tree) promote() {
+	if !t.smallMode {
+		return
+	}
+	t.root = t.buildNode("", 0, t.small)
+	t.small, t.smallMode = nil, false
+}
+
+// buildNode returns a node with the given prefix holding leaves, which
+// must be sorted and all start with the key path through the node,
+// of length end
+func (t *Tree) buildNode(prefix string, end int, leaves []*leafNode) *node {
+	n := t.newNode(prefix, end)
+	if len(leaves) > 0 && len(leaves[0].key) == end {
+		n.leaf = leaves[0]
+		leaves = leaves[1:]
+	}
+	for len(leaves) > 0 {
+		label := leaves[0].key[end]
+		j := 1
+		for j < len(leaves) && leaves[j].key[end] == label {
+			j++
+		}
+
+		// The child's prefix runs to the end of the keys' common
+		// prefix, which in sorted order is that of the first and last
+		first, last := leaves[0].key, leaves[j-1].key
+		common := end + longestPrefix(first[end:], last[end:])
+		n.addEdge(edge{label: label, node: t.buildNode(first[end:common], common, leaves[:j])})
+		leaves = leaves[j:]
+	}
+	if t.counted() {
+		t.recompute(n)
+	}
+	return n
+}
@@ -0,0 +1,119 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSmallTree(t *testing.T) {
+	r := New(WithSmallTree(8))
+	keys := []string{"foo", "", "foobar", "fo", "bar", "baz"}
+	for i, k := range keys {
+		r.Insert(k, i)
+	}
+	if !r.smallMode || len(r.small) != len(keys) || len(r.root.edges) != 0 {
+		t.Fatalf("tree should still be small")
+	}
+	checkKeys(t, r, []string{"", "bar", "baz", "fo", "foo", "foobar"})
+	if old, ok := r.Insert("foo", 10); !ok || old != 0 {
+		t.Fatalf("bad: %v %v", old, ok)
+	}
+	if v, ok := r.Get("foo"); !ok || v != 10 {
+		t.Fatalf("bad: %v %v", v, ok)
+	}
+	if k, _, ok := r.LongestPrefix("foob"); !ok || k != "foo" {
+		t.Fatalf("bad: %q", k)
+	}
+	var path []string
+	r.WalkPath("foobarbaz", func(k string, v interface{}) bool {
+		path = append(path, k)
+		return false
+	})
+	if !reflect.DeepEqual(path, []string{"", "fo", "foo", "foobar"}) {
+		t.Fatalf("bad: %v", path)
+	}
+	var under []string
+	r.WalkPrefix("ba", func(k string, v interface{}) bool {
+		under = append(under, k)
+		return false
+	})
+	if !reflect.DeepEqual(under, []string{"bar", "baz"}) {
+		t.Fatalf("bad: %v", under)
+	}
+	if k, _, ok := r.Successor("bar"); !ok || k != "baz" {
+		t.Fatalf("bad: %q", k)
+	}
+	if k, _, ok := r.Predecessor("bar"); !ok || k != "" {
+		t.Fatalf("bad: %q", k)
+	}
+	if n := r.DeletePrefix("foo"); n != 2 {
+		t.Fatalf("bad: %d", n)
+	}
+	if v, ok := r.Delete("bar"); !ok || v != 4 {
+		t.Fatalf("bad: %v %v", v, ok)
+	}
+	checkKeys(t, r, []string{"", "baz", "fo"})
+
+	// Deleting while walking
+	r.Walk(func(k string, v interface{}) bool {
+		r.Delete(k)
+		return false
+	})
+	if r.Len() != 0 || r.leaves.head != nil {
+		t.Fatalf("bad: %d", r.Len())
+	}
+}
+
+func TestSmallTree_Promote(t *testing.T) {
+	r := New(WithSmallTree(4), WithDenseDispatch(1))
+	plain := New()
+	for i := 0; i < 200; i++ {
+		k := generateUUID()[:i%7+1]
+		r.Insert(k, i)
+		plain.Insert(k, i)
+		if r.smallMode != (r.Len() <= 4) {
+			t.Fatalf("bad mode at %d entries", r.Len())
+		}
+	}
+	validateTree(t, r.root)
+	if !reflect.DeepEqual(r.ToMap(), plain.ToMap()) {
+		t.Fatalf("trees differ")
+	}
+	var got, want []string
+	r.Walk(func(k string, v interface{}) bool {
+		got = append(got, k)
+		return false
+	})
+	plain.Walk(func(k string, v interface{}) bool {
+		want = append(want, k)
+		return false
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("bad order: %v %v", got, want)
+	}
+}
+
+func TestSmallTree_NeedsNodes(t *testing.T) {
+	r := New(WithSmallTree(100), WithAggregate(func(k string, v interface{}) float64 {
+		return float64(v.(int))
+	}))
+	for i, k := range []string{"a", "ab", "b", "c"} {
+		r.Insert(k, i+1)
+	}
+	if f := r.Freeze(); !r.smallMode || f.Len() != 4 {
+		t.Fatalf("Freeze should not promote the tree")
+	}
+	if a := r.Aggregate("a"); a.Count != 2 || a.Sum != 3 {
+		t.Fatalf("bad: %+v", a)
+	}
+	if r.smallMode {
+		t.Fatalf("Aggregate should promote the tree")
+	}
+
+	left, right := r.Split("b")
+	checkKeys(t, left, []string{"a", "ab"})
+	checkKeys(t, right, []string{"b", "c"})
+	if !r.smallMode || r.Len() != 0 {
+		t.Fatalf("split tree should be empty and small")
+	}
+}
@@ -0,0 +1,254 @@
+package radix
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ValueCodec converts values to and from bytes for persistence
+type ValueCodec interface {
+	Encode(v interface{}) ([]byte, error)
+	Decode(b []byte) (interface{}, error)
+}
+
+var (
+	// ErrSnapshotVersion is returned when reading a snapshot written
+	// in a newer, incompatible format version.
+	ErrSnapshotVersion = errors.New("radix: unsupported snapshot version")
+
+	// ErrBadSnapshot is returned when reading a malformed snapshot
+	ErrBadSnapshot = errors.New("radix: malformed snapshot")
+)
+
+// A snapshot is a header followed by a sequence of sections. Each
+// section starts with an id, a flags byte and the payload length, so
+// a reader can skip sections it doesn't know. Sections flagged as
+// required can't be skipped; readers refuse snapshots holding unknown
+// required sections instead of silently losing data. New features
+// that older readers may ignore, like expiry times, go in optional
+// sections, and only a change to the meaning of existing sections
+// bumps the format version.
+const (
+	snapshotMagic   = "RADIXSNP"
+	snapshotVersion = 1
+
+	// sectionEnd terminates the snapshot
+	sectionEnd = 0
+
+	// sectionEntries holds a chunk of entries, in key order
+	sectionEntries = 1
+
+	// sectionExpiry holds the expiry of entries in the preceding
+	// entries section that have a TTL
+	sectionExpiry = 2
+
+	// sectionRequired marks a section readers must understand
+	sectionRequired = 1 << 0
+
+	// snapshotChunk is the number of entries per entries section,
+	// which bounds the memory used to write and read a section
+	snapshotChunk = 4096
+
+	// maxSectionLen guards against allocating for corrupt lengths
+	maxSectionLen = 1 << 30
+)
+
+// WriteSnapshot writes every entry in the tree to w, encoding values
+// with c. Entries keep their expiry, if any.
+func (t *Tree) WriteSnapshot(w io.Writer, c ValueCodec) error {
+	sw := &snapshotWriter{w: bufio.NewWriter(w)}
+	sw.w.WriteString(snapshotMagic)
+	sw.w.Write(sw.tmp[:binary.PutUvarint(sw.tmp[:], snapshotVersion)])
+
+	for l := t.leaves.head; l != nil; {
+		var entries, expiry bytes.Buffer
+		var numExpiring int
+		i := 0
+		for ; l != nil && i < snapshotChunk; l, i = l.next, i+1 {
+			b, err := c.Encode(l.val)
+			if err != nil {
+				return fmt.Errorf("radix: encoding value of %q: %w", l.key, err)
+			}
+			sw.bytes(&entries, []byte(l.key))
+			sw.bytes(&entries, b)
+			if l.expires() != 0 {
+				sw.uvarint(&expiry, uint64(i))
+				sw.varint(&expiry, l.ext.expires)
+				sw.varint(&expiry, int64(l.ext.ttl))
+				numExpiring++
+			}
+		}
+		sw.section(sectionEntries, sectionRequired, i, &entries)
+		if numExpiring > 0 {
+			sw.section(sectionExpiry, 0, numExpiring, &expiry)
+		}
+	}
+	sw.section(sectionEnd, 0, 0, &bytes.Buffer{})
+	return sw.w.Flush()
+}
+
+// snapshotWriter encodes sections. Write errors are sticky in the
+// bufio.Writer and reported by the final Flush.
+type snapshotWriter struct {
+	w   *bufio.Writer
+	hdr bytes.Buffer
+	tmp [binary.MaxVarintLen64]byte
+}
+
+func (sw *snapshotWriter) uvarint(b *bytes.Buffer, v uint64) {
+	b.Write(sw.tmp[:binary.PutUvarint(sw.tmp[:], v)])
+}
+
+func (sw *snapshotWriter) varint(b *bytes.Buffer, v int64) {
+	b.Write(sw.tmp[:binary.PutVarint(sw.tmp[:], v)])
+}
+
+func (sw *snapshotWriter) bytes(b *bytes.Buffer, p []byte) {
+	sw.uvarint(b, uint64(len(p)))
+	b.Write(p)
+}
+
+// section writes a section holding count records
+func (sw *snapshotWriter) section(id, flags byte, count int, payload *bytes.Buffer) {
+	sw.hdr.Reset()
+	sw.hdr.WriteByte(id)
+	sw.hdr.WriteByte(flags)
+	var body bytes.Buffer
+	sw.uvarint(&body, uint64(count))
+	sw.uvarint(&sw.hdr, uint64(body.Len()+payload.Len()))
+	sw.w.Write(sw.hdr.Bytes())
+	sw.w.Write(body.Bytes())
+	sw.w.Write(payload.Bytes())
+}
+
+// ReadSnapshot inserts every entry of a snapshot written by
+// WriteSnapshot into the tree, decoding values with c. Entries whose
+// expiry has passed are skipped. Sections added by newer versions
+// of this package are skipped if optional, so snapshots can be read
+// by older and newer code alike while a fleet is being upgraded.
+// Entries are stored with the same limits as TryInsert, and an entry
+// that doesn't fit stops the read with its error.
+func (t *Tree) ReadSnapshot(r io.Reader, c ValueCodec) error {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(snapshotMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != snapshotMagic {
+		return ErrBadSnapshot
+	}
+	version, err := binary.ReadUvarint(br)
+	if err != nil {
+		return ErrBadSnapshot
+	}
+	if version > snapshotVersion {
+		return ErrSnapshotVersion
+	}
+
+	var keys []string
+	for {
+		id, err := br.ReadByte()
+		if err != nil {
+			return ErrBadSnapshot
+		}
+		flags, err := br.ReadByte()
+		if err != nil {
+			return ErrBadSnapshot
+		}
+		n, err := binary.ReadUvarint(br)
+		if err != nil || n > maxSectionLen {
+			return ErrBadSnapshot
+		}
+		payload := make([]byte, n)
+		if _, err := io.ReadFull(br, payload); err != nil {
+			return ErrBadSnapshot
+		}
+
+		sr := &snapshotReader{b: payload}
+		switch id {
+		case sectionEnd:
+			return nil
+		case sectionEntries:
+			count := sr.uvarint()
+			keys = keys[:0]
+			for i := uint64(0); i < count && sr.err == nil; i++ {
+				key, val := sr.bytes(), sr.bytes()
+				if sr.err != nil {
+					break
+				}
+				v, err := c.Decode(val)
+				if err != nil {
+					return fmt.Errorf("radix: decoding value of %q: %w", key, err)
+				}
+				k := string(key)
+				if err := t.checkInsert(k, v); err != nil {
+					return err
+				}
+				t.insert(k, v, 0)
+				keys = append(keys, k)
+			}
+		case sectionExpiry:
+			count := sr.uvarint()
+			now := t.now()
+			for i := uint64(0); i < count && sr.err == nil; i++ {
+				idx, expires, ttl := sr.uvarint(), sr.varint(), sr.varint()
+				if sr.err != nil || idx >= uint64(len(keys)) {
+					return ErrBadSnapshot
+				}
+				if expires <= now {
+					t.Delete(keys[idx])
+					continue
+				}
+				if l := t.getLeaf(keys[idx]); l != nil {
+					t.setExpiryAt(l, expires, time.Duration(ttl))
+				}
+			}
+		default:
+			if flags&sectionRequired != 0 {
+				return ErrSnapshotVersion
+			}
+		}
+		if sr.err != nil {
+			return ErrBadSnapshot
+		}
+	}
+}
+
+// snapshotReader decodes the records of a section. Errors are sticky.
+type snapshotReader struct {
+	b   []byte
+	err error
+}
+
+func (sr *snapshotReader) uvarint() uint64 {
+	v, n := binary.Uvarint(sr.b)
+	if n <= 0 {
+		sr.err = ErrBadSnapshot
+		return 0
+	}
+	sr.b = sr.b[n:]
+	return v
+}
+
+func (sr *snapshotReader) varint() int64 {
+	v, n := binary.Varint(sr.b)
+	if n <= 0 {
+		sr.err = ErrBadSnapshot
+		return 0
+	}
+	sr.b = sr.b[n:]
+	return v
+}
+
+func (sr *snapshotReader) bytes() []byte {
+	n := sr.uvarint()
+	if sr.err != nil || n > uint64(len(sr.b)) {
+		sr.err = ErrBadSnapshot
+		return nil
+	}
+	p := sr.b[:n]
+	sr.b = sr.b[n:]
+	return p
+}
@@ -0,0 +1,117 @@
+package radix
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// stringCodec persists string values
+type stringCodec struct{}
+
+func (stringCodec) Encode(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("not a string: %T", v)
+	}
+	return []byte(s), nil
+}
+
+func (stringCodec) Decode(b []byte) (interface{}, error) {
+	return string(b), nil
+}
+
+func TestSnapshot(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	r := New(WithClock(clock))
+	var keys []string
+	for i := 0; i < 2*snapshotChunk+10; i++ {
+		k := fmt.Sprintf("key%06d", i)
+		keys = append(keys, k)
+		r.Insert(k, "v"+k)
+	}
+	r.InsertWithTTL("key000003", "short", time.Minute)
+	r.InsertWithTTL(keys[snapshotChunk+1], "long", time.Hour)
+
+	var buf bytes.Buffer
+	if err := r.WriteSnapshot(&buf, stringCodec{}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	clock.advance(2 * time.Minute)
+	out := New(WithClock(clock))
+	if err := out.ReadSnapshot(bytes.NewReader(buf.Bytes()), stringCodec{}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	checkKeys(t, out, append(append([]string(nil), keys[:3]...), keys[4:]...))
+	if v, _ := out.Get("key000010"); v != "vkey000010" {
+		t.Fatalf("bad: %v", v)
+	}
+	if d, ok := out.TTL(keys[snapshotChunk+1]); !ok || d != time.Hour-2*time.Minute {
+		t.Fatalf("bad: %v %v", d, ok)
+	}
+	clock.advance(30 * time.Minute)
+	if !out.Touch(keys[snapshotChunk+1]) {
+		t.Fatalf("bad")
+	}
+	if d, _ := out.TTL(keys[snapshotChunk+1]); d != time.Hour {
+		t.Fatalf("the original TTL should be restored: %v", d)
+	}
+
+	r.Insert("bad", 1)
+	if err := r.WriteSnapshot(&bytes.Buffer{}, stringCodec{}); err == nil {
+		t.Fatalf("expected encoding error")
+	}
+}
+
+// craftSnapshot writes a snapshot with the given extra section
+// placed before the end marker
+func craftSnapshot(version uint64, id, flags byte) []byte {
+	var buf bytes.Buffer
+	sw := &snapshotWriter{w: bufio.NewWriter(&buf)}
+	sw.w.WriteString(snapshotMagic)
+	var hdr bytes.Buffer
+	sw.uvarint(&hdr, version)
+	sw.w.Write(hdr.Bytes())
+
+	var entries bytes.Buffer
+	sw.bytes(&entries, []byte("foo"))
+	sw.bytes(&entries, []byte("bar"))
+	sw.section(sectionEntries, sectionRequired, 1, &entries)
+	sw.section(id, flags, 3, bytes.NewBufferString("future data"))
+	sw.section(sectionEnd, 0, 0, &bytes.Buffer{})
+	sw.w.Flush()
+	return buf.Bytes()
+}
+
+func TestSnapshot_Compatibility(t *testing.T) {
+	// Unknown optional sections are skipped
+	r := New()
+	if err := r.ReadSnapshot(bytes.NewReader(craftSnapshot(1, 99, 0)), stringCodec{}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if v, ok := r.Get("foo"); !ok || v != "bar" {
+		t.Fatalf("bad: %v %v", v, ok)
+	}
+
+	// Unknown required sections and newer versions are refused
+	err := New().ReadSnapshot(bytes.NewReader(craftSnapshot(1, 99, sectionRequired)), stringCodec{})
+	if err != ErrSnapshotVersion {
+		t.Fatalf("err: %v", err)
+	}
+	err = New().ReadSnapshot(bytes.NewReader(craftSnapshot(2, 99, 0)), stringCodec{})
+	if err != ErrSnapshotVersion {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Truncated and corrupt snapshots are detected
+	data := craftSnapshot(1, 99, 0)
+	for _, b := range [][]byte{nil, data[:5], data[:len(data)-1], []byte("RADIXSNP\x01\x01\x01\x05\x01\xff")} {
+		if err := New().ReadSnapshot(bytes.NewReader(b), stringCodec{}); !errors.Is(err, ErrBadSnapshot) {
+			t.Fatalf("err: %v", err)
+		}
+	}
+}
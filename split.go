@@ -0,0 +1,250 @@
+package radix
+
+import (
+	"container/heap"
+	"sort"
+	"strings"
+)
+
+// Split partitions the tree into two: left holds every key less than
+// key, and right every key greater than or equal to it. Subtrees that
+// fall entirely on one side are moved rather than copied, so only the
+// nodes along the path to key are rebuilt. Both trees inherit the
+// configuration of t, which is left empty, and each runs its own
+// janitor if t has one.
+func (t *Tree) Split(key string) (left, right *Tree) {
+	key = t.norm(key)
+	t.promote()
+	left, right = t.derive(), t.derive()
+	l, r := t.splitNode(t.root, key, 0)
+	if l != nil {
+		left.adopt(l)
+	}
+	if r != nil {
+		right.adopt(r)
+	}
+	t.redistributeOrder(func(k string) *Tree {
+		if t.collation.Compare(k, key) < 0 {
+			return left
+		}
+		return right
+	})
+	left.refreshPath(key)
+	right.refreshPath(key)
+	left.inheritJanitor(t.janitor)
+	right.inheritJanitor(t.janitor)
+
+	janitor, mods, gen := t.janitor, t.mods, t.generation
+	*t = *t.derive()
+	t.janitor, t.mods, t.generation = janitor, mods+1, gen+1
+	return left, right
+}
+
+// splitNode splits the subtree under n, whose path ends at offset
+// end, around the key whose remainder below n is search. Either
+// result may be nil if that side is empty. n itself is reused for
+// the right hand side.
+func (t *Tree) splitNode(n *node, search string, end int) (l, r *node) {
+	// Every key under n is at least the search key
+	if len(search) == 0 {
+		return nil, n
+	}
+
+	l = t.newNode(n.prefix, end)
+	l.leaf = n.leaf
+	n.leaf = nil
+
+	edges := n.edges
+	n.clearEdges()
+
+	label := search[0]
+	for _, e := range edges {
+		switch {
+		case t.collation.less(e.label, label):
+			l.addEdge(e)
+		case t.collation.less(label, e.label):
+			n.addEdge(e)
+		default:
+			cl, cr := t.splitChild(e.node, search, end)
+			if cl != nil {
+				l.addEdge(edge{label: label, node: cl})
+			}
+			if cr != nil {
+				n.addEdge(edge{label: label, node: cr})
+			}
+		}
+	}
+	return compact(l), compact(n)
+}
+
+// splitChild splits the child reached from a node ending at end,
+// where search starts with the child's label.
+func (t *Tree) splitChild(c *node, search string, end int) (l, r *node) {
+	common := longestPrefix(search, c.prefix)
+	switch {
+	case common == len(c.prefix):
+		// The key continues below the child
+		return t.splitNode(c, search[common:], end+common)
+	case common == len(search):
+		// The key is a prefix of everything under the child
+		return nil, c
+	case t.collation.less(c.prefix[common], search[common]):
+		return c, nil
+	default:
+		return nil, c
+	}
+}
+
+// compact restores the node invariants after edges were removed,
+// returning nil for a node that no longer holds anything.
+func compact(n *node) *node {
+	switch {
+	case n.leaf == nil && len(n.edges) == 0:
+		return nil
+	case n.leaf == nil && len(n.edges) == 1 && n.prefix != "":
+		n.mergeChild()
+	}
+	return n
+}
+
+// adopt installs n, a subtree detached from another tree with the
+// same configuration, as the contents of the empty tree t.
+func (t *Tree) adopt(n *node) {
+	t.root = n
+	t.smallMode = false
+	t.leaves = leafList{head: minLeaf(n), tail: maxLeaf(n)}
+	if t.leaves.head != nil {
+		t.leaves.head.prev = nil
+		t.leaves.tail.next = nil
+	}
+	forEachLeaf(n, func(l *leafNode) {
+		t.size++
+		t.bytes += t.entryBytes(l.key, l.val)
+		if l.expires() != 0 {
+			heap.Push(&t.expiry, l)
+		}
+		if t.lfuCap > 0 {
+			heap.Push(&t.lfu, l)
+		}
+	})
+}
+
+// Partition moves the entries under each of the given prefixes into a
+// tree of their own, returned keyed by prefix. Entries matching none of
+// the prefixes stay in t as the remainder. Where prefixes overlap, an
+// entry goes to the longest prefix that matches it. Subtrees are moved
+// rather than copied, and the new trees inherit the configuration of t,
+// each running its own janitor if t has one.
+func (t *Tree) Partition(prefixes []string) map[string]*Tree {
+	norms := make(map[string]string, len(prefixes))
+	for _, prefix := range prefixes {
+		norms[prefix] = t.norm(prefix)
+	}
+	sorted := make([]string, len(prefixes))
+	copy(sorted, prefixes)
+	sort.Slice(sorted, func(i, j int) bool {
+		return len(norms[sorted[i]]) > len(norms[sorted[j]])
+	})
+
+	out := make(map[string]*Tree, len(prefixes))
+	for _, prefix := range sorted {
+		if _, ok := out[prefix]; ok {
+			continue
+		}
+		p := t.derive()
+		if n := t.detachPrefix(norms[prefix]); n != nil {
+			p.adopt(n)
+			p.refreshPath("")
+			t.refreshPath(norms[prefix])
+		}
+		out[prefix] = p
+	}
+
+	if t.insertionOrder {
+		match := New()
+		for _, prefix := range prefixes {
+			match.Insert(prefix, out[prefix])
+		}
+		t.redistributeOrder(func(k string) *Tree {
+			if _, p, ok := match.LongestPrefix(k); ok {
+				return p.(*Tree)
+			}
+			return t
+		})
+	}
+	for _, p := range out {
+		p.inheritJanitor(t.janitor)
+	}
+	return out
+}
+
+// detachPrefix removes the subtree holding every key that starts with
+// prefix and returns it as the root of a new tree, or nil if there
+// are no such keys.
+func (t *Tree) detachPrefix(prefix string) *node {
+	t.promote()
+	if len(prefix) == 0 {
+		n := t.root
+		order, janitor, mods, gen := t.order, t.janitor, t.mods, t.generation
+		*t = *t.derive()
+		t.order, t.janitor, t.mods, t.generation = order, janitor, mods+1, gen+1
+		return n
+	}
+
+	var parent *node
+	n := t.root
+	search := prefix
+	for {
+		parent = n
+		n = n.getEdge(search[0])
+		if n == nil {
+			return nil
+		}
+		if strings.HasPrefix(search, n.prefix) {
+			search = search[len(n.prefix):]
+			if len(search) > 0 {
+				continue
+			}
+		} else if !strings.HasPrefix(n.prefix, search) {
+			return nil
+		}
+		break
+	}
+
+	// Unlink n, keeping the parent compact
+	t.mods++
+	t.generation++
+	t.leaves.removeRange(minLeaf(n), maxLeaf(n))
+	parent.delEdge(n.prefix[0])
+	if parent != t.root && parent.leaf == nil && len(parent.edges) == 1 {
+		parent.mergeChild()
+	}
+	forEachLeaf(n, func(l *leafNode) {
+		t.size--
+		t.bytes -= t.entryBytes(l.key, l.val)
+		if l.expires() != 0 {
+			t.expiry.remove(l)
+		}
+		if t.lfuCap > 0 {
+			heap.Remove(&t.lfu, l.ext.lfuIdx)
+		}
+	})
+
+	// Hang n off a fresh root, its prefix now being the full path
+	end := len(prefix)
+	if len(search) > 0 {
+		end += len(n.prefix) - len(search)
+	}
+	n.prefix = anyLeaf(n).key[:end]
+	root := t.newNode("", 0)
+	root.addEdge(edge{label: n.prefix[0], node: n})
+	return root
+}
+
+// anyLeaf returns some leaf under n, which must not be empty
+func anyLeaf(n *node) *leafNode {
+	for n.leaf == nil {
+		n = n.edges[0].node
+	}
+	return n.leaf
+}
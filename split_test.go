@@ -0,0 +1,126 @@
+package radix
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	keys := []string{"", "a", "ab", "abc", "abd", "b", "ba", "bab", "c", "foo/bar", "foo/baz", "foobar"}
+	splits := []string{"", "a", "aa", "ab", "abb", "abc", "abcd", "abz", "b", "bb", "foo", "foo/", "foo/bas", "foo/bar", "fooz", "zzz"}
+	for _, key := range splits {
+		r := New(WithDenseDispatch(2))
+		for _, k := range keys {
+			r.Insert(k, k)
+		}
+		left, right := r.Split(key)
+
+		if r.Len() != 0 {
+			t.Fatalf("original tree should be empty: %d", r.Len())
+		}
+		if left.Len()+right.Len() != len(keys) {
+			t.Fatalf("split %q: bad sizes %d %d", key, left.Len(), right.Len())
+		}
+		validateTree(t, left.root)
+		validateTree(t, right.root)
+
+		var wantLeft, wantRight []string
+		for _, k := range keys {
+			if k < key {
+				wantLeft = append(wantLeft, k)
+			} else {
+				wantRight = append(wantRight, k)
+			}
+		}
+		checkKeys(t, left, wantLeft)
+		checkKeys(t, right, wantRight)
+
+		// The halves must remain fully usable
+		left.Insert("new", 1)
+		right.Delete("c")
+		validateTree(t, left.root)
+		validateTree(t, right.root)
+	}
+}
+
+func TestSplit_Random(t *testing.T) {
+	r := New()
+	var keys []string
+	for i := 0; i < 1000; i++ {
+		k := generateUUID()
+		keys = append(keys, k)
+		r.Insert(k, i)
+	}
+	sort.Strings(keys)
+	key := keys[500][:5]
+
+	left, right := r.Split(key)
+	i := sort.SearchStrings(keys, key)
+	checkKeys(t, left, keys[:i])
+	checkKeys(t, right, keys[i:])
+	if left.BytesUsed()+right.BytesUsed() != 1000*(entryOverhead+36) {
+		t.Fatalf("bad bytes: %d %d", left.BytesUsed(), right.BytesUsed())
+	}
+}
+
+// checkKeys verifies that r holds exactly the given keys, in order
+func checkKeys(t *testing.T, r *Tree, want []string) {
+	t.Helper()
+	var got []string
+	r.Walk(func(k string, v interface{}) bool {
+		got = append(got, k)
+		return false
+	})
+	if len(got) != len(want) || r.Len() != len(want) {
+		t.Fatalf("mis-match: %q %q (len %d)", got, want, r.Len())
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("mis-match: %q %q", got, want)
+		}
+		if _, ok := r.Get(want[i]); !ok {
+			t.Fatalf("missing key: %q", want[i])
+		}
+	}
+
+	// The key order links must agree with the structure
+	i := 0
+	for l := r.leaves.head; l != nil; l = l.next {
+		if i >= len(want) || l.key != want[i] {
+			t.Fatalf("bad leaf links at %d: %q", i, l.key)
+		}
+		i++
+	}
+	if i != len(want) {
+		t.Fatalf("bad leaf links length: %d %d", i, len(want))
+	}
+}
+
+func TestPartition(t *testing.T) {
+	keys := []string{"", "a", "ab", "abc", "abd", "b", "ba", "bab", "c", "foo/bar", "foo/baz", "foobar", "tenant1/x", "tenant1/y", "tenant2/x"}
+	r := New()
+	for _, k := range keys {
+		r.Insert(k, k)
+	}
+	parts := r.Partition([]string{"tenant1/", "tenant2/", "tenant3/", "ab", "a", "foo/ba", "ab"})
+	if len(parts) != 6 {
+		t.Fatalf("bad number of partitions: %d", len(parts))
+	}
+
+	checkKeys(t, parts["tenant1/"], []string{"tenant1/x", "tenant1/y"})
+	checkKeys(t, parts["tenant2/"], []string{"tenant2/x"})
+	checkKeys(t, parts["tenant3/"], nil)
+	checkKeys(t, parts["ab"], []string{"ab", "abc", "abd"})
+	checkKeys(t, parts["a"], []string{"a"})
+	checkKeys(t, parts["foo/ba"], []string{"foo/bar", "foo/baz"})
+	checkKeys(t, r, []string{"", "b", "ba", "bab", "c", "foobar"})
+	for _, p := range parts {
+		validateTree(t, p.root)
+	}
+	validateTree(t, r.root)
+
+	// Everything goes with the empty prefix
+	all := r.Partition([]string{""})
+	checkKeys(t, all[""], []string{"", "b", "ba", "bab", "c", "foobar"})
+	checkKeys(t, r, nil)
+}
@@ -0,0 +1,173 @@
+package radix
+
+import (
+	"strings"
+	"unsafe"
+)
+
+// Stats describes the shape of a tree, which shows how well the
+// radix layout suits its keys: long shared prefixes and few, shallow
+// branch points make for a compact tree.
+type Stats struct {
+	// Keys is the number of entries, and Nodes the number of nodes
+	// they take, including the root
+	Keys  int
+	Nodes int
+
+	// KeyLengths counts the keys of each length: KeyLengths[i] is
+	// the number of keys i bytes long
+	KeyLengths       []int
+	MeanKeyLength    float64
+	MeanSharedPrefix float64
+
+	// BranchDepths counts the nodes with more than one edge by the
+	// length of their path, and MaxFanout is the most edges of any
+	// node
+	BranchDepths []int
+	MaxFanout    int
+}
+
+// Stats walks the whole tree to describe its shape. MeanSharedPrefix is
+// the mean length of the prefix each key shares with the key before
+// it in key order.
+func (t *Tree) Stats() Stats {
+	var s Stats
+	var prev string
+	var keyBytes, shared int
+	for l := t.leaves.head; l != nil; l = l.next {
+		if len(l.key) >= len(s.KeyLengths) {
+			s.KeyLengths = append(s.KeyLengths, make([]int, len(l.key)-len(s.KeyLengths)+1)...)
+		}
+		s.KeyLengths[len(l.key)]++
+		keyBytes += len(l.key)
+		if s.Keys > 0 {
+			shared += longestPrefix(prev, l.key)
+		}
+		prev = l.key
+		s.Keys++
+	}
+	if s.Keys > 0 {
+		s.MeanKeyLength = float64(keyBytes) / float64(s.Keys)
+	}
+	if s.Keys > 1 {
+		s.MeanSharedPrefix = float64(shared) / float64(s.Keys-1)
+	}
+
+	root := t.root
+	if t.smallMode {
+		// Describe the nodes the tree would have
+		root = t.buildNode("", 0, t.small)
+	}
+	var visit func(n *node, depth int)
+	visit = func(n *node, depth int) {
+		s.Nodes++
+		if len(n.edges) > s.MaxFanout {
+			s.MaxFanout = len(n.edges)
+		}
+		if len(n.edges) > 1 {
+			if depth >= len(s.BranchDepths) {
+				s.BranchDepths = append(s.BranchDepths, make([]int, depth-len(s.BranchDepths)+1)...)
+			}
+			s.BranchDepths[depth]++
+		}
+		for _, e := range n.edges {
+			visit(e.node, depth+len(e.node.prefix))
+		}
+	}
+	visit(root, 0)
+	return s
+}
+
+// SubtreeStats describes the part of a tree under a prefix, see
+// Tree.SubtreeStats
+type SubtreeStats struct {
+	Entries int
+	Nodes   int
+
+	// Bytes estimates the memory held by the nodes and entries,
+	// counting values if the tree has a value sizer
+	Bytes int
+
+	// MaxDepth is the number of nodes on the longest path down from
+	// the top of the subtree
+	MaxDepth int
+}
+
+// SubtreeStats describes the entries starting with prefix and the
+// nodes holding them, so the memory of a tree shared by tenants or
+// namespaces can be attributed to each. A node whose prefix runs past
+// the given one is counted whole. The entries of a small tree, see
+// WithSmallTree, take no nodes.
+func (t *Tree) SubtreeStats(prefix string) SubtreeStats {
+	prefix = t.norm(prefix)
+	var s SubtreeStats
+	if t.smallMode {
+		i, j := t.prefixRangeSmall(prefix)
+		for _, l := range t.small[i:j] {
+			s.Entries++
+			s.Bytes += t.leafBytes(l) + int(unsafe.Sizeof(l))
+		}
+		return s
+	}
+
+	n := t.root
+	search := prefix
+	for len(search) > 0 {
+		n = n.getEdge(search[0])
+		if n == nil {
+			return s
+		}
+		if strings.HasPrefix(search, n.prefix) {
+			search = search[len(n.prefix):]
+			continue
+		}
+		if !strings.HasPrefix(n.prefix, search) {
+			return s
+		}
+		break
+	}
+	s.MaxDepth = t.subtreeStats(n, &s)
+	return s
+}
+
+// subtreeStats adds n and everything under it to s, and returns the
+// depth of n's subtree
+func (t *Tree) subtreeStats(n *node, s *SubtreeStats) int {
+	s.Nodes++
+	s.Bytes += int(unsafe.Sizeof(*n)) + len(n.prefix) + cap(n.edges)*int(unsafe.Sizeof(edge{}))
+	if x := n.ext; x != nil {
+		s.Bytes += int(unsafe.Sizeof(*x))
+		if x.dense != nil {
+			s.Bytes += int(unsafe.Sizeof(*x.dense))
+		}
+		if x.bitmap != nil {
+			s.Bytes += int(unsafe.Sizeof(*x.bitmap))
+		}
+		if x.agg != nil {
+			s.Bytes += int(unsafe.Sizeof(*x.agg))
+		}
+	}
+	if n.leaf != nil {
+		s.Entries++
+		s.Bytes += t.leafBytes(n.leaf)
+	}
+	var depth int
+	for _, e := range n.edges {
+		if d := t.subtreeStats(e.node, s); d > depth {
+			depth = d
+		}
+	}
+	return depth + 1
+}
+
+// leafBytes estimates the memory held by a leaf and its entry
+func (t *Tree) leafBytes(l *leafNode) int {
+	b := int(unsafe.Sizeof(*l)) + len(l.key)
+	if l.ext != nil {
+		b += int(unsafe.Sizeof(*l.ext))
+	}
+	if t.sizer != nil {
+		b += t.sizer(l.val)
+	}
+	return b
+}
@@ -0,0 +1,62 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithSmallTree(10)}} {
+		r := New(opts...)
+		for _, k := range []string{"foo", "foobar", "fizz", "bar"} {
+			r.Insert(k, nil)
+		}
+		want := Stats{
+			Keys:          4,
+			Nodes:         6, // root, "bar", "f", "oo", "bar", "izz"
+			KeyLengths:    []int{0, 0, 0, 2, 1, 0, 1},
+			MeanKeyLength: 16.0 / 4,
+			// "bar" → "fizz" → "foo" → "foobar"
+			MeanSharedPrefix: (0 + 1 + 3) / 3.0,
+			BranchDepths:     []int{1, 1},
+			MaxFanout:        2,
+		}
+		if got := r.Stats(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("bad: %+v", got)
+		}
+	}
+	if s := New().Stats(); s.Nodes != 1 || s.Keys != 0 || s.MeanKeyLength != 0 {
+		t.Fatalf("bad: %+v", s)
+	}
+}
+
+func TestSubtreeStats(t *testing.T) {
+	r := New(WithValueSizer(func(v interface{}) int { return 100 }))
+	for _, k := range []string{"tenant1/a", "tenant1/b/c", "tenant1/b/d", "tenant2/a"} {
+		r.Insert(k, nil)
+	}
+	s := r.SubtreeStats("tenant1/")
+	// "1/" covers the prefix, then "a", "b/", "c" and "d"
+	if s.Entries != 3 || s.Nodes != 5 || s.MaxDepth != 3 {
+		t.Fatalf("bad: %+v", s)
+	}
+	if s.Bytes < 3*100 || s.Bytes >= r.SubtreeStats("").Bytes {
+		t.Fatalf("bad: %+v", s)
+	}
+	if s := r.SubtreeStats("tenant1/b/"); s.Entries != 2 || s.Nodes != 3 || s.MaxDepth != 2 {
+		t.Fatalf("bad: %+v", s)
+	}
+	if s := r.SubtreeStats("tenant1/b"); s.Entries != 2 || s.Nodes != 3 {
+		t.Fatalf("bad: %+v", s)
+	}
+	if s := r.SubtreeStats("tenant3"); s != (SubtreeStats{}) {
+		t.Fatalf("bad: %+v", s)
+	}
+
+	small := New(WithSmallTree(10))
+	small.Insert("tenant1/a", nil)
+	small.Insert("tenant2/a", nil)
+	if s := small.SubtreeStats("tenant1"); s.Entries != 1 || s.Nodes != 0 || s.Bytes == 0 {
+		t.Fatalf("bad: %+v", s)
+	}
+}
@@ -0,0 +1,121 @@
+package radix
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Store is a backing store that a tree keeps up to date by writing
+// through every mutation, see WithStore and WithAsyncStore. The tree
+// remains the source for reads.
+type Store interface {
+	Put(key string, v interface{}) error
+	Delete(key string) error
+}
+
+// StoreError reports a failed write to a Store
+type StoreError struct {
+	Op  string // "put" or "delete"
+	Key string
+	Err error
+}
+
+func (e *StoreError) Error() string {
+	return fmt.Sprintf("radix: store %s %q: %v", e.Op, e.Key, e.Err)
+}
+
+func (e *StoreError) Unwrap() error {
+	return e.Err
+}
+
+// storeOp is a single pending write
+type storeOp struct {
+	del bool
+	key string
+	val interface{}
+}
+
+// storeWriter forwards mutations to a Store, either directly or
+// through a queue drained by a background goroutine
+type storeWriter struct {
+	store Store
+	onErr func(error)
+
+	queue chan storeOp
+	done  chan struct{}
+	once  sync.Once
+}
+
+func newStoreWriter(s Store, onErr func(error), async bool, queueSize int) *storeWriter {
+	w := &storeWriter{store: s, onErr: onErr}
+	if async {
+		w.queue = make(chan storeOp, queueSize)
+		w.done = make(chan struct{})
+		go w.run()
+	}
+	return w
+}
+
+// write applies or enqueues a single operation
+func (w *storeWriter) write(op storeOp) {
+	if w.queue != nil {
+		w.queue <- op
+		return
+	}
+	w.apply(op)
+}
+
+func (w *storeWriter) apply(op storeOp) {
+	var err error
+	if op.del {
+		err = w.store.Delete(op.key)
+	} else {
+		err = w.store.Put(op.key, op.val)
+	}
+	if err != nil && w.onErr != nil {
+		name := "put"
+		if op.del {
+			name = "delete"
+		}
+		w.onErr(&StoreError{Op: name, Key: op.key, Err: err})
+	}
+}
+
+// run drains the queue until it is closed
+func (w *storeWriter) run() {
+	defer close(w.done)
+	for op := range w.queue {
+		w.apply(op)
+	}
+}
+
+// close flushes any queued writes and stops the goroutine
+func (w *storeWriter) close() {
+	if w.queue == nil {
+		return
+	}
+	w.once.Do(func() {
+		close(w.queue)
+	})
+	<-w.done
+}
+
+// Close releases background resources held by the tree, stopping
+// its janitor and waiting for queued store writes and log commits to
+// be flushed. It returns an error if committing the log failed.
+// Trees derived from this one, such as the results of Split, share
+// the store. Close must not be called while holding the janitor's
+// lock, and the tree must not be modified after Close.
+func (t *Tree) Close() error {
+	if t.janitor != nil {
+		t.janitor.close()
+	}
+	if t.store != nil {
+		t.store.close()
+	}
+	if t.wal != nil && t.wal.committer != nil {
+		t.wal.committer.close()
+		return t.wal.committer.err
+	}
+	return nil
+}
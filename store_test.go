@@ -0,0 +1,90 @@
+package radix
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// mapStore is an in-memory Store for tests
+type mapStore struct {
+	sync.Mutex
+	m    map[string]interface{}
+	fail bool
+}
+
+func newMapStore() *mapStore {
+	return &mapStore{m: make(map[string]interface{})}
+}
+
+func (s *mapStore) Put(key string, v interface{}) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.fail {
+		return errors.New("unavailable")
+	}
+	s.m[key] = v
+	return nil
+}
+
+func (s *mapStore) Delete(key string) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.fail {
+		return errors.New("unavailable")
+	}
+	delete(s.m, key)
+	return nil
+}
+
+// mutateForStore applies a fixed set of mutations to r
+func mutateForStore(r *Tree) {
+	for _, k := range []string{"a", "ab", "abc", "b", "bc", "c"} {
+		r.Insert(k, k)
+	}
+	r.Insert("a", "updated")
+	r.Delete("c")
+	r.DeletePrefix("b")
+}
+
+func TestStore(t *testing.T) {
+	s := newMapStore()
+	var errs []error
+	r := New(WithStore(s, func(err error) {
+		errs = append(errs, err)
+	}))
+	mutateForStore(r)
+	if !reflect.DeepEqual(s.m, r.ToMap()) {
+		t.Fatalf("store out of sync: %v %v", s.m, r.ToMap())
+	}
+
+	s.fail = true
+	r.Insert("x", 1)
+	r.Delete("a")
+	if len(errs) != 2 {
+		t.Fatalf("bad errors: %v", errs)
+	}
+	var serr *StoreError
+	if !errors.As(errs[1], &serr) || serr.Op != "delete" || serr.Key != "a" {
+		t.Fatalf("bad error: %v", errs[1])
+	}
+	if _, ok := r.Get("x"); !ok {
+		t.Fatalf("failed store writes should not undo the change")
+	}
+}
+
+func TestAsyncStore(t *testing.T) {
+	s := newMapStore()
+	r := New(WithAsyncStore(s, nil, 2))
+	mutateForStore(r)
+	if err := r.Close(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(s.m, r.ToMap()) {
+		t.Fatalf("store out of sync: %v %v", s.m, r.ToMap())
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("close should be idempotent: %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package radix
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// StringTree is a tree of string values, for the common case of
+// routing tables and configuration that map strings to strings. It
+// marshals to and from a JSON object.
+type StringTree struct {
+	t *Tree
+}
+
+// NewStringTree returns an empty tree of strings
+func NewStringTree(opts ...Option) *StringTree {
+	return &StringTree{t: New(opts...)}
+}
+
+// Tree returns the underlying tree, whose values are all strings
+func (s *StringTree) Tree() *Tree {
+	return s.t
+}
+
+// Insert adds or updates an entry, like Tree.Insert
+func (s *StringTree) Insert(k, v string) (string, bool) {
+	old, updated := s.t.Insert(k, v)
+	return asString(old), updated
+}
+
+// Delete removes an entry, like Tree.Delete
+func (s *StringTree) Delete(k string) (string, bool) {
+	old, ok := s.t.Delete(k)
+	return asString(old), ok
+}
+
+// Get looks up a key, like Tree.Get
+func (s *StringTree) Get(k string) (string, bool) {
+	v, ok := s.t.Get(k)
+	return asString(v), ok
+}
+
+// LongestPrefix finds the longest key that is a prefix of k, like
+// Tree.LongestPrefix
+func (s *StringTree) LongestPrefix(k string) (string, string, bool) {
+	m, v, ok := s.t.LongestPrefix(k)
+	return m, asString(v), ok
+}
+
+// Len returns the number of entries
+func (s *StringTree) Len() int {
+	return s.t.Len()
+}
+
+// Walk visits every entry, like Tree.Walk
+func (s *StringTree) Walk(fn func(k, v string) bool) {
+	s.t.Walk(func(k string, v interface{}) bool {
+		return fn(k, asString(v))
+	})
+}
+
+// WalkPrefix visits the entries under prefix, like Tree.WalkPrefix
+func (s *StringTree) WalkPrefix(prefix string, fn func(k, v string) bool) {
+	s.t.WalkPrefix(prefix, func(k string, v interface{}) bool {
+		return fn(k, asString(v))
+	})
+}
+
+// ToMap returns the entries as a map
+func (s *StringTree) ToMap() map[string]string {
+	out := make(map[string]string, s.t.Len())
+	s.Walk(func(k, v string) bool {
+		out[k] = v
+		return false
+	})
+	return out
+}
+
+// MarshalJSON encodes the entries as a JSON object, in key order.
+// Keys and values that are not valid UTF-8 don't survive the trip,
+// as with any string encoded by encoding/json.
+func (s *StringTree) MarshalJSON() ([]byte, error) {
+	if s.t == nil {
+		return []byte("{}"), nil
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for l := s.t.leaves.head; l != nil; l = l.next {
+		if l != s.t.leaves.head {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(l.key)
+		if err != nil {
+			return nil, fmt.Errorf("radix: encoding key %q: %w", l.key, err)
+		}
+		v, err := json.Marshal(asString(l.val))
+		if err != nil {
+			return nil, fmt.Errorf("radix: encoding value of %q: %w", l.key, err)
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON inserts the entries of a JSON object of strings,
+// keeping any entries already in the tree. A zero StringTree is given
+// a tree with no options.
+func (s *StringTree) UnmarshalJSON(b []byte) error {
+	var m map[string]string
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	if s.t == nil {
+		s.t = New()
+	}
+	for k, v := range m {
+		if _, _, err := s.t.TryInsert(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// asString returns v as a string, or "" if it is nil
+func asString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return v.(string)
+}
@@ -0,0 +1,50 @@
+package radix
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestStringTree(t *testing.T) {
+	s := NewStringTree()
+	if _, updated := s.Insert("/api/", "backend"); updated {
+		t.Fatalf("expected insert")
+	}
+	s.Insert("/api/v2/", "backend-v2")
+	s.Insert("/", "frontend")
+	if old, updated := s.Insert("/", "static"); !updated || old != "frontend" {
+		t.Fatalf("bad: %q %v", old, updated)
+	}
+	if v, ok := s.Get("/api/"); !ok || v != "backend" {
+		t.Fatalf("bad: %q %v", v, ok)
+	}
+	if _, ok := s.Get("/missing"); ok {
+		t.Fatalf("expected a miss")
+	}
+	if k, v, ok := s.LongestPrefix("/api/v2/users"); !ok || k != "/api/v2/" || v != "backend-v2" {
+		t.Fatalf("bad: %q %q %v", k, v, ok)
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"/":"static","/api/":"backend","/api/v2/":"backend-v2"}`; string(b) != want {
+		t.Fatalf("bad: %s", b)
+	}
+	var out StringTree
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out.ToMap(), s.ToMap()) {
+		t.Fatalf("bad: %v", out.ToMap())
+	}
+	if err := json.Unmarshal([]byte(`{"a":1}`), &out); err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	if old, ok := s.Delete("/api/"); !ok || old != "backend" || s.Len() != 2 {
+		t.Fatalf("bad: %q %v", old, ok)
+	}
+}
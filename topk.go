@@ -0,0 +1,61 @@
+package radix
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// TopValues returns the k best values stored under prefix, best
+// first. less reports whether a ranks below b. It keeps a bounded
+// heap while walking the prefix, so it needs O(k) memory regardless
+// of how many entries the prefix holds.
+func (t *Tree) TopValues(prefix string, k int, less func(a, b interface{}) bool) []interface{} {
+	if k <= 0 {
+		return nil
+	}
+	h := &valueHeap{less: less}
+	t.WalkPrefix(prefix, func(s string, v interface{}) bool {
+		if len(h.vals) < k {
+			heap.Push(h, v)
+		} else if less(h.vals[0], v) {
+			h.vals[0] = v
+			heap.Fix(h, 0)
+		}
+		return false
+	})
+
+	out := h.vals
+	sort.SliceStable(out, func(i, j int) bool {
+		return less(out[j], out[i])
+	})
+	return out
+}
+
+// valueHeap is a min-heap of values, so the worst of the current
+// best k is at the top and can be replaced cheaply
+type valueHeap struct {
+	vals []interface{}
+	less func(a, b interface{}) bool
+}
+
+func (h *valueHeap) Len() int {
+	return len(h.vals)
+}
+
+func (h *valueHeap) Less(i, j int) bool {
+	return h.less(h.vals[i], h.vals[j])
+}
+
+func (h *valueHeap) Swap(i, j int) {
+	h.vals[i], h.vals[j] = h.vals[j], h.vals[i]
+}
+
+func (h *valueHeap) Push(x interface{}) {
+	h.vals = append(h.vals, x)
+}
+
+func (h *valueHeap) Pop() interface{} {
+	last := h.vals[len(h.vals)-1]
+	h.vals = h.vals[:len(h.vals)-1]
+	return last
+}
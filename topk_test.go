@@ -0,0 +1,40 @@
+package radix
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestTopValues(t *testing.T) {
+	r := New()
+	for i := 0; i < 100; i++ {
+		r.Insert(fmt.Sprintf("game1/player%d", i), (i*37)%100)
+		r.Insert(fmt.Sprintf("game2/player%d", i), i)
+	}
+	less := func(a, b interface{}) bool {
+		return a.(int) < b.(int)
+	}
+
+	type exp struct {
+		prefix string
+		k      int
+		out    []interface{}
+	}
+	cases := []exp{
+		{"game1/", 3, []interface{}{99, 98, 97}},
+		{"game2/player1", 2, []interface{}{19, 18}},
+		{"game2/player99", 5, []interface{}{99}},
+		{"game3/", 3, []interface{}{}},
+		{"game1/", 0, nil},
+	}
+	for _, test := range cases {
+		out := r.TopValues(test.prefix, test.k, less)
+		if len(out) == 0 && len(test.out) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(out, test.out) {
+			t.Fatalf("prefix %q k %d: %v %v", test.prefix, test.k, out, test.out)
+		}
+	}
+}
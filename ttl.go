@@ -0,0 +1,208 @@
+package radix
+
+import (
+	"container/heap"
+	"time"
+)
+
+// SetDefaultTTL sets the lifetime given to entries stored by Insert
+// and TryInsert from now on. Entries already in the tree keep their
+// expiry. A d of zero, the default, stores entries that never expire.
+//
+// Expired entries are hidden from Get, TTL and Touch, and evicted
+// when one of those finds them or by EvictExpired. Other reads,
+// such as walks and Len, see them until they have been evicted.
+func (t *Tree) SetDefaultTTL(d time.Duration) {
+	t.defaultTTL = d
+}
+
+// InsertWithTTL is like Insert, but the entry expires after ttl
+// regardless of the tree's default. A ttl of zero or less stores
+// an entry that never expires.
+func (t *Tree) InsertWithTTL(s string, v interface{}, ttl time.Duration) (interface{}, bool) {
+	s = t.norm(s)
+	if err := t.checkInsert(s, v); err != nil {
+		return nil, false
+	}
+	return t.insert(s, v, ttl)
+}
+
+// TTL returns how long the entry for key has left to live and
+// whether the key is present. The duration is zero for entries that
+// never expire.
+func (t *Tree) TTL(key string) (time.Duration, bool) {
+	key = t.norm(key)
+	l := t.getLeaf(key)
+	if l == nil {
+		return 0, false
+	}
+	expires := l.expires()
+	if expires == 0 {
+		return 0, true
+	}
+	now := t.now()
+	if expires <= now {
+		t.expire(l)
+		return 0, false
+	}
+	return time.Duration(expires - now), true
+}
+
+// Touch restarts the lifetime of the entry for key, so it expires a
+// full TTL from now, and reports whether the key is present. The
+// entry keeps the TTL it was inserted with.
+func (t *Tree) Touch(key string) bool {
+	key = t.norm(key)
+	l := t.getLeaf(key)
+	if l == nil {
+		return false
+	}
+	if t.expired(l) {
+		t.expire(l)
+		return false
+	}
+	if l.ext != nil && l.ext.ttl > 0 {
+		t.setExpiry(l, l.ext.ttl)
+	}
+	return true
+}
+
+// EvictExpired removes every expired entry, returning how many
+// were removed.
+func (t *Tree) EvictExpired() int {
+	return t.evictExpired(0, 0)
+}
+
+// evictExpired evicts expired entries, oldest expiry first, stopping
+// after limit entries or once maxTime has elapsed. Zero means no limit.
+func (t *Tree) evictExpired(limit int, maxTime time.Duration) int {
+	now := t.now()
+	var deadline int64
+	if maxTime > 0 {
+		deadline = now + int64(maxTime)
+	}
+	n := 0
+	for len(t.expiry) > 0 && t.expiry[0].ext.expires <= now {
+		if limit > 0 && n >= limit {
+			break
+		}
+		if deadline != 0 && n > 0 && t.now() >= deadline {
+			break
+		}
+		t.expire(t.expiry[0])
+		n++
+	}
+	return n
+}
+
+// Clock tells the time. The tree uses one for everything that
+// expires, so tests can control time with WithClock.
+type Clock interface {
+	Now() time.Time
+}
+
+// currentTime returns the time according to the tree's clock
+func (t *Tree) currentTime() time.Time {
+	if t.clock == nil {
+		return time.Now()
+	}
+	return t.clock.Now()
+}
+
+// now returns the current time in Unix nanoseconds
+func (t *Tree) now() int64 {
+	return t.currentTime().UnixNano()
+}
+
+// expired reports whether l is past its expiry
+func (t *Tree) expired(l *leafNode) bool {
+	expires := l.expires()
+	return expires != 0 && expires <= t.now()
+}
+
+// OnExpire registers fn to be called with every entry evicted
+// because its TTL ran out, so resources held by values can be
+// released. Callbacks run in registration order once the entry has
+// been removed, and may modify the tree. Entries removed any other
+// way, such as by Delete, don't trigger them.
+func (t *Tree) OnExpire(fn func(key string, v interface{})) {
+	// Derived trees share the slice, so never append in place
+	n := len(t.onExpire)
+	t.onExpire = append(t.onExpire[:n:n], fn)
+}
+
+// expire evicts the expired leaf l
+func (t *Tree) expire(l *leafNode) {
+	t.Delete(l.key)
+	for _, fn := range t.onExpire {
+		fn(l.key, l.val)
+	}
+}
+
+// setExpiry gives l a lifetime of ttl from now, or clears its expiry
+// if ttl is not positive
+func (t *Tree) setExpiry(l *leafNode, ttl time.Duration) {
+	if ttl <= 0 {
+		if l.expires() != 0 {
+			t.expiry.remove(l)
+			l.ext.expires = 0
+		}
+		if l.ext != nil {
+			l.ext.ttl = 0
+		}
+		return
+	}
+	t.setExpiryAt(l, t.now()+int64(ttl), ttl)
+}
+
+// setExpiryAt makes l, which was given a lifetime of ttl, expire at
+// the given time in Unix nanoseconds
+func (t *Tree) setExpiryAt(l *leafNode, expires int64, ttl time.Duration) {
+	x := l.x()
+	x.ttl = ttl
+	tracked := x.expires != 0
+	x.expires = expires
+	if tracked {
+		heap.Fix(&t.expiry, x.expIdx)
+	} else {
+		heap.Push(&t.expiry, l)
+	}
+}
+
+// expiryHeap is a min-heap of the leaves that have a TTL, so the
+// next to expire is at the top. Each leaf tracks its own index.
+type expiryHeap []*leafNode
+
+func (h expiryHeap) Len() int {
+	return len(h)
+}
+
+func (h expiryHeap) Less(i, j int) bool {
+	return h[i].ext.expires < h[j].ext.expires
+}
+
+func (h expiryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].ext.expIdx = i
+	h[j].ext.expIdx = j
+}
+
+func (h *expiryHeap) Push(x interface{}) {
+	l := x.(*leafNode)
+	l.x().expIdx = len(*h)
+	*h = append(*h, l)
+}
+
+func (h *expiryHeap) Pop() interface{} {
+	old := *h
+	l := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return l
+}
+
+// remove takes l out of the heap. It keeps its expiry so it can be
+// tracked again if moved to another tree.
+func (h *expiryHeap) remove(l *leafNode) {
+	heap.Remove(h, l.ext.expIdx)
+}
@@ -0,0 +1,221 @@
+package radix
+
+import (
+	"container/heap"
+	"testing"
+	"time"
+)
+
+// backdate makes the entry for key expire immediately
+func backdate(t *testing.T, r *Tree, key string) {
+	l := r.getLeaf(key)
+	if l == nil || l.expires() == 0 {
+		t.Fatalf("no expiring entry for %q", key)
+	}
+	l.ext.expires = 1
+	heap.Fix(&r.expiry, l.ext.expIdx)
+}
+
+func TestTTL(t *testing.T) {
+	r := New()
+	r.Insert("forever", 1)
+	r.SetDefaultTTL(time.Hour)
+	r.Insert("hour", 2)
+	r.InsertWithTTL("minute", 3, time.Minute)
+	r.InsertWithTTL("never", 4, 0)
+
+	if d, ok := r.TTL("forever"); !ok || d != 0 {
+		t.Fatalf("bad: %v %v", d, ok)
+	}
+	if d, ok := r.TTL("never"); !ok || d != 0 {
+		t.Fatalf("bad: %v %v", d, ok)
+	}
+	if d, ok := r.TTL("hour"); !ok || d <= time.Minute || d > time.Hour {
+		t.Fatalf("bad: %v %v", d, ok)
+	}
+	if d, ok := r.TTL("minute"); !ok || d <= 0 || d > time.Minute {
+		t.Fatalf("bad: %v %v", d, ok)
+	}
+	if _, ok := r.TTL("missing"); ok {
+		t.Fatalf("bad")
+	}
+
+	// Updating an entry gives it the lifetime of the new insert
+	r.Insert("forever", 5)
+	if d, ok := r.TTL("forever"); !ok || d <= time.Minute {
+		t.Fatalf("bad: %v %v", d, ok)
+	}
+	r.InsertWithTTL("hour", 6, 0)
+	if d, ok := r.TTL("hour"); !ok || d != 0 {
+		t.Fatalf("bad: %v %v", d, ok)
+	}
+	if len(r.expiry) != 2 {
+		t.Fatalf("bad: %d", len(r.expiry))
+	}
+}
+
+func TestTTL_Expiry(t *testing.T) {
+	r := New()
+	r.SetDefaultTTL(time.Hour)
+	for _, k := range []string{"a", "ab", "abc", "b"} {
+		r.Insert(k, k)
+	}
+	r.InsertWithTTL("c", "c", 0)
+
+	backdate(t, r, "ab")
+	if _, ok := r.Get("ab"); ok {
+		t.Fatalf("expired entry returned")
+	}
+	if r.Len() != 4 {
+		t.Fatalf("expired entry should be evicted on Get: %d", r.Len())
+	}
+
+	backdate(t, r, "a")
+	if _, ok := r.TTL("a"); ok {
+		t.Fatalf("bad")
+	}
+	backdate(t, r, "b")
+	if r.Touch("b") {
+		t.Fatalf("bad")
+	}
+	checkKeys(t, r, []string{"abc", "c"})
+
+	backdate(t, r, "abc")
+	if n := r.EvictExpired(); n != 1 {
+		t.Fatalf("bad: %d", n)
+	}
+	if n := r.EvictExpired(); n != 0 {
+		t.Fatalf("bad: %d", n)
+	}
+	checkKeys(t, r, []string{"c"})
+	if len(r.expiry) != 0 {
+		t.Fatalf("bad: %d", len(r.expiry))
+	}
+}
+
+func TestTTL_Touch(t *testing.T) {
+	r := New()
+	r.InsertWithTTL("foo", 1, time.Minute)
+	r.Insert("bar", 2)
+
+	l := r.getLeaf("foo")
+	l.ext.expires = r.now() + int64(time.Second)
+	if !r.Touch("foo") {
+		t.Fatalf("bad")
+	}
+	if d, _ := r.TTL("foo"); d <= time.Second || d > time.Minute {
+		t.Fatalf("touch should restart the entry's own TTL: %v", d)
+	}
+	if !r.Touch("bar") || r.Touch("baz") {
+		t.Fatalf("bad")
+	}
+	if d, ok := r.TTL("bar"); !ok || d != 0 {
+		t.Fatalf("bad: %v %v", d, ok)
+	}
+}
+
+func TestTTL_Split(t *testing.T) {
+	r := New()
+	r.SetDefaultTTL(time.Hour)
+	for _, k := range []string{"a", "b", "c", "d"} {
+		r.Insert(k, k)
+	}
+	left, right := r.Split("c")
+	if len(left.expiry) != 2 || len(right.expiry) != 2 {
+		t.Fatalf("bad: %d %d", len(left.expiry), len(right.expiry))
+	}
+	backdate(t, right, "d")
+	if n := right.EvictExpired(); n != 1 {
+		t.Fatalf("bad: %d", n)
+	}
+	checkKeys(t, right, []string{"c"})
+
+	parts := left.Partition([]string{"a"})
+	if len(left.expiry) != 1 || len(parts["a"].expiry) != 1 {
+		t.Fatalf("bad: %d %d", len(left.expiry), len(parts["a"].expiry))
+	}
+	backdate(t, parts["a"], "a")
+	if n := parts["a"].EvictExpired(); n != 1 {
+		t.Fatalf("bad: %d", n)
+	}
+	if n := left.EvictExpired(); n != 0 {
+		t.Fatalf("bad: %d", n)
+	}
+}
+
+func TestTTL_OnExpire(t *testing.T) {
+	r := New()
+	var expired []string
+	r.OnExpire(func(k string, v interface{}) {
+		expired = append(expired, k+"="+v.(string))
+	})
+	r.OnExpire(func(k string, v interface{}) {
+		if _, ok := r.Get(k); ok {
+			t.Fatalf("entry should be removed before callbacks run")
+		}
+	})
+	r.SetDefaultTTL(time.Hour)
+	for _, k := range []string{"a", "b", "c", "d"} {
+		r.Insert(k, k)
+	}
+
+	backdate(t, r, "a")
+	r.Get("a")
+	backdate(t, r, "c")
+	backdate(t, r, "d")
+	r.EvictExpired()
+	r.Delete("b")
+	if len(expired) != 3 || expired[0] != "a=a" {
+		t.Fatalf("bad: %v", expired)
+	}
+
+	// Derived trees keep the callbacks
+	r.Insert("e", "e")
+	_, right := r.Split("")
+	backdate(t, right, "e")
+	right.EvictExpired()
+	if len(expired) != 4 || expired[3] != "e=e" {
+		t.Fatalf("bad: %v", expired)
+	}
+}
+
+// fakeClock is a Clock that only moves when told to
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestTTL_Clock(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	r := New(WithClock(clock))
+	r.SetDefaultTTL(time.Minute)
+	r.Insert("a", 1)
+	clock.advance(30 * time.Second)
+	r.Insert("b", 2)
+
+	if d, ok := r.TTL("a"); !ok || d != 30*time.Second {
+		t.Fatalf("bad: %v %v", d, ok)
+	}
+	clock.advance(30 * time.Second)
+	if _, ok := r.Get("a"); ok {
+		t.Fatalf("entry should have expired")
+	}
+	if !r.Touch("b") {
+		t.Fatalf("bad")
+	}
+	clock.advance(59 * time.Second)
+	if n := r.EvictExpired(); n != 0 {
+		t.Fatalf("bad: %d", n)
+	}
+	clock.advance(time.Second)
+	if n := r.EvictExpired(); n != 1 || r.Len() != 0 {
+		t.Fatalf("bad: %d %d", n, r.Len())
+	}
+}
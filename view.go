@@ -0,0 +1,110 @@
+package radix
+
+// View is the read-only part of the tree API, implemented by Tree and
+// by the views built on top of one.
+type View interface {
+	Get(k string) (interface{}, bool)
+	Len() int
+	Walk(fn WalkFn)
+	WalkPrefix(prefix string, fn WalkFn)
+}
+
+// MapView is a read-only view of a tree whose values are transformed
+// on the fly, see Tree.MapView
+type MapView struct {
+	src View
+	fn  func(k string, v interface{}) interface{}
+}
+
+// MapView returns a read-only view of t in which every value v stored
+// under k reads as fn(k, v). fn is called on every access, and nothing
+// is copied, so the view always reflects the current contents of t.
+// Reads go through t.ReadOnly, so they leave t untouched: they don't
+// run its loader, count hits or evict expired entries.
+func (t *Tree) MapView(fn func(k string, v interface{}) interface{}) *MapView {
+	return &MapView{src: t.ReadOnly(), fn: fn}
+}
+
+// Get looks up the mapped value of an entry
+func (m *MapView) Get(k string) (interface{}, bool) {
+	v, ok := m.src.Get(k)
+	if !ok {
+		return nil, false
+	}
+	return m.fn(k, v), true
+}
+
+// Len returns the number of entries
+func (m *MapView) Len() int {
+	return m.src.Len()
+}
+
+// Walk visits every entry with its mapped value
+func (m *MapView) Walk(fn WalkFn) {
+	m.src.Walk(m.mapped(fn))
+}
+
+// WalkPrefix visits the entries under prefix with their mapped values
+func (m *MapView) WalkPrefix(prefix string, fn WalkFn) {
+	m.src.WalkPrefix(prefix, m.mapped(fn))
+}
+
+// mapped wraps fn to receive mapped values
+func (m *MapView) mapped(fn WalkFn) WalkFn {
+	return func(k string, v interface{}) bool {
+		return fn(k, m.fn(k, v))
+	}
+}
+
+// FilterView is a read-only view of the entries of a tree that match
+// a predicate, see Tree.FilterView
+type FilterView struct {
+	src  View
+	pred func(k string, v interface{}) bool
+}
+
+// FilterView returns a read-only view of t holding only the entries
+// for which pred returns true. pred is evaluated on every access, so
+// the view always reflects the current contents of t. Like those of a
+// MapView, reads leave t untouched, so looking up a key the predicate
+// hides never runs t's loader.
+func (t *Tree) FilterView(pred func(k string, v interface{}) bool) *FilterView {
+	return &FilterView{src: t.ReadOnly(), pred: pred}
+}
+
+// Get looks up an entry, reporting it missing if it doesn't match
+func (f *FilterView) Get(k string) (interface{}, bool) {
+	v, ok := f.src.Get(k)
+	if !ok || !f.pred(k, v) {
+		return nil, false
+	}
+	return v, true
+}
+
+// Len returns the number of matching entries. It has to count them,
+// taking time proportional to the size of the tree.
+func (f *FilterView) Len() int {
+	n := 0
+	f.Walk(func(string, interface{}) bool {
+		n++
+		return false
+	})
+	return n
+}
+
+// Walk visits every matching entry
+func (f *FilterView) Walk(fn WalkFn) {
+	f.src.Walk(f.filtered(fn))
+}
+
+// WalkPrefix visits the matching entries under prefix
+func (f *FilterView) WalkPrefix(prefix string, fn WalkFn) {
+	f.src.WalkPrefix(prefix, f.filtered(fn))
+}
+
+// filtered wraps fn to only receive matching entries
+func (f *FilterView) filtered(fn WalkFn) WalkFn {
+	return func(k string, v interface{}) bool {
+		return f.pred(k, v) && fn(k, v)
+	}
+}
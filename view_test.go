@@ -0,0 +1,131 @@
+package radix
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMapView(t *testing.T) {
+	r := New()
+	r.Insert("a", 1)
+	r.Insert("ab", 2)
+	r.Insert("b", 3)
+	calls := 0
+	m := r.MapView(func(k string, v interface{}) interface{} {
+		calls++
+		return k + "=" + string(rune('0'+v.(int)))
+	})
+	var _ View = m
+
+	if v, ok := m.Get("ab"); !ok || v != "ab=2" {
+		t.Fatalf("bad: %v %v", v, ok)
+	}
+	if _, ok := m.Get("c"); ok || calls != 1 {
+		t.Fatalf("bad: %d", calls)
+	}
+	var out []interface{}
+	m.WalkPrefix("a", func(k string, v interface{}) bool {
+		out = append(out, v)
+		return false
+	})
+	if !reflect.DeepEqual(out, []interface{}{"a=1", "ab=2"}) {
+		t.Fatalf("bad: %v", out)
+	}
+
+	// The view follows the tree, which is left untouched
+	r.Insert("b", 4)
+	out = out[:0]
+	m.Walk(func(k string, v interface{}) bool {
+		out = append(out, v)
+		return false
+	})
+	if !reflect.DeepEqual(out, []interface{}{"a=1", "ab=2", "b=4"}) || m.Len() != 3 {
+		t.Fatalf("bad: %v", out)
+	}
+	if v, _ := r.Get("a"); v != 1 {
+		t.Fatalf("bad: %v", v)
+	}
+}
+
+func TestMapView_NoSideEffects(t *testing.T) {
+	loads := 0
+	r := New(WithLFU(0), WithLoader(func(string) (interface{}, bool) {
+		loads++
+		return 0, true
+	}))
+	r.Insert("a", 1)
+	m := r.MapView(func(k string, v interface{}) interface{} { return v })
+	if v, ok := m.Get("a"); !ok || v != 1 {
+		t.Fatalf("bad: %v %v", v, ok)
+	}
+	if _, ok := m.Get("missing"); ok {
+		t.Fatalf("bad")
+	}
+	if hits, _ := r.Hits("a"); hits != 0 || loads != 0 || r.Len() != 1 {
+		t.Fatalf("bad: %d %d %d", hits, loads, r.Len())
+	}
+}
+
+func TestFilterView(t *testing.T) {
+	r := New()
+	for i, k := range []string{"a", "ab", "abc", "b", "bc"} {
+		r.Insert(k, i)
+	}
+	f := r.FilterView(func(k string, v interface{}) bool {
+		return v.(int)%2 == 0
+	})
+	var _ View = f
+
+	if v, ok := f.Get("abc"); !ok || v != 2 {
+		t.Fatalf("bad: %v %v", v, ok)
+	}
+	if _, ok := f.Get("ab"); ok {
+		t.Fatalf("filtered entry returned")
+	}
+	if f.Len() != 3 {
+		t.Fatalf("bad: %d", f.Len())
+	}
+	var keys []string
+	f.WalkPrefix("a", func(k string, v interface{}) bool {
+		keys = append(keys, k)
+		return false
+	})
+	if !reflect.DeepEqual(keys, []string{"a", "abc"}) {
+		t.Fatalf("bad: %q", keys)
+	}
+	keys = keys[:0]
+	f.Walk(func(k string, v interface{}) bool {
+		keys = append(keys, k)
+		return len(keys) == 2
+	})
+	if !reflect.DeepEqual(keys, []string{"a", "abc"}) {
+		t.Fatalf("bad: %q", keys)
+	}
+
+	r.Insert("ab", 10)
+	if f.Len() != 4 {
+		t.Fatalf("bad: %d", f.Len())
+	}
+}
+
+func TestFilterView_NoLoader(t *testing.T) {
+	loads := 0
+	r := New(WithLFU(0), WithLoader(func(string) (interface{}, bool) {
+		loads++
+		return "secret", true
+	}))
+	r.Insert("public/a", 1)
+	f := r.FilterView(func(k string, v interface{}) bool {
+		return strings.HasPrefix(k, "public/")
+	})
+	if v, ok := f.Get("public/a"); !ok || v != 1 {
+		t.Fatalf("bad: %v %v", v, ok)
+	}
+	if _, ok := f.Get("private/b"); ok {
+		t.Fatalf("bad")
+	}
+	if hits, _ := r.Hits("public/a"); hits != 0 || loads != 0 || r.Len() != 1 {
+		t.Fatalf("bad: %d %d %d", hits, loads, r.Len())
+	}
+}
@@ -0,0 +1,209 @@
+package radix
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// Log records follow the snapshot's approach to compatibility: a
+// record starts with its type and a flags byte, and readers skip
+// records of unknown types unless they are flagged as required.
+const (
+	// recordPut and recordDelete hold a key, and for puts a value
+	recordPut    = 1
+	recordDelete = 2
+)
+
+// wal logs mutations to a Backend, see WithBackend
+type wal struct {
+	backend Backend
+	codec   ValueCodec
+	onErr   func(error)
+	writer  *storeWriter
+
+	// committer batches appends if group commit is enabled
+	committer *committer
+
+	// durability decides when appends are synced, and unsynced
+	// counts the appends since the last sync
+	durability Durability
+	unsynced   int
+}
+
+// Durability decides when mutations logged by a tree created with
+// WithBackend are synced to the backend, trading durability for
+// latency. It is set with WithDurability.
+type Durability struct {
+	every int
+	async bool
+}
+
+var (
+	// DurabilityNone never syncs the log on its own. Mutations are
+	// durable only after Sync, Checkpoint or Close, or whenever the
+	// backend happens to write them out.
+	DurabilityNone = Durability{}
+
+	// DurabilityAsync syncs the log in the background, in batches,
+	// so mutations become durable shortly after they return. The
+	// delay is set with WithGroupCommit.
+	DurabilityAsync = Durability{async: true}
+
+	// DurabilitySync syncs the log before every mutation returns
+	DurabilitySync = Durability{every: 1}
+)
+
+// DurabilityEvery syncs the log after every n mutations, so at most
+// n-1 of them can be lost.
+func DurabilityEvery(n int) Durability {
+	if n <= 0 {
+		panic("radix: durability interval must be positive")
+	}
+	return Durability{every: n}
+}
+
+// defaultAsyncLatency is the group commit latency used by
+// DurabilityAsync unless set with WithGroupCommit
+const defaultAsyncLatency = 100 * time.Millisecond
+
+// append adds a record to the log, syncing it as the durability
+// level requires
+func (w *wal) append(rec []byte) error {
+	if w.committer != nil {
+		w.committer.add(rec)
+	} else if err := w.backend.Append(rec); err != nil {
+		return err
+	}
+	if w.durability.every == 0 {
+		return nil
+	}
+	w.unsynced++
+	if w.unsynced < w.durability.every {
+		return nil
+	}
+	w.unsynced = 0
+	return w.sync()
+}
+
+// sync makes everything appended so far durable
+func (w *wal) sync() error {
+	if w.committer != nil {
+		return w.committer.sync()
+	}
+	return w.backend.Sync()
+}
+
+// walStore adapts the log to the Store interface, so mutations reach
+// it the same way they reach a store
+type walStore struct {
+	*wal
+}
+
+func (s walStore) Put(key string, v interface{}) error {
+	b, err := s.codec.Encode(v)
+	if err != nil {
+		return err
+	}
+	return s.append(encodeRecord(recordPut, key, b))
+}
+
+func (s walStore) Delete(key string) error {
+	return s.append(encodeRecord(recordDelete, key, nil))
+}
+
+// encodeRecord builds a log record
+func encodeRecord(typ byte, key string, val []byte) []byte {
+	rec := make([]byte, 2+binary.MaxVarintLen64+len(key)+len(val))
+	rec[0] = typ
+	n := 2 + binary.PutUvarint(rec[2:], uint64(len(key)))
+	n += copy(rec[n:], key)
+	n += copy(rec[n:], val)
+	return rec[:n]
+}
+
+// Sync waits until every mutation made so far is durable in the
+// tree's backend, returning an error if logging any of them failed.
+// With group commit, Sync may be called without holding the lock
+// guarding the tree, so that concurrent writers share a single sync of
+// the backend. The tree must have been created with WithBackend.
+func (t *Tree) Sync() error {
+	if t.wal == nil {
+		panic("radix: Sync requires WithBackend")
+	}
+	return t.wal.sync()
+}
+
+// Checkpoint writes a snapshot of the tree to its backend and then
+// empties the log, bounding the time Recover takes. The tree must have
+// been created with WithBackend.
+func (t *Tree) Checkpoint() error {
+	if t.wal == nil {
+		panic("radix: Checkpoint requires WithBackend")
+	}
+	if err := t.Sync(); err != nil {
+		return err
+	}
+	err := t.wal.backend.WriteSnapshot(func(w io.Writer) error {
+		return t.WriteSnapshot(w, t.wal.codec)
+	})
+	if err != nil {
+		return err
+	}
+	return t.wal.backend.TruncateLog()
+}
+
+// Recover restores the tree from its backend by loading the latest
+// snapshot, if any, and replaying the log on top of it. It is meant to
+// be called on an empty tree, before any other use. Expiry times are
+// only restored from the snapshot; entries replayed from the log are
+// given no TTL. The tree must have been created with WithBackend.
+func (t *Tree) Recover() error {
+	if t.wal == nil {
+		panic("radix: Recover requires WithBackend")
+	}
+
+	// Don't log the replayed mutations again
+	w := t.wal
+	t.wal = nil
+	defer func() {
+		t.wal = w
+	}()
+
+	err := w.backend.ReadSnapshot(func(r io.Reader) error {
+		return t.ReadSnapshot(r, w.codec)
+	})
+	if err != nil && err != ErrNoSnapshot {
+		return err
+	}
+	return w.backend.ReadLog(func(rec []byte) error {
+		if len(rec) < 2 {
+			return ErrCorruptLog
+		}
+		typ, flags := rec[0], rec[1]
+		sr := &snapshotReader{b: rec[2:]}
+		switch typ {
+		case recordPut:
+			key := sr.bytes()
+			if sr.err != nil {
+				return ErrCorruptLog
+			}
+			v, err := w.codec.Decode(sr.b)
+			if err != nil {
+				return err
+			}
+			t.insert(string(key), v, 0)
+		case recordDelete:
+			key := sr.bytes()
+			if sr.err != nil {
+				return ErrCorruptLog
+			}
+			t.Delete(string(key))
+		default:
+			if flags&sectionRequired != 0 {
+				return ErrSnapshotVersion
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,43 @@
+package radix
+
+import "strings"
+
+// WalkDeleteFn is called by WalkDelete for each entry. It returns
+// whether to delete the entry and whether to stop the walk.
+type WalkDeleteFn func(k string, v interface{}) (del, stop bool)
+
+// WalkDelete visits every entry in key order, deleting those fn asks
+// to, and returns the number deleted. Deletions are made with Delete
+// and so run its hooks, such as writing to a store. fn may also modify
+// the tree itself; the walk then carries on from the first key after
+// the one just visited. Unlike Walk, it ignores WithNaturalOrder.
+func (t *Tree) WalkDelete(fn WalkDeleteFn) int {
+	return t.WalkPrefixDelete("", fn)
+}
+
+// WalkPrefixDelete is like WalkDelete, but only visits the entries
+// whose keys start with prefix
+func (t *Tree) WalkPrefixDelete(prefix string, fn WalkDeleteFn) int {
+	prefix = t.norm(prefix)
+	var deleted int
+	l, _ := t.prefixLeaves(prefix)
+	for l != nil && strings.HasPrefix(l.key, prefix) {
+		mods := t.mods
+		next := l.next
+		del, stop := fn(l.key, l.val)
+		if t.mods != mods {
+			// fn changed the tree, so next may be gone
+			_, _, next = t.seek(l.key)
+		}
+		if del {
+			if _, ok := t.Delete(l.key); ok {
+				deleted++
+			}
+		}
+		if stop {
+			break
+		}
+		l = next
+	}
+	return deleted
+}
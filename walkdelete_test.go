@@ -0,0 +1,51 @@
+package radix
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestWalkDeleteFn(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithSmallTree(100)}} {
+		r := New(opts...)
+		for i := 0; i < 50; i++ {
+			r.Insert(fmt.Sprintf("%02d", i), i)
+		}
+
+		// Delete the even entries, which merges nodes along the way
+		var seen int
+		n := r.WalkDelete(func(k string, v interface{}) (bool, bool) {
+			seen++
+			return v.(int)%2 == 0, false
+		})
+		if n != 25 || seen != 50 || r.Len() != 25 {
+			t.Fatalf("bad: %d %d %d", n, seen, r.Len())
+		}
+		if _, ok := r.Get("02"); ok {
+			t.Fatalf("expected 02 to be deleted")
+		}
+		if r.root != nil {
+			validateTree(t, r.root)
+		}
+
+		// Stop after the first deletion under a prefix
+		n = r.WalkPrefixDelete("1", func(k string, v interface{}) (bool, bool) {
+			return true, true
+		})
+		if _, ok := r.Get("11"); n != 1 || ok {
+			t.Fatalf("bad: %d", n)
+		}
+
+		// fn may delete entries ahead of the walk
+		var visited []string
+		r.WalkPrefixDelete("1", func(k string, v interface{}) (bool, bool) {
+			visited = append(visited, k)
+			r.Delete("15")
+			return false, false
+		})
+		if !reflect.DeepEqual(visited, []string{"13", "17", "19"}) {
+			t.Fatalf("bad: %v", visited)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package radix
+
+// WalkRange visits the entries whose keys lie in [lo, hi), in key
+// order, until fn returns true. It seeks straight to lo rather than
+// scanning from the start, so it takes time proportional to the depth
+// of lo and the number of entries visited. Like Iterator, it ignores
+// WithNaturalOrder, and fn may modify the tree.
+func (t *Tree) WalkRange(lo, hi string, fn WalkFn) {
+	it := t.Iterator()
+	it.SeekRange(lo, hi)
+	for {
+		k, v, ok := it.Next()
+		if !ok || fn(k, v) {
+			return
+		}
+	}
+}
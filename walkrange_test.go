@@ -0,0 +1,46 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWalkRange(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithSmallTree(100)}} {
+		r := New(opts...)
+		for _, k := range []string{"a", "ab", "abc", "b", "ba", "c"} {
+			r.Insert(k, nil)
+		}
+		for _, tc := range []struct {
+			lo, hi string
+			want   []string
+		}{
+			{"", "\xff", []string{"a", "ab", "abc", "b", "ba", "c"}},
+			{"ab", "b", []string{"ab", "abc"}},
+			{"aa", "ba", []string{"ab", "abc", "b"}},
+			{"abc", "abc", nil},
+			{"c", "a", nil},
+			{"d", "z", nil},
+		} {
+			var got []string
+			r.WalkRange(tc.lo, tc.hi, func(k string, v interface{}) bool {
+				got = append(got, k)
+				return false
+			})
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("[%q, %q): got %v, want %v", tc.lo, tc.hi, got, tc.want)
+			}
+		}
+
+		// The iterator keeps its bound until seeked again
+		it := r.Iterator()
+		it.SeekRange("ab", "b")
+		if got := drain(it); !reflect.DeepEqual(got, []string{"ab", "abc"}) {
+			t.Fatalf("bad: %v", got)
+		}
+		it.SeekLowerBound("ba")
+		if got := drain(it); !reflect.DeepEqual(got, []string{"ba", "c"}) {
+			t.Fatalf("bad: %v", got)
+		}
+	}
+}